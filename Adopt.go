@@ -0,0 +1,261 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "log"
+    "sort"
+    "strings"
+    "text/tabwriter"
+
+    "Support_Site_Sync/listing"
+)
+
+// Ways a remote page can be matched to a canonical page, strongest first
+const (
+    MatchExternalKey = "external key"
+    MatchExactTitle  = "exact title"
+    MatchFuzzy       = "fuzzy"
+)
+
+// AdoptOptions controls how existing remote pages are matched and confirmed
+type AdoptOptions struct {
+    MinTitleScore float64   // Title similarity needed before content is compared, defaults to 0.6
+    MinScore      float64   // Combined title and content similarity a fuzzy match needs, defaults to 0.8
+    Yes           bool      // Adopt every match without asking
+    In            io.Reader // Answers to the confirmation prompts
+    Out           io.Writer // Review table and prompts
+}
+
+// AdoptMatch pairs a canonical page with an existing page on a service
+type AdoptMatch struct {
+    Service string
+    Page    Page            // Canonical page
+    Remote  listing.Summary // Existing page on the service
+    Method  string          // MatchExternalKey, MatchExactTitle or MatchFuzzy
+    Score   float64         // Similarity of fuzzy matches, 1 for the others
+}
+
+// Adopt matches the pages that already exist on each service to canonical pages that
+// are not mapped there yet, shows the matches for review and records the confirmed
+// ones in the ID mapping, so the next sync updates those pages instead of creating
+// duplicates. A service that cannot be listed is reported and skipped
+func Adopt(ctx context.Context, services []ServiceInterface, pages []Page, mapping *IDMapping, options AdoptOptions) error {
+    var matches []AdoptMatch
+    for _, svc := range services {
+        found, err := findMatches(ctx, svc, pages, mapping, options)
+        if err != nil {
+            log.Printf("Service %s could not be matched: %v", svcName(svc), err)
+            continue
+        }
+        matches = append(matches, found...)
+    }
+
+    if len(matches) == 0 {
+        fmt.Fprintln(options.Out, "No existing pages matched canonical pages.")
+        return nil
+    }
+    printMatches(options.Out, matches)
+
+    confirmed, err := confirmMatches(options, matches)
+    if err != nil {
+        return err
+    }
+
+    byName := make(map[string]ServiceInterface, len(services))
+    for _, svc := range services {
+        byName[svcName(svc)] = svc
+    }
+    for _, match := range confirmed {
+        svc := byName[match.Service]
+        entry := MappingEntry{RemoteID: match.Remote.ID}
+        if target, ok := svc.(PathTarget); ok {
+            entry.Path = match.Remote.ID
+            target.ReservePath(match.Page.ID, match.Remote.ID)
+        }
+        // A page adopted on another target of the same service keeps that target's ID
+        if composite, ok := svc.(CompositeIDs); ok {
+            if existing, ok := mapping.Get(match.Page.ID, match.Service); ok {
+                entry.RemoteID = composite.JoinIDs(existing.RemoteID, match.Remote.ID)
+            }
+        }
+        mapping.Set(match.Page.ID, match.Service, entry)
+    }
+    fmt.Fprintf(options.Out, "Adopted %d of %d matched pages.\n", len(confirmed), len(matches))
+    return nil
+}
+
+// findMatches lists the pages of a service and matches those not mapped yet to the
+// canonical pages not mapped on it yet. Services with composite IDs are matched one
+// target at a time, so a page can be adopted on each of them
+func findMatches(ctx context.Context, svc ServiceInterface, pages []Page, mapping *IDMapping, options AdoptOptions) ([]AdoptMatch, error) {
+    service := svcName(svc)
+    summaries, err := svc.ListPages(ctx).All()
+    if err != nil {
+        return nil, err
+    }
+
+    mapped := listedRemoteIDs(svc, mapping)
+    byTarget := map[string]map[string]listing.Summary{}
+    for _, summary := range summaries {
+        if _, ok := mapped[summary.ID]; ok {
+            continue
+        }
+        for target := range remoteTargets(svc, summary.ID) {
+            if byTarget[target] == nil {
+                byTarget[target] = map[string]listing.Summary{}
+            }
+            byTarget[target][summary.ID] = summary
+        }
+    }
+    targets := make([]string, 0, len(byTarget))
+    for target := range byTarget {
+        targets = append(targets, target)
+    }
+    sort.Strings(targets)
+
+    var matches []AdoptMatch
+    for _, target := range targets {
+        var canonical []Page
+        for _, page := range pages {
+            if entry, ok := mapping.Get(page.ID, service); ok {
+                if _, ok := remoteTargets(svc, entry.RemoteID)[target]; ok {
+                    continue
+                }
+            }
+            canonical = append(canonical, page)
+        }
+        matches = append(matches, matchTarget(ctx, svc, target, canonical, byTarget[target], options)...)
+    }
+    return matches, nil
+}
+
+// matchTarget matches the unmapped pages of one target of a service to canonical
+// pages: first by external key, then by a title only one page on each side has, and
+// finally by title and content similarity
+func matchTarget(ctx context.Context, svc ServiceInterface, target string, canonical []Page, remotes map[string]listing.Summary, options AdoptOptions) []AdoptMatch {
+    service := svcName(svc)
+    var matches []AdoptMatch
+    take := func(page Page, remote listing.Summary, method string, score float64) {
+        matches = append(matches, AdoptMatch{Service: service, Page: page, Remote: remote, Method: method, Score: score})
+        delete(remotes, remote.ID)
+    }
+
+    // External keys
+    var rest []Page
+    for _, page := range canonical {
+        key := remoteTargets(svc, page.ExternalKeys[service])[target]
+        if remote, ok := remotes[key]; ok && key != "" {
+            take(page, remote, MatchExternalKey, 1)
+        } else {
+            rest = append(rest, page)
+        }
+    }
+    canonical, rest = rest, nil
+
+    // Exact titles, when the title is unique on both sides
+    remoteTitles := map[string][]listing.Summary{}
+    for _, remote := range remotes {
+        title := normalizeTitle(remote.Title)
+        remoteTitles[title] = append(remoteTitles[title], remote)
+    }
+    pageTitles := map[string]int{}
+    for _, page := range canonical {
+        pageTitles[normalizeTitle(page.Title)]++
+    }
+    for _, page := range canonical {
+        title := normalizeTitle(page.Title)
+        if candidates := remoteTitles[title]; title != "" && len(candidates) == 1 && pageTitles[title] == 1 {
+            take(page, candidates[0], MatchExactTitle, 1)
+        } else {
+            rest = append(rest, page)
+        }
+    }
+    canonical = rest
+
+    // Similar titles and content, best pairs first
+    minTitle, minScore := options.MinTitleScore, options.MinScore
+    if minTitle == 0 {
+        minTitle = 0.6
+    }
+    if minScore == 0 {
+        minScore = 0.8
+    }
+    var candidates []AdoptMatch
+    contents := map[string]string{}
+    unreadable := map[string]bool{}
+    for _, page := range canonical {
+        for _, remote := range remotes {
+            titleScore := titleSimilarity(page.Title, remote.Title)
+            if titleScore < minTitle || unreadable[remote.ID] {
+                continue
+            }
+            content, ok := contents[remote.ID]
+            if !ok {
+                // A page that cannot be read is left out rather than failing the service
+                existing, err := svc.GetPage(ctx, remote.ID)
+                if err != nil {
+                    log.Printf("Skipping %s page %s: %v", service, remote.ID, err)
+                    unreadable[remote.ID] = true
+                    continue
+                }
+                content = existing.Content
+                contents[remote.ID] = content
+            }
+            score := (titleScore + contentSimilarity(page.Content, content)) / 2
+            if score >= minScore {
+                candidates = append(candidates, AdoptMatch{Service: service, Page: page, Remote: remote, Method: MatchFuzzy, Score: score})
+            }
+        }
+    }
+    sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+    used := map[string]bool{}
+    for _, candidate := range candidates {
+        if _, ok := remotes[candidate.Remote.ID]; !ok || used[candidate.Page.ID] {
+            continue
+        }
+        used[candidate.Page.ID] = true
+        take(candidate.Page, candidate.Remote, candidate.Method, candidate.Score)
+    }
+
+    return matches
+}
+
+// printMatches writes the review table of matches
+func printMatches(out io.Writer, matches []AdoptMatch) {
+    table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(table, "#\tSERVICE\tPAGE\tTITLE\tREMOTE ID\tREMOTE TITLE\tMATCH\tSCORE")
+    for i, match := range matches {
+        fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%.2f\n",
+            i+1, match.Service, match.Page.ID, match.Page.Title, match.Remote.ID, match.Remote.Title, match.Method, match.Score)
+    }
+    table.Flush()
+}
+
+// confirmMatches returns the matches to adopt: all of them with Yes set, otherwise
+// those confirmed at the prompt. "a" adopts the remaining matches, "q" skips them
+func confirmMatches(options AdoptOptions, matches []AdoptMatch) ([]AdoptMatch, error) {
+    if options.Yes {
+        return matches, nil
+    }
+
+    var confirmed []AdoptMatch
+    answers := bufio.NewScanner(options.In)
+    for i, match := range matches {
+        fmt.Fprintf(options.Out, "Adopt #%d %s %q as %s? [y/N/a/q] ", i+1, match.Service, match.Remote.Title, match.Page.ID)
+        if !answers.Scan() {
+            return confirmed, answers.Err()
+        }
+        switch strings.ToLower(strings.TrimSpace(answers.Text())) {
+        case "y", "yes":
+            confirmed = append(confirmed, match)
+        case "a", "all":
+            return append(confirmed, matches[i:]...), nil
+        case "q", "quit":
+            return confirmed, nil
+        }
+    }
+    return confirmed, nil
+}
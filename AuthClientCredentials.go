@@ -0,0 +1,246 @@
+package auth
+
+import (
+    "context"
+    "crypto"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha1"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// defaultExpiryMargin is how long before expiry a cached token is refreshed
+const defaultExpiryMargin = 2 * time.Minute
+
+// ClientCredentials is a TokenSource using the OAuth2 client credentials grant. The
+// client authenticates either with a secret or with a certificate-signed assertion
+type ClientCredentials struct {
+    TokenURL     string
+    ClientID     string
+    ClientSecret string
+    Scopes       []string
+    ExpiryMargin time.Duration // Refresh this long before expiry, defaults to two minutes
+
+    certificate *x509.Certificate
+    privateKey  *rsa.PrivateKey
+
+    mu     sync.Mutex
+    token  string
+    expiry time.Time
+}
+
+// NewAzureADClientSecret creates a token source for an Azure AD app registration
+// using a client secret. Scopes are resources such as
+// "https://graph.microsoft.com/.default". SharePoint REST app-only access does not
+// accept secrets; use NewAzureADClientCertificate for the Site Pages API
+func NewAzureADClientSecret(tenantID, clientID, clientSecret string, scopes ...string) *ClientCredentials {
+    return &ClientCredentials{
+        TokenURL:     azureTokenURL(tenantID),
+        ClientID:     clientID,
+        ClientSecret: clientSecret,
+        Scopes:       scopes,
+    }
+}
+
+// NewAzureADClientCertificate creates a token source for an Azure AD app registration
+// that authenticates with a certificate. certPEM holds the certificate uploaded to
+// the app registration and keyPEM its RSA private key
+func NewAzureADClientCertificate(tenantID, clientID string, certPEM, keyPEM []byte, scopes ...string) (*ClientCredentials, error) {
+    certBlock, _ := pem.Decode(certPEM)
+    if certBlock == nil {
+        return nil, fmt.Errorf("failed to decode certificate PEM")
+    }
+    certificate, err := x509.ParseCertificate(certBlock.Bytes)
+    if err != nil {
+        return nil, err
+    }
+
+    keyBlock, _ := pem.Decode(keyPEM)
+    if keyBlock == nil {
+        return nil, fmt.Errorf("failed to decode private key PEM")
+    }
+    privateKey, err := parseRSAKey(keyBlock.Bytes)
+    if err != nil {
+        return nil, err
+    }
+
+    return &ClientCredentials{
+        TokenURL:    azureTokenURL(tenantID),
+        ClientID:    clientID,
+        Scopes:      scopes,
+        certificate: certificate,
+        privateKey:  privateKey,
+    }, nil
+}
+
+// NewServiceNowClientCredentials creates a token source for an OAuth application
+// registered in the ServiceNow application registry
+func NewServiceNowClientCredentials(instanceURL, clientID, clientSecret string) *ClientCredentials {
+    return &ClientCredentials{
+        TokenURL:     strings.TrimSuffix(instanceURL, "/") + "/oauth_token.do",
+        ClientID:     clientID,
+        ClientSecret: clientSecret,
+    }
+}
+
+// Token returns the cached access token, fetching a new one when it is missing or
+// within the expiry margin
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    margin := c.ExpiryMargin
+    if margin == 0 {
+        margin = defaultExpiryMargin
+    }
+    if c.token != "" && time.Now().Add(margin).Before(c.expiry) {
+        return c.token, nil
+    }
+
+    token, expiresIn, err := c.fetch(ctx)
+    if err != nil {
+        return "", err
+    }
+    c.token = token
+    c.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+
+    return c.token, nil
+}
+
+// Invalidate drops the cached token
+func (c *ClientCredentials) Invalidate() {
+    c.mu.Lock()
+    c.token = ""
+    c.mu.Unlock()
+}
+
+// fetch requests a new token from the token endpoint
+func (c *ClientCredentials) fetch(ctx context.Context) (string, int64, error) {
+    form := url.Values{}
+    form.Set("grant_type", "client_credentials")
+    form.Set("client_id", c.ClientID)
+    if len(c.Scopes) > 0 {
+        form.Set("scope", strings.Join(c.Scopes, " "))
+    }
+    if c.privateKey != nil {
+        assertion, err := c.assertion()
+        if err != nil {
+            return "", 0, err
+        }
+        form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+        form.Set("client_assertion", assertion)
+    } else {
+        form.Set("client_secret", c.ClientSecret)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, "POST", c.TokenURL, strings.NewReader(form.Encode()))
+    if err != nil {
+        return "", 0, err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return "", 0, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        body, _ := ioutil.ReadAll(resp.Body)
+        return "", 0, fmt.Errorf("failed to get access token: %s - %s", resp.Status, body)
+    }
+
+    var result struct {
+        AccessToken string          `json:"access_token"`
+        ExpiresIn   json.RawMessage `json:"expires_in"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return "", 0, err
+    }
+    if result.AccessToken == "" {
+        return "", 0, fmt.Errorf("failed to parse access token")
+    }
+
+    // Some providers send expires_in as a string
+    var expiresIn int64
+    if err := json.Unmarshal(result.ExpiresIn, &expiresIn); err != nil {
+        var text string
+        json.Unmarshal(result.ExpiresIn, &text)
+        fmt.Sscanf(text, "%d", &expiresIn)
+    }
+    if expiresIn <= 0 {
+        expiresIn = 3600
+    }
+
+    return result.AccessToken, expiresIn, nil
+}
+
+// assertion builds the signed JWT that proves possession of the certificate's key
+func (c *ClientCredentials) assertion() (string, error) {
+    thumbprint := sha1.Sum(c.certificate.Raw)
+    header := map[string]string{
+        "alg": "RS256",
+        "typ": "JWT",
+        "x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+    }
+
+    jti := make([]byte, 16)
+    if _, err := rand.Read(jti); err != nil {
+        return "", err
+    }
+    now := time.Now()
+    claims := map[string]interface{}{
+        "aud": c.TokenURL,
+        "iss": c.ClientID,
+        "sub": c.ClientID,
+        "jti": hex.EncodeToString(jti),
+        "nbf": now.Unix(),
+        "exp": now.Add(10 * time.Minute).Unix(),
+    }
+
+    headerJSON, _ := json.Marshal(header)
+    claimsJSON, _ := json.Marshal(claims)
+    signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+    digest := sha256.Sum256([]byte(signingInput))
+    signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, digest[:])
+    if err != nil {
+        return "", err
+    }
+
+    return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// azureTokenURL returns the v2.0 token endpoint of an Azure AD tenant
+func azureTokenURL(tenantID string) string {
+    return fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenantID)
+}
+
+// parseRSAKey parses a PKCS#1 or PKCS#8 encoded RSA private key
+func parseRSAKey(der []byte) (*rsa.PrivateKey, error) {
+    if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+        return key, nil
+    }
+    key, err := x509.ParsePKCS8PrivateKey(der)
+    if err != nil {
+        return nil, err
+    }
+    rsaKey, ok := key.(*rsa.PrivateKey)
+    if !ok {
+        return nil, fmt.Errorf("private key is not an RSA key")
+    }
+    return rsaKey, nil
+}
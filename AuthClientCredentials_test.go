@@ -0,0 +1,74 @@
+package auth
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+// fakeTokenEndpoint issues numbered tokens with a fixed lifetime, written as JSON
+type fakeTokenEndpoint struct {
+    expiresIn string // JSON value of expires_in
+    issued    int
+    forms     []string // grant_type and client_id of each request
+}
+
+func (f *fakeTokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    r.ParseForm()
+    f.forms = append(f.forms, r.PostForm.Get("grant_type")+" "+r.PostForm.Get("client_id"))
+    f.issued++
+    fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": %s}`, f.issued, f.expiresIn)
+}
+
+func TestClientCredentialsToken(t *testing.T) {
+    tests := []struct {
+        name       string
+        expiresIn  string
+        invalidate bool
+        want       []string // Tokens returned by three calls
+    }{
+        {"cached", `3600`, false, []string{"token-1", "token-1", "token-1"}},
+        {"string lifetime", `"3600"`, false, []string{"token-1", "token-1", "token-1"}},
+        {"within margin", `90`, false, []string{"token-1", "token-2", "token-3"}},
+        {"just outside margin", `150`, false, []string{"token-1", "token-1", "token-1"}},
+        {"invalidated", `3600`, true, []string{"token-1", "token-2", "token-3"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeTokenEndpoint{expiresIn: tt.expiresIn}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            source := &ClientCredentials{TokenURL: server.URL, ClientID: "client", ClientSecret: "secret"}
+            for i, want := range tt.want {
+                got, err := source.Token(context.Background())
+                if err != nil {
+                    t.Fatalf("Token() error = %v", err)
+                }
+                if got != want {
+                    t.Errorf("Token() call %d = %q, want %q", i+1, got, want)
+                }
+                if tt.invalidate {
+                    source.Invalidate()
+                }
+            }
+            if fake.forms[0] != "client_credentials client" {
+                t.Errorf("token request = %q, want client credentials grant for client", fake.forms[0])
+            }
+        })
+    }
+}
+
+func TestClientCredentialsTokenError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+    }))
+    defer server.Close()
+
+    source := &ClientCredentials{TokenURL: server.URL, ClientID: "client", ClientSecret: "wrong"}
+    if _, err := source.Token(context.Background()); err == nil {
+        t.Fatal("Token() error = nil, want rejected credentials")
+    }
+}
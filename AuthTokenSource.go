@@ -0,0 +1,65 @@
+package auth
+
+import (
+    "context"
+    "net/http"
+)
+
+// TokenSource supplies bearer tokens for API requests, refreshing them as needed
+type TokenSource interface {
+    // Token returns a valid access token, fetching a new one when the cached token
+    // is missing or about to expire
+    Token(ctx context.Context) (string, error)
+    // Invalidate drops the cached token so the next call to Token fetches a new one
+    Invalidate()
+}
+
+// StaticToken is a TokenSource for a fixed, externally managed access token
+type StaticToken string
+
+// Token returns the static token
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+    return string(t), nil
+}
+
+// Invalidate does nothing, as a static token cannot be refreshed
+func (t StaticToken) Invalidate() {}
+
+// Do sends req with a bearer token from source. If the server rejects the token with
+// 401 Unauthorized the token is refreshed and the request retried once. Requests with
+// a body must be created with http.NewRequest so the body can be replayed
+func Do(client *http.Client, source TokenSource, req *http.Request) (*http.Response, error) {
+    token, err := source.Token(req.Context())
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Authorization", "Bearer "+token)
+
+    resp, err := client.Do(req)
+    if err != nil || resp.StatusCode != http.StatusUnauthorized {
+        return resp, err
+    }
+    if _, static := source.(StaticToken); static {
+        return resp, nil
+    }
+    if req.Body != nil && req.GetBody == nil {
+        return resp, nil
+    }
+    resp.Body.Close()
+
+    source.Invalidate()
+    token, err = source.Token(req.Context())
+    if err != nil {
+        return nil, err
+    }
+
+    retry := req.Clone(req.Context())
+    if req.GetBody != nil {
+        if retry.Body, err = req.GetBody(); err != nil {
+            return nil, err
+        }
+    }
+    retry.Header.Set("Authorization", "Bearer "+token)
+
+    return client.Do(retry)
+}
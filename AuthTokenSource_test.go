@@ -0,0 +1,120 @@
+package auth
+
+import (
+    "context"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// fakeAPI accepts requests authorised with the valid token and rejects others with
+// 401 Unauthorized, recording the token and body of each request
+type fakeAPI struct {
+    valid    string
+    requests []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    body, _ := ioutil.ReadAll(r.Body)
+    token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+    f.requests = append(f.requests, strings.TrimSpace(token+" "+string(body)))
+    if token != f.valid {
+        w.WriteHeader(http.StatusUnauthorized)
+    }
+}
+
+// rotatingSource hands out numbered tokens, moving to the next one when invalidated
+type rotatingSource struct {
+    current int
+}
+
+func (s *rotatingSource) Token(ctx context.Context) (string, error) {
+    return fmt.Sprintf("token-%d", s.current+1), nil
+}
+
+func (s *rotatingSource) Invalidate() {
+    s.current++
+}
+
+func TestDo(t *testing.T) {
+    tests := []struct {
+        name     string
+        valid    string
+        source   TokenSource
+        body     string
+        noReplay bool // Send the body without GetBody, so it cannot be sent twice
+        status   int
+        want     []string
+    }{
+        {"accepted", "token-1", &rotatingSource{}, "", false, http.StatusOK, []string{"token-1"}},
+        {"refreshed", "token-2", &rotatingSource{}, "", false, http.StatusOK, []string{"token-1", "token-2"}},
+        {"body replayed", "token-2", &rotatingSource{}, "payload", false, http.StatusOK, []string{"token-1 payload", "token-2 payload"}},
+        {"retried once", "token-9", &rotatingSource{}, "", false, http.StatusUnauthorized, []string{"token-1", "token-2"}},
+        {"body not replayable", "token-2", &rotatingSource{}, "payload", true, http.StatusUnauthorized, []string{"token-1 payload"}},
+        {"static token", "other", StaticToken("token-1"), "", false, http.StatusUnauthorized, []string{"token-1"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeAPI{valid: tt.valid}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            var req *http.Request
+            switch {
+            case tt.body == "":
+                req, _ = http.NewRequest("GET", server.URL, nil)
+            case tt.noReplay:
+                req, _ = http.NewRequest("POST", server.URL, ioutil.NopCloser(strings.NewReader(tt.body)))
+            default:
+                req, _ = http.NewRequest("POST", server.URL, strings.NewReader(tt.body))
+            }
+
+            resp, err := Do(http.DefaultClient, tt.source, req)
+            if err != nil {
+                t.Fatalf("Do() error = %v", err)
+            }
+            resp.Body.Close()
+            if resp.StatusCode != tt.status {
+                t.Errorf("Do() status = %d, want %d", resp.StatusCode, tt.status)
+            }
+            if fmt.Sprint(fake.requests) != fmt.Sprint(tt.want) {
+                t.Errorf("requests = %q, want %q", fake.requests, tt.want)
+            }
+        })
+    }
+}
+
+func TestDoWithClientCredentials(t *testing.T) {
+    tokens := &fakeTokenEndpoint{expiresIn: "3600"}
+    tokenServer := httptest.NewServer(tokens)
+    defer tokenServer.Close()
+
+    // The API has revoked the first token before its expiry
+    api := &fakeAPI{valid: "token-2"}
+    apiServer := httptest.NewServer(api)
+    defer apiServer.Close()
+
+    source := &ClientCredentials{TokenURL: tokenServer.URL, ClientID: "client", ClientSecret: "secret"}
+    for i := 0; i < 2; i++ {
+        req, _ := http.NewRequest("GET", apiServer.URL, nil)
+        resp, err := Do(http.DefaultClient, source, req)
+        if err != nil {
+            t.Fatalf("Do() error = %v", err)
+        }
+        resp.Body.Close()
+        if resp.StatusCode != http.StatusOK {
+            t.Errorf("Do() status = %d, want %d", resp.StatusCode, http.StatusOK)
+        }
+    }
+
+    want := []string{"token-1", "token-2", "token-2"}
+    if fmt.Sprint(api.requests) != fmt.Sprint(want) {
+        t.Errorf("requests = %q, want %q", api.requests, want)
+    }
+    if tokens.issued != 2 {
+        t.Errorf("tokens issued = %d, want 2", tokens.issued)
+    }
+}
@@ -0,0 +1,45 @@
+package confluence
+
+// APIFlavor selects which Confluence REST API and authentication scheme is used
+type APIFlavor string
+
+const (
+    // FlavorCloudV2 uses the Cloud v2 API (/wiki/api/v2/pages) with basic auth
+    FlavorCloudV2 APIFlavor = "cloud-v2"
+    // FlavorCloudV1 uses the legacy Cloud v1 API (/wiki/rest/api/content) with basic auth
+    FlavorCloudV1 APIFlavor = "cloud-v1"
+    // FlavorDataCenter uses the Data Center/Server API (/rest/api/content) with a personal access token
+    FlavorDataCenter APIFlavor = "datacenter"
+)
+
+// ConfluenceConfig holds the API and placement settings used when syncing pages to Confluence
+type ConfluenceConfig struct {
+    Flavor   APIFlavor                       `yaml:"flavor"`    // Defaults to FlavorCloudV1
+    SpaceKey string                          `yaml:"space_key"` // Default space for new pages
+    ParentID string                          `yaml:"parent_id"` // Default parent page, empty for the space root
+    Pages    map[string]ConfluencePageConfig `yaml:"pages"`     // Per-page overrides keyed by page ID
+}
+
+// ConfluencePageConfig overrides the default placement for a single page
+type ConfluencePageConfig struct {
+    SpaceKey string `yaml:"space_key"`
+    ParentID string `yaml:"parent_id"`
+}
+
+// placement returns the space key and parent page ID for a page. A parent set on the
+// page itself takes precedence over the configured one
+func (c ConfluenceConfig) placement(page Page) (string, string) {
+    spaceKey, parentID := c.SpaceKey, c.ParentID
+    if override, ok := c.Pages[page.CanonicalID()]; ok {
+        if override.SpaceKey != "" {
+            spaceKey = override.SpaceKey
+        }
+        if override.ParentID != "" {
+            parentID = override.ParentID
+        }
+    }
+    if page.ParentID != "" {
+        parentID = page.ParentID
+    }
+    return spaceKey, parentID
+}
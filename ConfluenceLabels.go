@@ -0,0 +1,125 @@
+package confluence
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "sort"
+    "strings"
+)
+
+// labelName normalises a label to the form Confluence stores: lower case without spaces
+func labelName(label string) string {
+    return strings.ToLower(strings.Join(strings.Fields(label), "-"))
+}
+
+// getLabels returns the global labels currently applied to a page
+func (s *ConfluenceServiceImpl) getLabels(ctx context.Context, id string) (map[string]bool, error) {
+    if s.config.Flavor == FlavorCloudV2 {
+        return s.getLabelsV2(ctx, id)
+    }
+
+    const limit = 200
+    labels := map[string]bool{}
+
+    for start := 0; ; start += limit {
+        endpoint := fmt.Sprintf("%s/content/%s/label?prefix=global&start=%d&limit=%d", s.restAPI(), id, start, limit)
+
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "get labels", "GET", endpoint, nil, &result); err != nil {
+            return nil, err
+        }
+
+        items, _ := result["results"].([]interface{})
+        for _, item := range items {
+            if label, ok := item.(map[string]interface{}); ok {
+                name, _ := label["name"].(string)
+                labels[name] = true
+            }
+        }
+        if len(items) < limit {
+            break
+        }
+    }
+
+    return labels, nil
+}
+
+// pageLabels returns the global labels of a page in name order
+func (s *ConfluenceServiceImpl) pageLabels(ctx context.Context, id string) ([]string, error) {
+    current, err := s.getLabels(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    labels := make([]string, 0, len(current))
+    for name := range current {
+        labels = append(labels, name)
+    }
+    sort.Strings(labels)
+    return labels, nil
+}
+
+// ReadsLabels reports that GetPage returns the labels of a page
+func (s *ConfluenceServiceImpl) ReadsLabels() bool {
+    return true
+}
+
+// labelsProperty is the content property listing the labels the sync applied to a
+// page, so labels added by hand, such as protected labels, are never removed
+const labelsProperty = "sync-labels"
+
+// syncLabels makes the global labels of a page match the page's labels, adding
+// missing ones in a single request and removing the ones an earlier sync added that
+// are no longer wanted
+func (s *ConfluenceServiceImpl) syncLabels(ctx context.Context, id string, labels []string) error {
+    current, err := s.getLabels(ctx, id)
+    if err != nil {
+        return err
+    }
+
+    wanted := map[string]bool{}
+    for _, label := range labels {
+        if name := labelName(label); name != "" {
+            wanted[name] = true
+        }
+    }
+
+    var add []map[string]string
+    for name := range wanted {
+        if !current[name] {
+            add = append(add, map[string]string{"prefix": "global", "name": name})
+        }
+    }
+    sort.Slice(add, func(i, j int) bool { return add[i]["name"] < add[j]["name"] })
+
+    if len(add) > 0 {
+        endpoint := fmt.Sprintf("%s/content/%s/label", s.restAPI(), id)
+        if err := s.doRequest(ctx, "add labels", "POST", endpoint, add, nil); err != nil {
+            return err
+        }
+    }
+
+    // Pages synced before labels were tracked have no property, so nothing is removed
+    synced, _ := s.getProperty(ctx, id, labelsProperty)
+    previous, _ := synced.([]interface{})
+    for _, item := range previous {
+        name, _ := item.(string)
+        if !current[name] || wanted[name] {
+            continue
+        }
+        endpoint := fmt.Sprintf("%s/content/%s/label?name=%s", s.restAPI(), id, url.QueryEscape(name))
+        if err := s.doRequest(ctx, "remove label", "DELETE", endpoint, nil, nil); err != nil {
+            return err
+        }
+    }
+
+    names := make([]string, 0, len(wanted))
+    for name := range wanted {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    if len(names) == 0 && len(previous) == 0 {
+        return nil
+    }
+    return s.setProperty(ctx, id, labelsProperty, names)
+}
@@ -0,0 +1,125 @@
+package confluence
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+// fakeLabels serves the label and content property endpoints of a single page
+type fakeLabels struct {
+    labels   map[string]bool
+    property []string // Value of the sync-labels property, nil when it is missing
+    removed  []string
+}
+
+func (f *fakeLabels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/wiki/rest/api/content/1")
+    switch {
+    case path == "/label" && r.Method == "GET":
+        var results []map[string]string
+        for name := range f.labels {
+            results = append(results, map[string]string{"name": name})
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+    case path == "/label" && r.Method == "POST":
+        var add []map[string]string
+        json.NewDecoder(r.Body).Decode(&add)
+        for _, label := range add {
+            f.labels[label["name"]] = true
+        }
+    case path == "/label" && r.Method == "DELETE":
+        name := r.URL.Query().Get("name")
+        delete(f.labels, name)
+        f.removed = append(f.removed, name)
+    case path == "/property/"+labelsProperty && r.Method == "GET":
+        if f.property == nil {
+            http.NotFound(w, r)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"value": f.property, "version": map[string]int{"number": 1}})
+    case path == "/property" || path == "/property/"+labelsProperty:
+        var body struct{ Value []string }
+        json.NewDecoder(r.Body).Decode(&body)
+        f.property = append([]string{}, body.Value...)
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func TestSyncLabels(t *testing.T) {
+    tests := []struct {
+        name        string
+        current     []string
+        synced      []string // nil for a page synced before labels were tracked
+        labels      []string
+        wantLabels  []string
+        wantRemoved []string
+    }{
+        {
+            name:       "untracked page keeps every label",
+            current:    []string{"manual", "old"},
+            labels:     []string{"New Label"},
+            wantLabels: []string{"manual", "new-label", "old"},
+        },
+        {
+            name:        "only synced labels are removed",
+            current:     []string{"a", "b", "do-not-delete"},
+            synced:      []string{"a", "b"},
+            labels:      []string{"a"},
+            wantLabels:  []string{"a", "do-not-delete"},
+            wantRemoved: []string{"b"},
+        },
+        {
+            name:       "labels removed by hand are not removed again",
+            current:    []string{"manual"},
+            synced:     []string{"a"},
+            labels:     nil,
+            wantLabels: []string{"manual"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeLabels{labels: map[string]bool{}, property: tt.synced}
+            for _, name := range tt.current {
+                fake.labels[name] = true
+            }
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewConfluenceService(server.URL, "user", "token")
+            if err := s.syncLabels(context.Background(), "1", tt.labels); err != nil {
+                t.Fatal(err)
+            }
+
+            var labels []string
+            for name := range fake.labels {
+                labels = append(labels, name)
+            }
+            sort.Strings(labels)
+            if !reflect.DeepEqual(labels, tt.wantLabels) {
+                t.Errorf("labels = %q, want %q", labels, tt.wantLabels)
+            }
+            if !reflect.DeepEqual(fake.removed, tt.wantRemoved) {
+                t.Errorf("removed = %q, want %q", fake.removed, tt.wantRemoved)
+            }
+
+            var want []string
+            for _, label := range tt.labels {
+                want = append(want, labelName(label))
+            }
+            if len(want) == 0 && tt.synced == nil {
+                return
+            }
+            if fake.property == nil || strings.Join(fake.property, ",") != strings.Join(want, ",") {
+                t.Errorf("synced labels = %q, want %q", fake.property, want)
+            }
+        })
+    }
+}
@@ -0,0 +1,99 @@
+package confluence
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// ListPages lists the pages of the configured space. The v2 API pages through the
+// listing with cursors and v1 with offsets; both are followed through the next links
+// of each response
+func (s *ConfluenceServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    if s.config.SpaceKey == "" {
+        return listing.Failed(fmt.Errorf("no Confluence space configured to list pages from"))
+    }
+    if s.config.Flavor == FlavorCloudV2 {
+        return listing.New(ctx, s.listPagesV2)
+    }
+    return listing.New(ctx, s.listPagesV1)
+}
+
+// listPagesV1 fetches one batch of the space's pages from the content API
+func (s *ConfluenceServiceImpl) listPagesV1(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    endpoint := cursor
+    if endpoint == "" {
+        endpoint = fmt.Sprintf("%s/content?type=page&spaceKey=%s&expand=version,ancestors&limit=100", s.restAPI(), url.QueryEscape(s.config.SpaceKey))
+    }
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "list pages", "GET", endpoint, nil, &result); err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    items, _ := result["results"].([]interface{})
+    for _, item := range items {
+        content, _ := item.(map[string]interface{})
+        summary := listing.Summary{ParentID: currentParentID(content)}
+        summary.ID, _ = content["id"].(string)
+        summary.Title, _ = content["title"].(string)
+        version, _ := content["version"].(map[string]interface{})
+        if when, ok := version["when"].(string); ok {
+            summary.Updated, _ = time.Parse(time.RFC3339, when)
+        }
+        summaries = append(summaries, summary)
+    }
+
+    return summaries, s.nextV1URL(result), nil
+}
+
+// listPagesV2 fetches one batch of the space's pages from the Cloud v2 API
+func (s *ConfluenceServiceImpl) listPagesV2(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    endpoint := cursor
+    if endpoint == "" {
+        spaceID, err := s.spaceID(ctx, s.config.SpaceKey)
+        if err != nil {
+            return nil, "", err
+        }
+        endpoint = fmt.Sprintf("%s/spaces/%s/pages?limit=250", s.v2API(), spaceID)
+    }
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "list pages", "GET", endpoint, nil, &result); err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    items, _ := result["results"].([]interface{})
+    for _, item := range items {
+        page, _ := item.(map[string]interface{})
+        var summary listing.Summary
+        summary.ID, _ = page["id"].(string)
+        summary.Title, _ = page["title"].(string)
+        summary.ParentID, _ = page["parentId"].(string)
+        version, _ := page["version"].(map[string]interface{})
+        if created, ok := version["createdAt"].(string); ok {
+            summary.Updated, _ = time.Parse(time.RFC3339, created)
+        }
+        summaries = append(summaries, summary)
+    }
+
+    return summaries, s.nextPageURL(result), nil
+}
+
+// nextV1URL returns the absolute URL of the next page of a v1 listing, or "" when the
+// listing is complete. v1 links are relative to the context path the REST API is
+// served under
+func (s *ConfluenceServiceImpl) nextV1URL(result map[string]interface{}) string {
+    links, _ := result["_links"].(map[string]interface{})
+    next, _ := links["next"].(string)
+    if next == "" {
+        return ""
+    }
+    return strings.TrimSuffix(s.restAPI(), "/rest/api") + next
+}
@@ -0,0 +1,130 @@
+package confluence
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakeSpacePages serves the pages of space DOCS, two per listing page, through the v1
+// content API under both context paths and through the v2 pages API
+type fakeSpacePages struct {
+    pages    []listing.Summary
+    requests []string // Paths of the listing requests, with their offset or cursor
+}
+
+func (f *fakeSpacePages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    switch {
+    case r.URL.Path == "/wiki/api/v2/spaces" && query.Get("keys") == "DOCS":
+        json.NewEncoder(w).Encode(map[string]interface{}{"results": []map[string]string{{"id": "42"}}})
+
+    case r.URL.Path == "/wiki/api/v2/spaces/42/pages":
+        f.requests = append(f.requests, r.URL.Path+" "+query.Get("cursor"))
+        start, _ := strconv.Atoi(query.Get("cursor"))
+        var results []interface{}
+        for _, page := range f.batch(start) {
+            results = append(results, map[string]interface{}{
+                "id":       page.ID,
+                "title":    page.Title,
+                "parentId": page.ParentID,
+                "version":  map[string]string{"createdAt": page.Updated.Format(time.RFC3339)},
+            })
+        }
+        result := map[string]interface{}{"results": results}
+        if start+2 < len(f.pages) {
+            result["_links"] = map[string]string{"next": fmt.Sprintf("/wiki/api/v2/spaces/42/pages?cursor=%d", start+2)}
+        }
+        json.NewEncoder(w).Encode(result)
+
+    case strings.HasSuffix(r.URL.Path, "/rest/api/content") && query.Get("spaceKey") == "DOCS":
+        f.requests = append(f.requests, r.URL.Path+" "+query.Get("start"))
+        start, _ := strconv.Atoi(query.Get("start"))
+        var results []interface{}
+        for _, page := range f.batch(start) {
+            var ancestors []interface{}
+            if page.ParentID != "" {
+                ancestors = []interface{}{map[string]string{"id": "1"}, map[string]string{"id": page.ParentID}}
+            }
+            results = append(results, map[string]interface{}{
+                "id":        page.ID,
+                "title":     page.Title,
+                "ancestors": ancestors,
+                "version":   map[string]string{"when": page.Updated.Format(time.RFC3339)},
+            })
+        }
+        result := map[string]interface{}{"results": results}
+        if start+2 < len(f.pages) {
+            // v1 links are relative to the context path
+            result["_links"] = map[string]string{"next": fmt.Sprintf("/rest/api/content?type=page&spaceKey=DOCS&start=%d", start+2)}
+        }
+        json.NewEncoder(w).Encode(result)
+
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+// batch returns the pages of the listing page starting at start
+func (f *fakeSpacePages) batch(start int) []listing.Summary {
+    if start+2 < len(f.pages) {
+        return f.pages[start : start+2]
+    }
+    return f.pages[start:]
+}
+
+func TestListPages(t *testing.T) {
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    pages := []listing.Summary{
+        {ID: "1", Title: "Home", Updated: updated},
+        {ID: "2", Title: "Guide", Updated: updated, ParentID: "1"},
+        {ID: "3", Title: "Install", Updated: updated, ParentID: "2"},
+        {ID: "4", Title: "FAQ", Updated: updated, ParentID: "1"},
+        {ID: "5", Title: "Release notes", Updated: updated, ParentID: "1"},
+    }
+
+    tests := []struct {
+        flavor   APIFlavor
+        requests []string
+    }{
+        {FlavorCloudV1, []string{"/wiki/rest/api/content ", "/wiki/rest/api/content 2", "/wiki/rest/api/content 4"}},
+        {FlavorDataCenter, []string{"/rest/api/content ", "/rest/api/content 2", "/rest/api/content 4"}},
+        {FlavorCloudV2, []string{"/wiki/api/v2/spaces/42/pages ", "/wiki/api/v2/spaces/42/pages 2", "/wiki/api/v2/spaces/42/pages 4"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(string(tt.flavor), func(t *testing.T) {
+            fake := &fakeSpacePages{pages: pages}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewConfluenceServiceWithConfig(server.URL, "user", "token", ConfluenceConfig{Flavor: tt.flavor, SpaceKey: "DOCS"})
+            got, err := s.ListPages(context.Background()).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(got, pages) {
+                t.Errorf("ListPages() = %v, want %v", got, pages)
+            }
+            if !reflect.DeepEqual(fake.requests, tt.requests) {
+                t.Errorf("requests = %q, want %q", fake.requests, tt.requests)
+            }
+        })
+    }
+}
+
+func TestListPagesWithoutSpace(t *testing.T) {
+    s := NewConfluenceServiceWithConfig("http://confluence.invalid", "user", "token", ConfluenceConfig{})
+    if _, err := s.ListPages(context.Background()).All(); err == nil {
+        t.Error("ListPages() without a space succeeded, want an error")
+    }
+}
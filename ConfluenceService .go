@@ -1,6 +1,10 @@
 package confluence
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // ConfluenceService defines the methods for interacting with Confluence
 type ConfluenceService interface {
@@ -8,4 +12,5 @@ type ConfluenceService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
@@ -7,6 +7,7 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "sync"
 )
 
 // ConfluenceServiceImpl is the implementation of the ConfluenceService interface
@@ -14,25 +15,51 @@ type ConfluenceServiceImpl struct {
     baseURL   string
     username   string
     apiToken   string
+    config     ConfluenceConfig
+
+    mu       sync.Mutex
+    spaceIDs map[string]string // Cloud v2 space IDs by space key
 }
 
-// NewConfluenceService creates a new instance of ConfluenceService
+// NewConfluenceService creates a new instance of ConfluenceService using the Cloud v1 API
 func NewConfluenceService(baseURL, username, apiToken string) *ConfluenceServiceImpl {
     return &ConfluenceServiceImpl{baseURL: baseURL, username: username, apiToken: apiToken}
 }
 
-// CreatePage creates a new page in Confluence
+// NewConfluenceServiceWithConfig creates a new instance of ConfluenceService that uses
+// the configured API flavor and places pages according to its space and parent settings.
+// For Data Center the apiToken is a personal access token and username is ignored
+func NewConfluenceServiceWithConfig(baseURL, username, apiToken string, config ConfluenceConfig) *ConfluenceServiceImpl {
+    return &ConfluenceServiceImpl{baseURL: baseURL, username: username, apiToken: apiToken, config: config}
+}
+
+// CreatePage creates a new page in Confluence, converting its Markdown content to storage format
 func (s *ConfluenceServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
-    url := fmt.Sprintf("%s/wiki/rest/api/content", s.baseURL)
-    reqBody, _ := json.Marshal(map[string]interface{}{
+    if s.config.Flavor == FlavorCloudV2 {
+        return s.createPageV2(ctx, page)
+    }
+
+    url := fmt.Sprintf("%s/content", s.restAPI())
+
+    spaceKey, parentID := s.config.placement(page)
+    if spaceKey == "" {
+        return "", fmt.Errorf("no Confluence space configured for page %s", page.ID)
+    }
+
+    content := map[string]interface{}{
         "type":    "page",
         "title":   page.Title,
-        "body":    map[string]interface{}{"storage": map[string]string{"value": page.Content, "representation": "storage"}},
+        "space":   map[string]string{"key": spaceKey},
+        "body":    map[string]interface{}{"storage": map[string]string{"value": markdownToStorage(page.Content), "representation": "storage"}},
         "version": map[string]int{"number": 1},
-    })
+    }
+    if parentID != "" {
+        content["ancestors"] = []map[string]string{{"id": parentID}}
+    }
+    reqBody, _ := json.Marshal(content)
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-    req.SetBasicAuth(s.username, s.apiToken)
+    s.authorize(req)
     req.Header.Set("Content-Type", "application/json")
 
     client := &http.Client{}
@@ -54,17 +81,30 @@ func (s *ConfluenceServiceImpl) CreatePage(ctx context.Context, page Page) (stri
         return "", fmt.Errorf("failed to parse page ID")
     }
 
+    if err := s.syncLabels(ctx, id, page.Labels); err != nil {
+        return id, err
+    }
+
+    if err := s.applyPosition(ctx, id, parentID, page.Position); err != nil {
+        return id, err
+    }
+
     return id, nil
 }
 
-// UpdatePage updates an existing page in Confluence
+// UpdatePage updates an existing page in Confluence, moving it when its parent page
+// has changed, reordering it among its siblings and syncing its labels
 func (s *ConfluenceServiceImpl) UpdatePage(ctx context.Context, page Page) error {
+    if s.config.Flavor == FlavorCloudV2 {
+        return s.updatePageV2(ctx, page)
+    }
+
     pageID := page.ID
-    url := fmt.Sprintf("%s/wiki/rest/api/content/%s", s.baseURL, pageID)
+    url := fmt.Sprintf("%s/content/%s", s.restAPI(), pageID)
 
-    // Fetch current version
-    req, _ := http.NewRequest("GET", url, nil)
-    req.SetBasicAuth(s.username, s.apiToken)
+    // Fetch current version and location
+    req, _ := http.NewRequest("GET", url+"?expand=version,ancestors", nil)
+    s.authorize(req)
     resp, err := http.DefaultClient.Do(req)
     if err != nil {
         return err
@@ -81,14 +121,23 @@ func (s *ConfluenceServiceImpl) UpdatePage(ctx context.Context, page Page) error
     currentVersion := result["version"].(map[string]interface{})["number"].(float64)
     newVersion := int(currentVersion) + 1
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
+    content := map[string]interface{}{
+        "type":    "page",
         "version": map[string]int{"number": newVersion},
         "title":   page.Title,
-        "body":    map[string]interface{}{"storage": map[string]string{"value": page.Content, "representation": "storage"}},
-    })
+        "body":    map[string]interface{}{"storage": map[string]string{"value": markdownToStorage(page.Content), "representation": "storage"}},
+    }
+    _, parentID := s.config.placement(page)
+    if parentID != "" && parentID != currentParentID(result) {
+        content["ancestors"] = []map[string]string{{"id": parentID}}
+    }
+    if parentID == "" {
+        parentID = currentParentID(result)
+    }
+    reqBody, _ := json.Marshal(content)
 
     req, _ = http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
-    req.SetBasicAuth(s.username, s.apiToken)
+    s.authorize(req)
     req.Header.Set("Content-Type", "application/json")
 
     resp, err = http.DefaultClient.Do(req)
@@ -101,15 +150,23 @@ func (s *ConfluenceServiceImpl) UpdatePage(ctx context.Context, page Page) error
         return fmt.Errorf("failed to update page: %s", resp.Status)
     }
 
-    return nil
+    if err := s.syncLabels(ctx, pageID, page.Labels); err != nil {
+        return err
+    }
+
+    return s.applyPosition(ctx, pageID, parentID, page.Position)
 }
 
 // DeletePage deletes a page in Confluence
 func (s *ConfluenceServiceImpl) DeletePage(ctx context.Context, id string) error {
-    url := fmt.Sprintf("%s/wiki/rest/api/content/%s", s.baseURL, id)
+    if s.config.Flavor == FlavorCloudV2 {
+        return s.deletePageV2(ctx, id)
+    }
+
+    url := fmt.Sprintf("%s/content/%s", s.restAPI(), id)
 
     req, _ := http.NewRequest("DELETE", url, nil)
-    req.SetBasicAuth(s.username, s.apiToken)
+    s.authorize(req)
 
     resp, err := http.DefaultClient.Do(req)
     if err != nil {
@@ -124,12 +181,29 @@ func (s *ConfluenceServiceImpl) DeletePage(ctx context.Context, id string) error
     return nil
 }
 
-// GetPage retrieves a page from Confluence
+// ArchivePage moves a page to the space archive, from where it can be restored.
+// Archiving is only available on Confluence Cloud
+func (s *ConfluenceServiceImpl) ArchivePage(ctx context.Context, id string) error {
+    if s.config.Flavor == FlavorDataCenter {
+        return fmt.Errorf("failed to archive page %s: archiving is not supported by Confluence Data Center", id)
+    }
+    body := map[string]interface{}{
+        "pages": []map[string]interface{}{{"id": json.Number(id)}},
+    }
+    return s.doRequest(ctx, "archive page", "POST", s.restAPI()+"/content/archive", body, nil)
+}
+
+// GetPage retrieves a page from Confluence, converting its body back to Markdown and
+// reporting its direct parent and labels
 func (s *ConfluenceServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
-    url := fmt.Sprintf("%s/wiki/rest/api/content/%s", s.baseURL, id)
+    if s.config.Flavor == FlavorCloudV2 {
+        return s.getPageV2(ctx, id)
+    }
+
+    url := fmt.Sprintf("%s/content/%s?expand=body.storage,version,ancestors", s.restAPI(), id)
 
     req, _ := http.NewRequest("GET", url, nil)
-    req.SetBasicAuth(s.username, s.apiToken)
+    s.authorize(req)
 
     resp, err := http.DefaultClient.Do(req)
     if err != nil {
@@ -138,7 +212,8 @@ func (s *ConfluenceServiceImpl) GetPage(ctx context.Context, id string) (Page, e
     defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
-        return Page{}, fmt.Errorf("failed to get page: %s", resp.Status)
+        body, _ := ioutil.ReadAll(resp.Body)
+        return Page{}, fmt.Errorf("failed to get page: %s - %s", resp.Status, body)
     }
 
     var result map[string]interface{}
@@ -148,9 +223,83 @@ func (s *ConfluenceServiceImpl) GetPage(ctx context.Context, id string) (Page, e
     title := result["title"].(string)
     content := result["body"].(map[string]interface{})["storage"].(map[string]interface{})["value"].(string)
 
+    labels, err := s.pageLabels(ctx, pageID)
+    if err != nil {
+        return Page{}, err
+    }
+
     return Page{
-        ID:      pageID,
-        Title:   title,
-        Content: content,
+        ID:       pageID,
+        Title:    title,
+        Content:  storageToMarkdown(content),
+        Labels:   labels,
+        ParentID: currentParentID(result),
     }, nil
 }
+
+// restAPI returns the root of the content REST API for the configured flavor. The
+// Cloud v2 flavor still uses it for operations v2 does not offer, such as writing labels
+func (s *ConfluenceServiceImpl) restAPI() string {
+    if s.config.Flavor == FlavorDataCenter {
+        return s.baseURL + "/rest/api"
+    }
+    return s.baseURL + "/wiki/rest/api"
+}
+
+// authorize adds credentials for the configured flavor: a bearer personal access
+// token for Data Center, basic auth with the API token for Cloud
+func (s *ConfluenceServiceImpl) authorize(req *http.Request) {
+    if s.config.Flavor == FlavorDataCenter {
+        req.Header.Set("Authorization", "Bearer "+s.apiToken)
+        return
+    }
+    req.SetBasicAuth(s.username, s.apiToken)
+}
+
+// currentParentID returns the direct parent of a page from its expanded ancestors
+func currentParentID(content map[string]interface{}) string {
+    ancestors, _ := content["ancestors"].([]interface{})
+    if len(ancestors) == 0 {
+        return ""
+    }
+    parent, _ := ancestors[len(ancestors)-1].(map[string]interface{})
+    id, _ := parent["id"].(string)
+    return id
+}
+
+// doRequest sends an authenticated JSON request to Confluence and decodes the
+// response into out when it is not nil
+func (s *ConfluenceServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    s.authorize(req)
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
@@ -0,0 +1,200 @@
+package confluence
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+)
+
+// v2API returns the root of the Cloud v2 REST API
+func (s *ConfluenceServiceImpl) v2API() string {
+    return s.baseURL + "/wiki/api/v2"
+}
+
+// createPageV2 creates a page through the Cloud v2 API
+func (s *ConfluenceServiceImpl) createPageV2(ctx context.Context, page Page) (string, error) {
+    spaceKey, parentID := s.config.placement(page)
+    if spaceKey == "" {
+        return "", fmt.Errorf("no Confluence space configured for page %s", page.ID)
+    }
+    spaceID, err := s.spaceID(ctx, spaceKey)
+    if err != nil {
+        return "", err
+    }
+
+    content := map[string]interface{}{
+        "spaceId": spaceID,
+        "status":  "current",
+        "title":   page.Title,
+        "body":    map[string]string{"representation": "storage", "value": markdownToStorage(page.Content)},
+    }
+    if parentID != "" {
+        content["parentId"] = parentID
+    }
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "create page", "POST", s.v2API()+"/pages", content, &result); err != nil {
+        return "", err
+    }
+
+    id, ok := result["id"].(string)
+    if !ok {
+        return "", fmt.Errorf("failed to parse page ID")
+    }
+
+    if err := s.syncLabels(ctx, id, page.Labels); err != nil {
+        return id, err
+    }
+
+    if err := s.applyPosition(ctx, id, parentID, page.Position); err != nil {
+        return id, err
+    }
+
+    return id, nil
+}
+
+// updatePageV2 updates a page through the Cloud v2 API, moving it when its parent
+// page has changed
+func (s *ConfluenceServiceImpl) updatePageV2(ctx context.Context, page Page) error {
+    endpoint := fmt.Sprintf("%s/pages/%s", s.v2API(), page.ID)
+
+    var current map[string]interface{}
+    if err := s.doRequest(ctx, "get page", "GET", endpoint, nil, &current); err != nil {
+        return err
+    }
+
+    version, _ := current["version"].(map[string]interface{})
+    number, _ := version["number"].(float64)
+
+    content := map[string]interface{}{
+        "id":      page.ID,
+        "status":  "current",
+        "title":   page.Title,
+        "body":    map[string]string{"representation": "storage", "value": markdownToStorage(page.Content)},
+        "version": map[string]int{"number": int(number) + 1},
+    }
+    currentParent, _ := current["parentId"].(string)
+    _, parentID := s.config.placement(page)
+    if parentID != "" && parentID != currentParent {
+        content["parentId"] = parentID
+    }
+    if parentID == "" {
+        parentID = currentParent
+    }
+
+    if err := s.doRequest(ctx, "update page", "PUT", endpoint, content, nil); err != nil {
+        return err
+    }
+
+    if err := s.syncLabels(ctx, page.ID, page.Labels); err != nil {
+        return err
+    }
+
+    return s.applyPosition(ctx, page.ID, parentID, page.Position)
+}
+
+// deletePageV2 moves a page to the trash through the Cloud v2 API
+func (s *ConfluenceServiceImpl) deletePageV2(ctx context.Context, id string) error {
+    return s.doRequest(ctx, "delete page", "DELETE", fmt.Sprintf("%s/pages/%s", s.v2API(), id), nil, nil)
+}
+
+// getPageV2 retrieves a page through the Cloud v2 API, converting its body back to Markdown
+func (s *ConfluenceServiceImpl) getPageV2(ctx context.Context, id string) (Page, error) {
+    endpoint := fmt.Sprintf("%s/pages/%s?body-format=storage", s.v2API(), id)
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "get page", "GET", endpoint, nil, &result); err != nil {
+        return Page{}, err
+    }
+
+    pageID, _ := result["id"].(string)
+    title, _ := result["title"].(string)
+    body, _ := result["body"].(map[string]interface{})
+    storage, _ := body["storage"].(map[string]interface{})
+    content, _ := storage["value"].(string)
+    parentID, _ := result["parentId"].(string)
+
+    labels, err := s.pageLabels(ctx, pageID)
+    if err != nil {
+        return Page{}, err
+    }
+
+    return Page{
+        ID:       pageID,
+        Title:    title,
+        Content:  storageToMarkdown(content),
+        Labels:   labels,
+        ParentID: parentID,
+    }, nil
+}
+
+// getLabelsV2 returns the global labels of a page, following v2 cursor pagination
+func (s *ConfluenceServiceImpl) getLabelsV2(ctx context.Context, id string) (map[string]bool, error) {
+    labels := map[string]bool{}
+    endpoint := fmt.Sprintf("%s/pages/%s/labels?prefix=global&limit=250", s.v2API(), id)
+
+    for endpoint != "" {
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "get labels", "GET", endpoint, nil, &result); err != nil {
+            return nil, err
+        }
+
+        items, _ := result["results"].([]interface{})
+        for _, item := range items {
+            if label, ok := item.(map[string]interface{}); ok {
+                name, _ := label["name"].(string)
+                labels[name] = true
+            }
+        }
+
+        endpoint = s.nextPageURL(result)
+    }
+
+    return labels, nil
+}
+
+// nextPageURL returns the absolute URL of the next page of a v2 listing, or ""
+// when the listing is complete. v2 responses carry a site-relative cursor link
+func (s *ConfluenceServiceImpl) nextPageURL(result map[string]interface{}) string {
+    links, _ := result["_links"].(map[string]interface{})
+    next, _ := links["next"].(string)
+    if next == "" {
+        return ""
+    }
+    return s.baseURL + next
+}
+
+// spaceID resolves a space key to the numeric ID the v2 API expects, caching the result
+func (s *ConfluenceServiceImpl) spaceID(ctx context.Context, key string) (string, error) {
+    s.mu.Lock()
+    id, ok := s.spaceIDs[key]
+    s.mu.Unlock()
+    if ok {
+        return id, nil
+    }
+
+    var result map[string]interface{}
+    endpoint := fmt.Sprintf("%s/spaces?keys=%s", s.v2API(), url.QueryEscape(key))
+    if err := s.doRequest(ctx, "get space", "GET", endpoint, nil, &result); err != nil {
+        return "", err
+    }
+
+    items, _ := result["results"].([]interface{})
+    if len(items) == 0 {
+        return "", fmt.Errorf("space %s not found in Confluence", key)
+    }
+    space, _ := items[0].(map[string]interface{})
+    id, _ = space["id"].(string)
+    if id == "" {
+        return "", fmt.Errorf("failed to parse space ID")
+    }
+
+    s.mu.Lock()
+    if s.spaceIDs == nil {
+        s.spaceIDs = map[string]string{}
+    }
+    s.spaceIDs[key] = id
+    s.mu.Unlock()
+
+    return id, nil
+}
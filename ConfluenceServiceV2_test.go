@@ -0,0 +1,104 @@
+package confluence
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+)
+
+func TestFlavorEndpoints(t *testing.T) {
+    tests := []struct {
+        flavor   APIFlavor
+        wantPath string
+        wantAuth string
+    }{
+        {flavor: FlavorCloudV1, wantPath: "/wiki/rest/api/content/1", wantAuth: "Basic dXNlcjp0b2tlbg=="},
+        {flavor: FlavorCloudV2, wantPath: "/wiki/api/v2/pages/1", wantAuth: "Basic dXNlcjp0b2tlbg=="},
+        {flavor: FlavorDataCenter, wantPath: "/rest/api/content/1", wantAuth: "Bearer token"},
+    }
+
+    for _, tt := range tests {
+        t.Run(string(tt.flavor), func(t *testing.T) {
+            var paths, auths []string
+            server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                paths = append(paths, r.URL.Path)
+                auths = append(auths, r.Header.Get("Authorization"))
+                json.NewEncoder(w).Encode(map[string]interface{}{
+                    "id":      "1",
+                    "title":   "Home",
+                    "body":    map[string]interface{}{"storage": map[string]string{"value": "<p>Hi</p>"}},
+                    "results": []interface{}{},
+                })
+            }))
+            defer server.Close()
+
+            s := NewConfluenceServiceWithConfig(server.URL, "user", "token", ConfluenceConfig{Flavor: tt.flavor})
+            page, err := s.GetPage(context.Background(), "1")
+            if err != nil {
+                t.Fatal(err)
+            }
+            if page.Title != "Home" || page.Content != "Hi\n" {
+                t.Errorf("GetPage() = %q, %q, want \"Home\", \"Hi\\n\"", page.Title, page.Content)
+            }
+            if len(paths) == 0 || paths[0] != tt.wantPath {
+                t.Errorf("requested %q, want %s first", paths, tt.wantPath)
+            }
+            for _, auth := range auths {
+                if auth != tt.wantAuth {
+                    t.Errorf("Authorization = %q, want %q", auth, tt.wantAuth)
+                }
+            }
+        })
+    }
+}
+
+func TestGetLabelsV2FollowsCursor(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        result := map[string]interface{}{"results": []map[string]string{{"name": "first"}}}
+        if r.URL.Query().Get("cursor") == "" {
+            result["_links"] = map[string]string{"next": "/wiki/api/v2/pages/1/labels?cursor=abc"}
+        } else {
+            result["results"] = []map[string]string{{"name": "second"}}
+        }
+        json.NewEncoder(w).Encode(result)
+    }))
+    defer server.Close()
+
+    s := NewConfluenceServiceWithConfig(server.URL, "user", "token", ConfluenceConfig{Flavor: FlavorCloudV2})
+    labels, err := s.getLabels(context.Background(), "1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := map[string]bool{"first": true, "second": true}; !reflect.DeepEqual(labels, want) {
+        t.Errorf("getLabels() = %v, want %v", labels, want)
+    }
+}
+
+func TestSpaceIDIsCached(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        if got := r.URL.Query().Get("keys"); got != "DOCS" {
+            t.Errorf("keys = %q, want DOCS", got)
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"results": []map[string]string{{"id": "42"}}})
+    }))
+    defer server.Close()
+
+    s := NewConfluenceServiceWithConfig(server.URL, "user", "token", ConfluenceConfig{Flavor: FlavorCloudV2})
+    for i := 0; i < 2; i++ {
+        id, err := s.spaceID(context.Background(), "DOCS")
+        if err != nil {
+            t.Fatal(err)
+        }
+        if id != "42" {
+            t.Errorf("spaceID() = %q, want 42", id)
+        }
+    }
+    if requests != 1 {
+        t.Errorf("space lookups = %d, want 1", requests)
+    }
+}
@@ -0,0 +1,700 @@
+package confluence
+
+import (
+    "encoding/base64"
+    "encoding/xml"
+    "fmt"
+    "html"
+    "io"
+    "regexp"
+    "sort"
+    "strings"
+
+    "Support_Site_Sync/markdown"
+)
+
+// Canonical page content is Markdown. Confluence constructs without a Markdown
+// equivalent use the following conventions so they survive a round trip:
+//
+//   ```lang ... ```                       code macro
+//   > [!INFO] Optional title              info, note, warning and tip panels
+//   <details><summary>T</summary> ... </details>   expand macro
+//   [TOC] or [TOC maxLevel=3]             table of contents macro
+//   :status[Done]{colour=Green}           status lozenge
+//   <!-- confluence:macro ... -->         any other macro, kept verbatim
+//
+// Text that Markdown would read as markup, such as the asterisks of "5*3*2", is
+// backslash-escaped. markdownToStorage and storageToMarkdown convert between the
+// two formats.
+
+// panelMacros maps Markdown admonition kinds to Confluence panel macro names
+var panelMacros = map[string]string{
+    "INFO":    "info",
+    "NOTE":    "note",
+    "WARNING": "warning",
+    "TIP":     "tip",
+}
+
+// preservedPrefix marks an unknown macro kept verbatim in an HTML comment
+const preservedPrefix = "confluence:macro "
+
+// preservedEncodedPrefix is used instead when the macro itself contains "--"
+const preservedEncodedPrefix = "confluence:macro64 "
+
+var (
+    tocPattern        = regexp.MustCompile(`^\[TOC((?:\s+\w+=\S+)*)\]$`)
+    whitespacePattern = regexp.MustCompile(`\s+`)
+
+    preservedPattern   = regexp.MustCompile(`<!-- confluence:macro(64)? ([\s\S]*?) -->`)
+    statusPattern      = regexp.MustCompile(`:status\[([^\]]*)\](?:\{([^}]*)\})?`)
+    inlineMacroPattern = regexp.MustCompile(preservedPattern.String() + "|" + statusPattern.String())
+)
+
+// storageMarkdown parses Markdown with the conventions above: panels, expand macros,
+// tables of contents and preserved macros on their own line, and status lozenges
+// and preserved macros inside text
+var storageMarkdown = markdown.Parser{
+    Details: true,
+    Alerts:  panelKinds(),
+    RawLine: func(line string) bool {
+        return tocPattern.MatchString(line) || preservedPattern.FindString(line) == line
+    },
+    RawInline: inlineMacroPattern,
+}
+
+// panelKinds lists the admonition kinds that have a panel macro
+func panelKinds() []string {
+    kinds := make([]string, 0, len(panelMacros))
+    for kind := range panelMacros {
+        kinds = append(kinds, kind)
+    }
+    return kinds
+}
+
+// markdownToStorage converts Markdown content into Confluence storage format
+func markdownToStorage(content string) string {
+    return convertMarkdownBlocks(storageMarkdown.Parse(content))
+}
+
+// convertMarkdownBlocks converts parsed Markdown blocks into storage format
+func convertMarkdownBlocks(blocks []markdown.Block) string {
+    var b strings.Builder
+    for _, block := range blocks {
+        switch block.Kind {
+        case markdown.ParagraphBlock:
+            b.WriteString("<p>" + convertInline(block.Text) + "</p>")
+
+        case markdown.HeadingBlock:
+            b.WriteString(fmt.Sprintf("<h%d>%s</h%d>", block.Level, convertInline(block.Text), block.Level))
+
+        case markdown.CodeBlock:
+            b.WriteString(codeMacro(block.Language, block.Text))
+
+        case markdown.RawBlock:
+            if m := tocPattern.FindStringSubmatch(block.Text); m != nil {
+                b.WriteString(`<ac:structured-macro ac:name="toc">`)
+                for _, param := range strings.Fields(m[1]) {
+                    kv := strings.SplitN(param, "=", 2)
+                    b.WriteString(macroParameter(kv[0], kv[1]))
+                }
+                b.WriteString(`</ac:structured-macro>`)
+            } else {
+                b.WriteString(restorePreserved(block.Text))
+            }
+
+        case markdown.DetailsBlock:
+            b.WriteString(`<ac:structured-macro ac:name="expand">`)
+            if block.Text != "" {
+                b.WriteString(macroParameter("title", html.UnescapeString(block.Text)))
+            }
+            b.WriteString("<ac:rich-text-body>" + convertMarkdownBlocks(block.Children) + "</ac:rich-text-body></ac:structured-macro>")
+
+        case markdown.QuoteBlock:
+            if block.Alert == "" {
+                b.WriteString("<blockquote>" + convertMarkdownBlocks(block.Children) + "</blockquote>")
+                continue
+            }
+            b.WriteString(fmt.Sprintf(`<ac:structured-macro ac:name="%s">`, panelMacros[block.Alert]))
+            if block.Text != "" {
+                b.WriteString(macroParameter("title", block.Text))
+            }
+            b.WriteString("<ac:rich-text-body>" + convertMarkdownBlocks(block.Children) + "</ac:rich-text-body></ac:structured-macro>")
+
+        case markdown.RuleBlock:
+            b.WriteString("<hr />")
+
+        case markdown.ListBlock:
+            b.WriteString(convertList(block))
+
+        case markdown.TableBlock:
+            b.WriteString(convertTable(block.Rows))
+        }
+    }
+    return b.String()
+}
+
+// convertList converts a list and the lists nested in its items
+func convertList(list markdown.Block) string {
+    tag := "ul"
+    if list.Ordered {
+        tag = "ol"
+    }
+
+    var b strings.Builder
+    b.WriteString("<" + tag + ">")
+    for _, item := range list.Children {
+        b.WriteString("<li>" + html.EscapeString(item.Box()) + convertInline(item.Text))
+        b.WriteString(convertMarkdownBlocks(item.Children))
+        b.WriteString("</li>")
+    }
+    b.WriteString("</" + tag + ">")
+    return b.String()
+}
+
+// convertTable converts the rows of a Markdown pipe table; the first row becomes the header
+func convertTable(rows [][]string) string {
+    var b strings.Builder
+    b.WriteString("<table><tbody>")
+    for r, row := range rows {
+        cell := "td"
+        if r == 0 {
+            cell = "th"
+        }
+        b.WriteString("<tr>")
+        for _, value := range row {
+            b.WriteString("<" + cell + ">" + convertInline(value) + "</" + cell + ">")
+        }
+        b.WriteString("</tr>")
+    }
+    b.WriteString("</tbody></table>")
+    return b.String()
+}
+
+// convertInline converts inline Markdown (emphasis, code, links, images and
+// status lozenges) into storage format
+func convertInline(text string) string {
+    return convertSpans(storageMarkdown.ParseInline(text))
+}
+
+// convertSpans converts parsed inline spans into storage format; line breaks are kept
+func convertSpans(spans []markdown.Span) string {
+    var b strings.Builder
+    for _, span := range spans {
+        switch span.Kind {
+        case markdown.TextSpan:
+            b.WriteString(html.EscapeString(span.Text))
+        case markdown.BreakSpan:
+            b.WriteString("<br />")
+        case markdown.CodeSpan:
+            b.WriteString("<code>" + html.EscapeString(span.Text) + "</code>")
+        case markdown.StrongSpan:
+            b.WriteString("<strong>" + convertSpans(span.Children) + "</strong>")
+        case markdown.EmphasisSpan:
+            b.WriteString("<em>" + convertSpans(span.Children) + "</em>")
+        case markdown.LinkSpan:
+            b.WriteString(`<a href="` + html.EscapeString(span.URL) + `">` + convertSpans(span.Children) + "</a>")
+        case markdown.ImageSpan:
+            b.WriteString(imageMacro(span.Text, span.URL))
+        case markdown.RawSpan:
+            if m := statusPattern.FindStringSubmatch(span.Text); m != nil && m[0] == span.Text {
+                b.WriteString(statusMacro(m[1], m[2]))
+            } else {
+                b.WriteString(restorePreserved(span.Text))
+            }
+        }
+    }
+    return b.String()
+}
+
+// codeMacro builds a code block macro
+func codeMacro(language, body string) string {
+    var b strings.Builder
+    b.WriteString(`<ac:structured-macro ac:name="code">`)
+    if language != "" {
+        b.WriteString(macroParameter("language", language))
+    }
+    b.WriteString("<ac:plain-text-body><![CDATA[" + strings.ReplaceAll(body, "]]>", "]]]]><![CDATA[>") + "]]></ac:plain-text-body>")
+    b.WriteString(`</ac:structured-macro>`)
+    return b.String()
+}
+
+// statusMacro builds a status lozenge from its title and "key=value" parameters
+func statusMacro(title, params string) string {
+    var b strings.Builder
+    b.WriteString(`<ac:structured-macro ac:name="status">`)
+    for _, param := range strings.FieldsFunc(params, func(r rune) bool { return r == ' ' || r == ',' }) {
+        kv := strings.SplitN(param, "=", 2)
+        if len(kv) == 2 {
+            b.WriteString(macroParameter(kv[0], kv[1]))
+        }
+    }
+    b.WriteString(macroParameter("title", title))
+    b.WriteString(`</ac:structured-macro>`)
+    return b.String()
+}
+
+// imageMacro builds an image reference, treating bare file names as page attachments
+func imageMacro(alt, src string) string {
+    attrs := ""
+    if alt != "" {
+        attrs = fmt.Sprintf(` ac:alt="%s"`, html.EscapeString(alt))
+    }
+    if strings.Contains(src, "://") {
+        return fmt.Sprintf(`<ac:image%s><ri:url ri:value="%s" /></ac:image>`, attrs, html.EscapeString(src))
+    }
+    return fmt.Sprintf(`<ac:image%s><ri:attachment ri:filename="%s" /></ac:image>`, attrs, html.EscapeString(src))
+}
+
+// macroParameter builds a single macro parameter element
+func macroParameter(name, value string) string {
+    return fmt.Sprintf(`<ac:parameter ac:name="%s">%s</ac:parameter>`, html.EscapeString(name), html.EscapeString(value))
+}
+
+// preserveMacro wraps raw storage format in an HTML comment so it survives Markdown.
+// Markup that would end the comment early or span several lines, which the Markdown
+// converter splits into paragraphs, is base64-encoded
+func preserveMacro(raw string) string {
+    if strings.Contains(raw, "--") || strings.ContainsAny(raw, "\r\n") {
+        return "<!-- " + preservedEncodedPrefix + base64.StdEncoding.EncodeToString([]byte(raw)) + " -->"
+    }
+    return "<!-- " + preservedPrefix + raw + " -->"
+}
+
+// restorePreserved extracts the raw storage format from a preserved macro comment
+func restorePreserved(comment string) string {
+    m := preservedPattern.FindStringSubmatch(comment)
+    if m[1] == "" {
+        return m[2]
+    }
+    raw, err := base64.StdEncoding.DecodeString(m[2])
+    if err != nil {
+        return ""
+    }
+    return string(raw)
+}
+
+// storageNode is an element or text node parsed from storage format
+type storageNode struct {
+    Name     string // "" for text nodes
+    Attrs    map[string]string
+    Text     string
+    Raw      string // original markup of the element
+    Children []*storageNode
+}
+
+// attr returns an attribute value by its prefixed name
+func (n *storageNode) attr(name string) string {
+    return n.Attrs[name]
+}
+
+// text returns the concatenated text of the node and its descendants
+func (n *storageNode) text() string {
+    if n.Name == "" {
+        return n.Text
+    }
+    var b strings.Builder
+    for _, c := range n.Children {
+        b.WriteString(c.text())
+    }
+    return b.String()
+}
+
+// macroParams collects the ac:parameter children of a macro
+func (n *storageNode) macroParams() map[string]string {
+    params := map[string]string{}
+    for _, c := range n.Children {
+        if c.Name == "ac:parameter" {
+            params[c.attr("ac:name")] = c.text()
+        }
+    }
+    return params
+}
+
+// child returns the first child element with the given name
+func (n *storageNode) child(name string) *storageNode {
+    for _, c := range n.Children {
+        if c.Name == name {
+            return c
+        }
+    }
+    return nil
+}
+
+// voidElements are the HTML elements that never have content or an end tag
+var voidElements = map[string]bool{}
+
+func init() {
+    for _, name := range xml.HTMLAutoClose {
+        voidElements[name] = true
+    }
+}
+
+// parseStorage parses storage format into a node tree. Storage format is an XHTML
+// fragment with undeclared ac: and ri: prefixes and HTML entities, so it is parsed
+// leniently inside a synthetic root element. Only unprefixed void elements such as
+// <br> may be left open; <ac:link> is not an HTML <link>
+func parseStorage(storage string) (*storageNode, error) {
+    const open, close = "<root>", "</root>"
+    input := open + storage + close
+
+    decoder := xml.NewDecoder(strings.NewReader(input))
+    decoder.Strict = false
+    decoder.Entity = xml.HTMLEntity
+
+    root := &storageNode{Name: "root", Attrs: map[string]string{}}
+    stack := []*storageNode{}
+    starts := []int64{}
+    current := (*storageNode)(nil)
+    void := "" // void element whose optional end tag may follow
+
+    for {
+        offset := decoder.InputOffset()
+        token, err := decoder.RawToken()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        closed := void
+        void = ""
+
+        switch t := token.(type) {
+        case xml.StartElement:
+            node := &storageNode{Name: qualifiedName(t.Name), Attrs: map[string]string{}}
+            for _, a := range t.Attr {
+                node.Attrs[qualifiedName(a.Name)] = a.Value
+            }
+            if current == nil {
+                current = root
+                starts = append(starts, offset)
+                continue
+            }
+            current.Children = append(current.Children, node)
+            if t.Name.Space == "" && voidElements[strings.ToLower(t.Name.Local)] {
+                node.Raw = input[offset:decoder.InputOffset()]
+                void = node.Name
+                continue
+            }
+            stack = append(stack, current)
+            current = node
+            starts = append(starts, offset)
+        case xml.EndElement:
+            name := qualifiedName(t.Name)
+            if current == nil || name == closed {
+                continue
+            }
+            // An end tag closes its element and any left open inside it; a stray end
+            // tag is ignored
+            open := name == current.Name
+            for _, n := range stack {
+                open = open || n.Name == name
+            }
+            if !open {
+                continue
+            }
+            for {
+                start := starts[len(starts)-1]
+                starts = starts[:len(starts)-1]
+                current.Raw = input[start:decoder.InputOffset()]
+                if len(stack) == 0 {
+                    return root, nil
+                }
+                done := current.Name == name
+                current = stack[len(stack)-1]
+                stack = stack[:len(stack)-1]
+                if done {
+                    break
+                }
+            }
+        case xml.CharData:
+            if current != nil {
+                current.Children = append(current.Children, &storageNode{Text: string(t)})
+            }
+        }
+    }
+
+    return root, nil
+}
+
+// qualifiedName rebuilds the prefixed name of an element or attribute
+func qualifiedName(name xml.Name) string {
+    if name.Space == "" {
+        return name.Local
+    }
+    return name.Space + ":" + name.Local
+}
+
+// storageToMarkdown converts Confluence storage format into Markdown. Content that
+// cannot be parsed is returned unchanged
+func storageToMarkdown(storage string) string {
+    root, err := parseStorage(storage)
+    if err != nil {
+        return storage
+    }
+    return strings.TrimSpace(convertStorageBlocks(root.Children)) + "\n"
+}
+
+// convertStorageBlocks converts block-level nodes, separating blocks by blank lines
+func convertStorageBlocks(nodes []*storageNode) string {
+    var blocks []string
+    var inline []*storageNode
+
+    flush := func() {
+        if text := markdown.EscapeLines(strings.TrimSpace(convertStorageInline(inline))); text != "" {
+            blocks = append(blocks, text)
+        }
+        inline = nil
+    }
+
+    for _, n := range nodes {
+        if block, ok := convertStorageBlock(n); ok {
+            flush()
+            if block != "" {
+                blocks = append(blocks, block)
+            }
+            continue
+        }
+        inline = append(inline, n)
+    }
+    flush()
+
+    return strings.Join(blocks, "\n\n")
+}
+
+// convertStorageBlock converts a single block-level node. It reports false for
+// inline nodes, which the caller groups into paragraphs
+func convertStorageBlock(n *storageNode) (string, bool) {
+    switch n.Name {
+    case "h1", "h2", "h3", "h4", "h5", "h6":
+        level := int(n.Name[1] - '0')
+        return strings.Repeat("#", level) + " " + strings.TrimSpace(convertStorageInline(n.Children)), true
+    case "p":
+        return markdown.EscapeLines(strings.TrimSpace(convertStorageInline(n.Children))), true
+    case "ul", "ol":
+        return convertStorageList(n, 0), true
+    case "blockquote":
+        return quoteLines(convertStorageBlocks(n.Children)), true
+    case "pre":
+        fence := markdown.Fence(n.text())
+        return fence + "\n" + n.text() + "\n" + fence, true
+    case "hr":
+        return "---", true
+    case "table":
+        return convertStorageTable(n), true
+    case "div", "section":
+        return convertStorageBlocks(n.Children), true
+    case "ac:structured-macro", "ac:macro":
+        return convertStorageMacro(n)
+    case "ac:layout", "ac:task-list", "ac:placeholder":
+        return preserveMacro(n.Raw), true
+    }
+    return "", false
+}
+
+// convertStorageMacro converts a block-level macro; inline macros such as status
+// lozenges report false so they stay inside their paragraph
+func convertStorageMacro(n *storageNode) (string, bool) {
+    name := n.attr("ac:name")
+    params := n.macroParams()
+
+    switch name {
+    case "code", "noformat":
+        body := ""
+        if b := n.child("ac:plain-text-body"); b != nil {
+            body = b.text()
+        }
+        fence := markdown.Fence(body)
+        return fence + params["language"] + "\n" + body + "\n" + fence, true
+    case "info", "note", "warning", "tip":
+        header := "[!" + strings.ToUpper(name) + "]"
+        if params["title"] != "" {
+            header += " " + params["title"]
+        }
+        body := ""
+        if b := n.child("ac:rich-text-body"); b != nil {
+            body = convertStorageBlocks(b.Children)
+        }
+        return quoteLines(header + "\n" + body), true
+    case "expand":
+        var b strings.Builder
+        b.WriteString("<details>\n")
+        if params["title"] != "" {
+            b.WriteString("<summary>" + html.EscapeString(params["title"]) + "</summary>\n")
+        }
+        if body := n.child("ac:rich-text-body"); body != nil {
+            b.WriteString("\n" + convertStorageBlocks(body.Children) + "\n\n")
+        }
+        b.WriteString("</details>")
+        return b.String(), true
+    case "toc":
+        var keys []string
+        for k := range params {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+        toc := "[TOC"
+        for _, k := range keys {
+            toc += " " + k + "=" + params[k]
+        }
+        return toc + "]", true
+    case "status":
+        return "", false
+    }
+    return preserveMacro(n.Raw), true
+}
+
+// convertStorageInline converts inline nodes into Markdown text
+func convertStorageInline(nodes []*storageNode) string {
+    var b strings.Builder
+    for _, n := range nodes {
+        switch n.Name {
+        case "":
+            b.WriteString(escapeText(whitespacePattern.ReplaceAllString(n.Text, " ")))
+        case "strong", "b":
+            b.WriteString(wrapInline("**", convertStorageInline(n.Children)))
+        case "em", "i":
+            b.WriteString(wrapInline("*", convertStorageInline(n.Children)))
+        case "code":
+            b.WriteString(markdown.InlineCode(n.text()))
+        case "a":
+            b.WriteString("[" + convertStorageInline(n.Children) + "](" + markdown.Destination(n.attr("href")) + ")")
+        case "br":
+            b.WriteString("\n")
+        case "ac:image":
+            src := ""
+            if u := n.child("ri:url"); u != nil {
+                src = u.attr("ri:value")
+            } else if a := n.child("ri:attachment"); a != nil {
+                src = a.attr("ri:filename")
+            }
+            b.WriteString("![" + markdown.Escape(n.attr("ac:alt")) + "](" + markdown.Destination(src) + ")")
+        case "ac:structured-macro", "ac:macro":
+            if n.attr("ac:name") != "status" {
+                b.WriteString(preserveMacro(n.Raw))
+                continue
+            }
+            params := n.macroParams()
+            b.WriteString(":status[" + params["title"] + "]")
+            var extra []string
+            for _, k := range []string{"colour", "subtle"} {
+                if params[k] != "" {
+                    extra = append(extra, k+"="+params[k])
+                }
+            }
+            if len(extra) > 0 {
+                b.WriteString("{" + strings.Join(extra, " ") + "}")
+            }
+        default:
+            if strings.HasPrefix(n.Name, "ac:") || strings.HasPrefix(n.Name, "ri:") {
+                b.WriteString(preserveMacro(n.Raw))
+                continue
+            }
+            b.WriteString(convertStorageInline(n.Children))
+        }
+    }
+    return b.String()
+}
+
+// escapeText escapes plain text for Markdown, including text that looks like a
+// preserved macro comment
+func escapeText(text string) string {
+    return strings.ReplaceAll(markdown.Escape(text), "<!--", "\\<!--")
+}
+
+// wrapInline puts emphasis markers around inline Markdown. Leading and trailing
+// spaces stay outside the markers, where Markdown still reads them as emphasis
+func wrapInline(marker, text string) string {
+    inner := strings.TrimSpace(text)
+    if inner == "" {
+        return text
+    }
+    lead := len(text) - len(strings.TrimLeft(text, " \t\n"))
+    return text[:lead] + marker + inner + marker + text[lead+len(inner):]
+}
+
+// convertStorageList converts a list element, indenting nested lists
+func convertStorageList(n *storageNode, depth int) string {
+    var lines []string
+    index := 1
+    for _, item := range n.Children {
+        if item.Name != "li" {
+            continue
+        }
+        marker := "-"
+        if n.Name == "ol" {
+            marker = fmt.Sprintf("%d.", index)
+            index++
+        }
+
+        var inline []*storageNode
+        var nested []string
+        for _, c := range item.Children {
+            if c.Name == "ul" || c.Name == "ol" {
+                nested = append(nested, convertStorageList(c, depth+1))
+            } else if c.Name == "p" {
+                inline = append(inline, c.Children...)
+            } else {
+                inline = append(inline, c)
+            }
+        }
+
+        lines = append(lines, strings.Repeat("  ", depth)+marker+" "+strings.TrimSpace(convertStorageInline(inline)))
+        lines = append(lines, nested...)
+    }
+    return strings.Join(lines, "\n")
+}
+
+// convertStorageTable converts a table into a Markdown pipe table
+func convertStorageTable(n *storageNode) string {
+    var rows [][]string
+    var collect func(node *storageNode)
+    collect = func(node *storageNode) {
+        for _, c := range node.Children {
+            switch c.Name {
+            case "tbody", "thead", "tfoot":
+                collect(c)
+            case "tr":
+                var row []string
+                for _, cell := range c.Children {
+                    if cell.Name == "td" || cell.Name == "th" {
+                        row = append(row, strings.TrimSpace(convertStorageInline(flattenParagraphs(cell.Children))))
+                    }
+                }
+                rows = append(rows, row)
+            }
+        }
+    }
+    collect(n)
+    if len(rows) == 0 {
+        return ""
+    }
+
+    return markdown.Table(rows)
+}
+
+// flattenParagraphs replaces paragraph elements with their children so table cells stay on one line
+func flattenParagraphs(nodes []*storageNode) []*storageNode {
+    var flat []*storageNode
+    for _, n := range nodes {
+        if n.Name == "p" {
+            flat = append(flat, n.Children...)
+        } else {
+            flat = append(flat, n)
+        }
+    }
+    return flat
+}
+
+// quoteLines prefixes every line with a Markdown blockquote marker
+func quoteLines(text string) string {
+    lines := strings.Split(text, "\n")
+    for i, line := range lines {
+        if line == "" {
+            lines[i] = ">"
+        } else {
+            lines[i] = "> " + line
+        }
+    }
+    return strings.Join(lines, "\n")
+}
@@ -0,0 +1,138 @@
+package confluence
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestMarkdownToStorage(t *testing.T) {
+    tests := []struct {
+        name     string
+        markdown string
+        want     string
+    }{
+        {
+            name:     "inline markup",
+            markdown: "Run **setup** with `--force` and *see* [docs](https://example.com)",
+            want:     `<p>Run <strong>setup</strong> with <code>--force</code> and <em>see</em> <a href="https://example.com">docs</a></p>`,
+        },
+        {
+            name:     "escaped asterisks",
+            markdown: `5\*3\*2 < 40`,
+            want:     "<p>5*3*2 &lt; 40</p>",
+        },
+        {
+            name:     "nested lists",
+            markdown: "- one\n  1. first\n- two",
+            want:     "<ul><li>one<ol><li>first</li></ol></li><li>two</li></ul>",
+        },
+        {
+            name:     "table with escaped pipe",
+            markdown: "| a | b \\| c |\n| --- | --- |\n| 1 | 2 |",
+            want:     "<table><tbody><tr><th>a</th><th>b | c</th></tr><tr><td>1</td><td>2</td></tr></tbody></table>",
+        },
+        {
+            name:     "panel",
+            markdown: "> [!WARNING] Careful\n> Back up first",
+            want:     `<ac:structured-macro ac:name="warning"><ac:parameter ac:name="title">Careful</ac:parameter><ac:rich-text-body><p>Back up first</p></ac:rich-text-body></ac:structured-macro>`,
+        },
+        {
+            name:     "table of contents",
+            markdown: "[TOC maxLevel=2]",
+            want:     `<ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">2</ac:parameter></ac:structured-macro>`,
+        },
+        {
+            name:     "status lozenge",
+            markdown: "State :status[Done]{colour=Green}",
+            want:     `<p>State <ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Green</ac:parameter><ac:parameter ac:name="title">Done</ac:parameter></ac:structured-macro></p>`,
+        },
+        {
+            name:     "code block",
+            markdown: "```go\nfmt.Println(\"]]>\")\n```",
+            want:     `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[fmt.Println("]]]]><![CDATA[>")]]></ac:plain-text-body></ac:structured-macro>`,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := markdownToStorage(tt.markdown); got != tt.want {
+                t.Errorf("markdownToStorage(%q) =\n%s\nwant\n%s", tt.markdown, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+    tests := []struct {
+        name    string
+        storage string
+    }{
+        {name: "literal markup characters", storage: "<p>5*3*2, snake_case, C:\\temp and [not a link](x)</p>"},
+        {name: "headings and line breaks", storage: "<h2>Setup <em>now</em></h2><p>first<br />second</p>"},
+        {name: "nested lists", storage: "<ul><li>a<ul><li>b</li></ul></li><li>c</li></ul><ol><li>one</li><li>two</li></ol>"},
+        {name: "table with a pipe", storage: "<table><tbody><tr><th>a|b</th><th>c</th></tr><tr><td>1</td><td><strong>2</strong></td></tr></tbody></table>"},
+        {name: "panel", storage: `<ac:structured-macro ac:name="info"><ac:parameter ac:name="title">Note</ac:parameter><ac:rich-text-body><p>hi <em>there</em></p></ac:rich-text-body></ac:structured-macro>`},
+        {name: "expand", storage: `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">More</ac:parameter><ac:rich-text-body><p>inside</p></ac:rich-text-body></ac:structured-macro>`},
+        {name: "status", storage: `<p>State <ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Green</ac:parameter><ac:parameter ac:name="title">Done</ac:parameter></ac:structured-macro> ok</p>`},
+        {name: "image", storage: `<p><ac:image ac:alt="diagram"><ri:attachment ri:filename="flow.png" /></ac:image></p>`},
+        {name: "unknown macro", storage: `<ac:structured-macro ac:name="jira"><ac:parameter ac:name="key">ABC-1</ac:parameter></ac:structured-macro>`},
+        {name: "multi-line macro", storage: "<ac:structured-macro ac:name=\"html\"><ac:plain-text-body><![CDATA[<div>\n<b>x</b>\n</div>]]></ac:plain-text-body></ac:structured-macro>"},
+        {name: "macro containing a comment end", storage: `<ac:structured-macro ac:name="x"><ac:parameter ac:name="a">--></ac:parameter></ac:structured-macro>`},
+        {name: "page link", storage: `<p>A <ac:link><ri:page ri:content-title="Other" /></ac:link> B</p>`},
+        {name: "user mention", storage: `<p>Hi <ac:link><ri:user ri:account-id="5b10ac8d" /></ac:link> there</p>`},
+        {name: "link target with parentheses", storage: `<p><a href="https://example.com/a_(b)">x</a></p>`},
+        {name: "code with backticks", storage: "<p><code>a`b</code> and <code>`x</code></p>"},
+        {name: "code macro with a fence", storage: "<ac:structured-macro ac:name=\"code\"><ac:plain-text-body><![CDATA[```\nx\n```]]></ac:plain-text-body></ac:structured-macro>"},
+        {name: "ordered list marker", storage: "<p>1. not a list</p>"},
+        {name: "bullet marker", storage: "<p>- not a list</p>"},
+        {name: "heading marker", storage: "<p># not a heading</p>"},
+        {name: "quote marker", storage: "<p>&gt; not a quote</p>"},
+        {name: "rule", storage: "<p>---</p>"},
+        {name: "marker after a line break", storage: "<p>first<br /># second</p>"},
+        {name: "literal macro comment", storage: "<p>&lt;!-- confluence:macro &lt;b&gt;x&lt;/b&gt; --&gt;</p>"},
+        {name: "expand title with markup", storage: `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">a &lt;/summary&gt; &amp; b</ac:parameter><ac:rich-text-body><p>inside</p></ac:rich-text-body></ac:structured-macro>`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            markdown := storageToMarkdown(tt.storage)
+            if got := markdownToStorage(markdown); got != tt.storage {
+                t.Errorf("round trip of %s\nvia %q\ngave %s", tt.storage, markdown, got)
+            }
+        })
+    }
+}
+
+func TestStorageToMarkdownEscapes(t *testing.T) {
+    markdown := storageToMarkdown("<p>5*3*2</p>")
+    if want := "5\\*3\\*2\n"; markdown != want {
+        t.Errorf("storageToMarkdown() = %q, want %q", markdown, want)
+    }
+    if strings.Contains(markdownToStorage(markdown), "<em>") {
+        t.Errorf("escaped asterisks were read as emphasis")
+    }
+}
+
+func TestStorageToMarkdown(t *testing.T) {
+    tests := []struct {
+        name    string
+        storage string
+        want    string
+    }{
+        {name: "spaces move outside emphasis", storage: "<p><strong>bold </strong>text and<em> it</em></p>", want: "**bold** text and *it*\n"},
+        {name: "unclosed line break", storage: "<p>a<br>b</p>", want: "a\nb\n"},
+        {name: "link target in angle brackets", storage: `<p><a href="a b">x</a></p>`, want: "[x](<a b>)\n"},
+        {name: "longer code span", storage: "<p><code>a`b</code></p>", want: "``a`b``\n"},
+        {name: "longer fence", storage: "<pre>```\nx\n```</pre>", want: "````\n```\nx\n```\n````\n"},
+        {name: "ordered list marker", storage: "<p>1. x</p>", want: "1\\. x\n"},
+        {name: "page link", storage: `<p>A <ac:link><ri:page ri:content-title="Other" /></ac:link> B</p>`, want: `A <!-- confluence:macro <ac:link><ri:page ri:content-title="Other" /></ac:link> --> B` + "\n"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := storageToMarkdown(tt.storage); got != tt.want {
+                t.Errorf("storageToMarkdown(%s) = %q, want %q", tt.storage, got, tt.want)
+            }
+        })
+    }
+}
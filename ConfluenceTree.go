@@ -0,0 +1,174 @@
+package confluence
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/url"
+)
+
+// positionProperty is the content property holding the sibling position of a synced
+// page, so later runs can order new and moved pages relative to the others
+const positionProperty = "sync-position"
+
+// sibling is a child page as listed under its parent, in the parent's current order
+type sibling struct {
+    id       string
+    position int // Synced position, 0 for pages without one
+}
+
+// applyPosition records the position of a page and moves it among its siblings so
+// synced pages appear in position order. Pages without a position, and pages at the
+// root of a space, keep the order Confluence gives them
+func (s *ConfluenceServiceImpl) applyPosition(ctx context.Context, id, parentID string, position int) error {
+    if position == 0 {
+        return nil
+    }
+    if err := s.setProperty(ctx, id, positionProperty, position); err != nil {
+        return err
+    }
+    if parentID == "" {
+        return nil
+    }
+
+    siblings, err := s.listChildren(ctx, parentID)
+    if err != nil {
+        return err
+    }
+
+    // Place the page before the first sibling with a higher position, or after the
+    // last one with a lower or equal position
+    index := -1
+    var before, after string
+    var beforeIndex, afterIndex int
+    for i, child := range siblings {
+        switch {
+        case child.id == id:
+            index = i
+        case child.position == 0:
+            // Pages placed by hand are not used as anchors
+        case child.position > position && before == "":
+            before, beforeIndex = child.id, i
+        case child.position <= position:
+            after, afterIndex = child.id, i
+        }
+    }
+
+    switch {
+    case before != "" && (index == -1 || index+1 != beforeIndex):
+        return s.movePage(ctx, id, "before", before)
+    case before == "" && after != "" && (index == -1 || index-1 != afterIndex):
+        return s.movePage(ctx, id, "after", after)
+    }
+    return nil
+}
+
+// movePage moves a page relative to another page with the content move API, which
+// both API flavors share
+func (s *ConfluenceServiceImpl) movePage(ctx context.Context, id, position, targetID string) error {
+    url := fmt.Sprintf("%s/content/%s/move/%s/%s", s.restAPI(), id, position, targetID)
+    return s.doRequest(ctx, "move page", "PUT", url, nil, nil)
+}
+
+// listChildren returns the child pages of a page in their current order, with the
+// synced position of each, following the listing's pagination links
+func (s *ConfluenceServiceImpl) listChildren(ctx context.Context, parentID string) ([]sibling, error) {
+    var children []sibling
+    endpoint := fmt.Sprintf("%s/content/%s/child/page?expand=metadata.properties.%s&limit=200", s.restAPI(), parentID, positionProperty)
+
+    for endpoint != "" {
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "list child pages", "GET", endpoint, nil, &result); err != nil {
+            return nil, err
+        }
+
+        items, _ := result["results"].([]interface{})
+        for _, item := range items {
+            child, _ := item.(map[string]interface{})
+            id, _ := child["id"].(string)
+            metadata, _ := child["metadata"].(map[string]interface{})
+            properties, _ := metadata["properties"].(map[string]interface{})
+            property, _ := properties[positionProperty].(map[string]interface{})
+            position, _ := property["value"].(float64)
+            children = append(children, sibling{id: id, position: int(position)})
+        }
+
+        endpoint = s.nextV1URL(result)
+    }
+
+    return children, nil
+}
+
+// getProperty returns the value of a content property of a page
+func (s *ConfluenceServiceImpl) getProperty(ctx context.Context, id, key string) (interface{}, error) {
+    var current map[string]interface{}
+    url := fmt.Sprintf("%s/content/%s/property/%s", s.restAPI(), id, key)
+    if err := s.doRequest(ctx, "get property", "GET", url, nil, &current); err != nil {
+        return nil, err
+    }
+    return current["value"], nil
+}
+
+// setProperty creates or updates a content property of a page, leaving it alone when
+// it already holds the value
+func (s *ConfluenceServiceImpl) setProperty(ctx context.Context, id, key string, value interface{}) error {
+    url := fmt.Sprintf("%s/content/%s/property/%s", s.restAPI(), id, key)
+
+    var current map[string]interface{}
+    if err := s.doRequest(ctx, "get property", "GET", url, nil, &current); err != nil {
+        // Pages get the property the first time it is set
+        create := map[string]interface{}{"key": key, "value": value}
+        return s.doRequest(ctx, "set property", "POST", fmt.Sprintf("%s/content/%s/property", s.restAPI(), id), create, nil)
+    }
+
+    have, _ := json.Marshal(current["value"])
+    want, _ := json.Marshal(value)
+    if bytes.Equal(have, want) {
+        return nil
+    }
+    version, _ := current["version"].(map[string]interface{})
+    number, _ := version["number"].(float64)
+    update := map[string]interface{}{
+        "key":     key,
+        "value":   value,
+        "version": map[string]int{"number": int(number) + 1},
+    }
+    return s.doRequest(ctx, "set property", "PUT", url, update, nil)
+}
+
+// RootPages returns the configured parent pages and the home pages of the configured
+// spaces, which synced pages live under without being synced themselves
+func (s *ConfluenceServiceImpl) RootPages(ctx context.Context) (map[string]bool, error) {
+    roots := map[string]bool{}
+    spaces := map[string]bool{}
+    add := func(placement ConfluencePageConfig) {
+        if placement.ParentID != "" {
+            roots[placement.ParentID] = true
+        }
+        if placement.SpaceKey != "" {
+            spaces[placement.SpaceKey] = true
+        }
+    }
+    add(ConfluencePageConfig{SpaceKey: s.config.SpaceKey, ParentID: s.config.ParentID})
+    for _, override := range s.config.Pages {
+        add(override)
+    }
+
+    for key := range spaces {
+        var space struct {
+            Homepage struct {
+                ID string `json:"id"`
+            } `json:"homepage"`
+        }
+        endpoint := fmt.Sprintf("%s/space/%s?expand=homepage", s.restAPI(), url.PathEscape(key))
+        if err := s.doRequest(ctx, "get space "+key, "GET", endpoint, nil, &space); err != nil {
+            return nil, err
+        }
+        if space.Homepage.ID != "" {
+            roots[space.Homepage.ID] = true
+        }
+    }
+    return roots, nil
+}
+
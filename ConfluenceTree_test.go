@@ -0,0 +1,145 @@
+package confluence
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// fakeTree serves the children of page 10, two per listing page, and the position
+// property of each page. Writes are recorded as "set <id> <position>" and
+// "move <id> <before|after> <target>"
+type fakeTree struct {
+    children  []string       // Child page IDs of page 10 in their current order
+    positions map[string]int // Position properties, missing for pages without one
+    writes    []string
+}
+
+func (f *fakeTree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/wiki/rest/api/content/"), "/")
+    switch {
+    case len(parts) == 3 && parts[1] == "child":
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        result := map[string]interface{}{}
+        var results []interface{}
+        for i := start; i < len(f.children) && i < start+2; i++ {
+            properties := map[string]interface{}{}
+            if position, ok := f.positions[f.children[i]]; ok {
+                properties[positionProperty] = map[string]interface{}{"value": position}
+            }
+            results = append(results, map[string]interface{}{"id": f.children[i], "metadata": map[string]interface{}{"properties": properties}})
+        }
+        result["results"] = results
+        if start+2 < len(f.children) {
+            result["_links"] = map[string]string{"next": fmt.Sprintf("/rest/api/content/10/child/page?start=%d", start+2)}
+        }
+        json.NewEncoder(w).Encode(result)
+
+    case len(parts) == 3 && parts[1] == "property" && r.Method == "GET":
+        position, ok := f.positions[parts[0]]
+        if !ok {
+            http.NotFound(w, r)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"value": position, "version": map[string]int{"number": 1}})
+
+    case parts[1] == "property":
+        var body struct{ Value int }
+        json.NewDecoder(r.Body).Decode(&body)
+        f.positions[parts[0]] = body.Value
+        f.writes = append(f.writes, fmt.Sprintf("set %s %d", parts[0], body.Value))
+
+    case len(parts) == 4 && parts[1] == "move":
+        f.writes = append(f.writes, fmt.Sprintf("move %s %s %s", parts[0], parts[2], parts[3]))
+
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func TestApplyPosition(t *testing.T) {
+    tests := []struct {
+        name     string
+        children []string       // Children of page 10, "p" being the page placed
+        synced   map[string]int // Positions of the siblings, and of "p" when it has one
+        parentID string
+        position int
+        want     []string
+    }{
+        {
+            name:     "new page before the next higher sibling",
+            children: []string{"a", "manual", "c", "p"},
+            synced:   map[string]int{"a": 1, "c": 3},
+            parentID: "10",
+            position: 2,
+            want:     []string{"set p 2", "move p before c"},
+        },
+        {
+            name:     "last position after the highest sibling",
+            children: []string{"p", "a", "c", "manual"},
+            synced:   map[string]int{"a": 1, "c": 3},
+            parentID: "10",
+            position: 4,
+            want:     []string{"set p 4", "move p after c"},
+        },
+        {
+            name:     "already after the highest sibling",
+            children: []string{"a", "c", "p", "manual"},
+            synced:   map[string]int{"a": 1, "c": 3},
+            parentID: "10",
+            position: 4,
+            want:     []string{"set p 4"},
+        },
+        {
+            name:     "already in place with an unchanged position",
+            children: []string{"a", "p", "c"},
+            synced:   map[string]int{"a": 1, "p": 2, "c": 3},
+            parentID: "10",
+            position: 2,
+            want:     nil,
+        },
+        {
+            name:     "reordered page",
+            children: []string{"a", "p", "c"},
+            synced:   map[string]int{"a": 1, "p": 2, "c": 3},
+            parentID: "10",
+            position: 5,
+            want:     []string{"set p 5", "move p after c"},
+        },
+        {
+            name:     "root page keeps its place",
+            synced:   map[string]int{},
+            position: 2,
+            want:     []string{"set p 2"},
+        },
+        {
+            name:     "no position",
+            children: []string{"c", "p"},
+            synced:   map[string]int{"c": 1},
+            parentID: "10",
+            want:     nil,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeTree{children: tt.children, positions: tt.synced}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewConfluenceService(server.URL, "user", "token")
+            if err := s.applyPosition(context.Background(), "p", tt.parentID, tt.position); err != nil {
+                t.Fatalf("applyPosition() error = %v", err)
+            }
+            if !reflect.DeepEqual(fake.writes, tt.want) {
+                t.Errorf("writes = %q, want %q", fake.writes, tt.want)
+            }
+        })
+    }
+}
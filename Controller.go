@@ -2,9 +2,14 @@ package main
 
 import (
     "context"
+    "flag"
     "log"
+    "os"
+    "strings"
     "sync"
     "time"
+
+    "Support_Site_Sync/listing"
 )
 
 // Page represents a document or page managed by the services
@@ -13,27 +18,125 @@ type Page struct {
     Title     string
     Content   string
     Timestamp time.Time // Added to keep track of the last updated time
+    Labels    []string  // Tags or labels applied to the page on platforms that support them
+    Section   []string  // Category and section path the page belongs to, outermost first
+    ParentID  string    // ID of the parent page in the content tree, empty for a top-level page
+    Position  int       // Order among sibling pages, lowest first; 0 leaves the platform's order alone
+    Status    string    // Publication state reported by the platform, e.g. "draft", "review" or "published"
+    Version   string    // Version label reported by platforms that version articles
+
+    MetaTitle       string // Search engine title, empty to use Title
+    MetaDescription string // Search engine description
+    ChangeSummary   string // Short description of what changed, recorded where the platform keeps change notes
+
+    Locale       string          // Locale of Title and Content, e.g. "en-us"; empty uses the service default
+    Translations map[string]Page // Locale variants of the page keyed by locale
+
+    ExternalKeys map[string]string // IDs the page is known to have on services, keyed by service name, used to adopt existing pages
+
+    SourceID string // ID of the canonical page when ID holds the page's ID on a service, as on update
 }
 
-// ServiceInterface defines the methods that all services must implement
+// CanonicalID returns the ID of the canonical page, which per-page service config is keyed on
+func (p Page) CanonicalID() string {
+    if p.SourceID != "" {
+        return p.SourceID
+    }
+    return p.ID
+}
+
+// ServiceInterface defines the methods that all services must implement. ListPages
+// enumerates the pages on the service lazily, one platform page of results at a time
 type ServiceInterface interface {
     CreatePage(ctx context.Context, page Page) (string, error)
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
+}
+
+// TargetReporter is implemented by services that write each page to several targets,
+// such as the brands of a multi-brand Zendesk account, so the sync report can show
+// results per target. Results are keyed by operation and then target name
+type TargetReporter interface {
+    TargetResults() map[string]map[string]error
+}
+
+// RunParticipant is implemented by services that group the changes of a sync run,
+// for example into one commit or one pull request. BeginRun is called before the
+// first change of a run and EndRun after the last
+type RunParticipant interface {
+    BeginRun(ctx context.Context, runID string) error
+    EndRun(ctx context.Context) error
+}
+
+// CompositeIDs is implemented by services whose page IDs combine the IDs of a page on
+// several targets, such as the brands of a multi-brand Zendesk account, while their
+// listings report one ID per target. SplitID returns those per-target IDs keyed by
+// target name and JoinIDs combines them again
+type CompositeIDs interface {
+    SplitID(id string) map[string]string
+    JoinIDs(ids ...string) string
+}
+
+// IDUpdater is implemented by services where an update can give a page a new ID, such
+// as ServiceNow publishing each update as a new article version or a multi-brand
+// Zendesk account creating the article in a brand that lacked it. CurrentID returns
+// the page's ID after the update
+type IDUpdater interface {
+    CurrentID(ctx context.Context, id string) (string, error)
+}
+
+// RootPages is implemented by services with pages the sync depends on without syncing
+// them, such as configured parent pages or a site's home page. They are never
+// reported or pruned as orphans
+type RootPages interface {
+    RootPages(ctx context.Context) (map[string]bool, error)
 }
 
-func syncPages(ctx context.Context, services []ServiceInterface, page Page) {
+// LabelReader is implemented by services whose GetPage reports the labels of a page,
+// which pruning needs to honour protected labels
+type LabelReader interface {
+    ReadsLabels() bool
+}
+
+// Archiver is implemented by services that can archive a page so that it can be
+// restored later, which pruning prefers over deleting it
+type Archiver interface {
+    ArchivePage(ctx context.Context, id string) error
+}
+
+func syncPages(ctx context.Context, services []ServiceInterface, page Page, mapping *IDMapping) {
     var wg sync.WaitGroup
     errors := make(map[string]error)
     results := make(map[string]Page)
 
+    // Start Run
+    runID := time.Now().UTC().Format("20060102T150405Z")
+    for _, svc := range services {
+        if participant, ok := svc.(RunParticipant); ok {
+            if err := participant.BeginRun(ctx, runID); err != nil {
+                log.Printf("Error starting sync run in service: %v", err)
+                errors[svcName(svc)] = err
+            }
+        }
+    }
+
     // Create Page
     for _, svc := range services {
         wg.Add(1)
         go func(svc ServiceInterface) {
             defer wg.Done()
-            _, err := svc.CreatePage(ctx, page)
+            id, err := svc.CreatePage(ctx, withRemoteParent(page, svcName(svc), mapping))
+            // A page can be created even when a later step such as labelling fails, and
+            // must be recorded so the next run does not create it again
+            if id != "" {
+                entry := MappingEntry{RemoteID: id}
+                if target, ok := svc.(PathTarget); ok {
+                    entry.Path, _ = target.PagePath(page.ID)
+                }
+                mapping.Set(page.ID, svcName(svc), entry)
+            }
             if err != nil {
                 log.Printf("Error creating page in service: %v", err)
                 errors[svcName(svc)] = err
@@ -47,10 +150,34 @@ func syncPages(ctx context.Context, services []ServiceInterface, page Page) {
         wg.Add(1)
         go func(svc ServiceInterface) {
             defer wg.Done()
-            err := svc.UpdatePage(ctx, page)
+            remote := withRemoteParent(page, svcName(svc), mapping)
+            remote.ID, remote.SourceID = mapping.RemoteID(page.ID, svcName(svc)), page.ID
+            err := svc.UpdatePage(ctx, remote)
+            // Publishing a new version or adding a brand gives the page a new ID, which
+            // is kept even when another part of the update failed
+            if updater, ok := svc.(IDUpdater); ok {
+                if id, err := updater.CurrentID(ctx, remote.ID); err != nil {
+                    log.Printf("Error resolving current page ID in service: %v", err)
+                } else if id != remote.ID {
+                    mapping.Set(page.ID, svcName(svc), MappingEntry{RemoteID: id})
+                }
+            }
             if err != nil {
                 log.Printf("Error updating page in service: %v", err)
                 errors[svcName(svc)] = err
+                return
+            }
+            // Moving a page on a path-addressed target changes its path, and with it its ID
+            // when the target addresses pages by path
+            if target, ok := svc.(PathTarget); ok {
+                entry, _ := mapping.Get(page.ID, svcName(svc))
+                if path, ok := target.PagePath(page.ID); ok && path != entry.Path {
+                    if entry.RemoteID == entry.Path || entry.RemoteID == "" {
+                        entry.RemoteID = path
+                    }
+                    entry.Path = path
+                    mapping.Set(page.ID, svcName(svc), entry)
+                }
             }
         }(svc)
     }
@@ -61,21 +188,33 @@ func syncPages(ctx context.Context, services []ServiceInterface, page Page) {
         wg.Add(1)
         go func(svc ServiceInterface) {
             defer wg.Done()
-            err := svc.DeletePage(ctx, page.ID)
+            err := svc.DeletePage(ctx, mapping.RemoteID(page.ID, svcName(svc)))
             if err != nil {
                 log.Printf("Error deleting page in service: %v", err)
                 errors[svcName(svc)] = err
+                return
             }
+            mapping.Delete(page.ID, svcName(svc))
         }(svc)
     }
     wg.Wait()
 
+    // Finish Run
+    for _, svc := range services {
+        if participant, ok := svc.(RunParticipant); ok {
+            if err := participant.EndRun(ctx); err != nil {
+                log.Printf("Error finishing sync run in service: %v", err)
+                errors[svcName(svc)] = err
+            }
+        }
+    }
+
     // Get Page and Compare Versions
     for _, svc := range services {
         wg.Add(1)
         go func(svc ServiceInterface) {
             defer wg.Done()
-            retrievedPage, err := svc.GetPage(ctx, page.ID)
+            retrievedPage, err := svc.GetPage(ctx, mapping.RemoteID(page.ID, svcName(svc)))
             if err != nil {
                 log.Printf("Error getting page from service: %v", err)
                 errors[svcName(svc)] = err
@@ -93,6 +232,9 @@ func syncPages(ctx context.Context, services []ServiceInterface, page Page) {
             latestPage = p
         }
         log.Printf("Service %s returned page: %+v", svc, p)
+        if p.Status == "unverified" {
+            log.Printf("Service %s reports page %s as unverified", svc, p.ID)
+        }
     }
 
     if latestPage.ID != "" {
@@ -107,6 +249,36 @@ func syncPages(ctx context.Context, services []ServiceInterface, page Page) {
             log.Printf("Service %s encountered an error: %v", svc, err)
         }
     }
+    for _, svc := range services {
+        reporter, ok := svc.(TargetReporter)
+        if !ok {
+            continue
+        }
+        for op, targets := range reporter.TargetResults() {
+            for target, err := range targets {
+                if err != nil {
+                    log.Printf("Service %s target %s failed to %s page: %v", svcName(svc), target, op, err)
+                } else {
+                    log.Printf("Service %s target %s completed %s", svcName(svc), target, op)
+                }
+            }
+        }
+    }
+}
+
+// withRemoteParent returns page with its parent given by the parent's ID on a service.
+// A parent that has not been synced to the service is left out, so the service places
+// the page by its own defaults until the parent exists
+func withRemoteParent(page Page, service string, mapping *IDMapping) Page {
+    if page.ParentID == "" {
+        return page
+    }
+    if entry, ok := mapping.Get(page.ParentID, service); ok {
+        page.ParentID = entry.RemoteID
+    } else {
+        page.ParentID = ""
+    }
+    return page
 }
 
 func svcName(svc ServiceInterface) string {
@@ -115,7 +287,7 @@ func svcName(svc ServiceInterface) string {
         return "Confluence"
     case *sharepoint.SharePointService:
         return "SharePoint"
-    case *zendesk.ZendeskService:
+    case *zendesk.ZendeskService, *zendesk.ZendeskMultiBrandService:
         return "Zendesk"
     case *freshdesk.FreshdeskService:
         return "Freshdesk"
@@ -170,6 +342,65 @@ func main() {
         Timestamp: time.Now(), // Set the current time as the initial timestamp
     }
 
+    mapping, err := LoadIDMapping("id-mapping.yaml")
+    if err != nil {
+        log.Fatalf("Error loading ID mapping: %v", err)
+    }
+    for _, svc := range services {
+        if target, ok := svc.(PathTarget); ok {
+            mapping.ReservePaths(svcName(svc), target)
+        }
+    }
+
     ctx := context.Background()
-    syncPages(ctx, services, page)
+
+    // "adopt" matches pages that already exist on the services instead of syncing
+    if len(os.Args) > 1 && os.Args[1] == "adopt" {
+        options := AdoptOptions{In: os.Stdin, Out: os.Stdout}
+        flags := flag.NewFlagSet("adopt", flag.ExitOnError)
+        flags.BoolVar(&options.Yes, "yes", false, "adopt every match without asking")
+        flags.Float64Var(&options.MinScore, "min-score", 0.8, "combined title and content similarity a fuzzy match needs")
+        flags.Float64Var(&options.MinTitleScore, "min-title-score", 0.6, "title similarity needed before content is compared")
+        flags.Parse(os.Args[2:])
+
+        if err := Adopt(ctx, services, []Page{page}, mapping, options); err != nil {
+            log.Fatalf("Error adopting pages: %v", err)
+        }
+        if err := mapping.Save(); err != nil {
+            log.Fatalf("Error saving ID mapping: %v", err)
+        }
+        return
+    }
+
+    // "orphans" reports pages known to only one side of the sync, and prunes them on request
+    if len(os.Args) > 1 && os.Args[1] == "orphans" {
+        options := PruneOptions{Out: os.Stdout}
+        var protected string
+        flags := flag.NewFlagSet("orphans", flag.ExitOnError)
+        flags.BoolVar(&options.Prune, "prune", false, "archive pages unknown to the sync and pages whose canonical page was removed")
+        flags.BoolVar(&options.Delete, "delete", false, "delete pruned pages instead of archiving them")
+        flags.IntVar(&options.MaxDeletions, "max-deletions", 10, "most pages pruned in one run")
+        flags.StringVar(&protected, "protected-labels", "", "comma-separated labels of pages that are never pruned")
+        flags.Parse(os.Args[2:])
+        if options.MaxDeletions < 1 {
+            log.Fatalf("-max-deletions must be at least 1")
+        }
+        if protected != "" {
+            options.ProtectedLabels = strings.Split(protected, ",")
+        }
+
+        if err := Orphans(ctx, services, []Page{page}, mapping, options); err != nil {
+            log.Fatalf("Error pruning orphaned pages: %v", err)
+        }
+        if err := mapping.Save(); err != nil {
+            log.Fatalf("Error saving ID mapping: %v", err)
+        }
+        return
+    }
+
+    syncPages(ctx, services, page, mapping)
+
+    if err := mapping.Save(); err != nil {
+        log.Printf("Error saving ID mapping: %v", err)
+    }
 }
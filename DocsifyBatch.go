@@ -0,0 +1,215 @@
+package docsify
+
+import (
+    "context"
+    "net/http"
+    "sort"
+)
+
+// defaultBatchMessageTemplate is used for batch commits when BatchMessageTemplate is empty
+const defaultBatchMessageTemplate = "Sync {{.Count}} pages{{if .RunID}} (sync run {{.RunID}}){{end}}"
+
+// maxRefUpdateAttempts is how often a batch commit is rebuilt when the branch moved
+// while it was being created
+const maxRefUpdateAttempts = 3
+
+// batchInfo is the data available to batch commit message templates
+type batchInfo struct {
+    Count int
+    Paths []string
+    RunID string
+}
+
+// batching reports whether page changes are staged for a single commit at the end of
+// the current run rather than committed one by one
+func (s *DocsifyServiceImpl) batching() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.config.Batch && s.runActive
+}
+
+// stage records a change to be committed at the end of the run; a nil page deletes
+// the file
+func (s *DocsifyServiceImpl) stage(path string, page *Page) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.pending == nil {
+        s.pending = make(map[string]*Page)
+    }
+    s.pending[path] = page
+}
+
+// staged returns the staged version of a page, and whether the page has a staged change
+func (s *DocsifyServiceImpl) staged(path string) (*Page, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    page, ok := s.pending[path]
+    return page, ok
+}
+
+// flushBatch commits all staged changes as one commit and updates the branch once
+func (s *DocsifyServiceImpl) flushBatch(ctx context.Context) error {
+    s.mu.Lock()
+    pending, runID := s.pending, s.runID
+    s.pending = nil
+    s.mu.Unlock()
+    if len(pending) == 0 {
+        return nil
+    }
+
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return err
+    }
+
+    paths := sortedPaths(pending)
+    message, err := renderTemplate(s.config.BatchMessageTemplate, defaultBatchMessageTemplate, batchInfo{Count: len(paths), Paths: paths, RunID: runID})
+    if err != nil {
+        return err
+    }
+
+    return s.commitChanges(ctx, branch, message, pending)
+}
+
+// writePage commits a single page change on its own; a nil page deletes the file
+func (s *DocsifyServiceImpl) writePage(ctx context.Context, action, title, id string, page *Page) error {
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return err
+    }
+    message, err := s.commitMessage(action, title, id)
+    if err != nil {
+        return err
+    }
+    return s.commitChanges(ctx, branch, message, map[string]*Page{id: page})
+}
+
+// movePage commits a page at its new path together with the removal of its old path,
+// so the move shows as a rename in one commit
+func (s *DocsifyServiceImpl) movePage(ctx context.Context, from string, page *Page) error {
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return err
+    }
+    message, err := s.commitMessage("Move", page.Title, page.ID)
+    if err != nil {
+        return err
+    }
+    return s.commitChanges(ctx, branch, message, map[string]*Page{from: nil, page.ID: page})
+}
+
+// commitChanges commits a set of page changes, together with the sidebar and navbar
+// changes they cause, as one commit on branch using the configured backend
+func (s *DocsifyServiceImpl) commitChanges(ctx context.Context, branch, message string, changes map[string]*Page) error {
+    if s.config.Backend == BackendLocal {
+        if branch != "" {
+            if _, err := s.git(ctx, "checkout", "-q", branch); err != nil {
+                return err
+            }
+        }
+        all, err := s.withNavigation(ctx, branch, changes)
+        if err != nil {
+            return err
+        }
+        return s.commitLocal(ctx, message, sortedPaths(all), all)
+    }
+
+    if branch == "" {
+        var err error
+        if branch, err = s.baseBranch(ctx); err != nil {
+            return err
+        }
+    }
+    for attempt := 1; ; attempt++ {
+        // The navigation is rebuilt on every attempt as the branch may have moved
+        all, err := s.withNavigation(ctx, branch, changes)
+        if err != nil {
+            return err
+        }
+        err = s.commitTree(ctx, branch, message, sortedPaths(all), all)
+        if err == nil || !isConflict(err) || attempt == maxRefUpdateAttempts {
+            return err
+        }
+    }
+}
+
+// sortedPaths returns the paths of a set of changes in sorted order
+func sortedPaths(changes map[string]*Page) []string {
+    paths := make([]string, 0, len(changes))
+    for path := range changes {
+        paths = append(paths, path)
+    }
+    sort.Strings(paths)
+    return paths
+}
+
+// commitTree creates a tree with the staged changes on top of the head of branch,
+// commits it and moves the branch to the new commit
+func (s *DocsifyServiceImpl) commitTree(ctx context.Context, branch, message string, paths []string, pending map[string]*Page) error {
+    var ref struct {
+        Object struct {
+            SHA string `json:"sha"`
+        } `json:"object"`
+    }
+    if err := s.doRequest(ctx, "get branch", "GET", s.repoURL("/git/ref/heads/%s", branch), nil, &ref); err != nil {
+        return err
+    }
+    head := ref.Object.SHA
+
+    var commit struct {
+        Tree struct {
+            SHA string `json:"sha"`
+        } `json:"tree"`
+    }
+    if err := s.doRequest(ctx, "get commit", "GET", s.repoURL("/git/commits/%s", head), nil, &commit); err != nil {
+        return err
+    }
+
+    entries := make([]map[string]interface{}, 0, len(paths))
+    for _, path := range paths {
+        entry := map[string]interface{}{"path": path, "mode": "100644", "type": "blob"}
+        if page := pending[path]; page != nil {
+            entry["content"] = page.Content
+        } else {
+            // A null SHA removes the file from the tree
+            entry["sha"] = nil
+        }
+        entries = append(entries, entry)
+    }
+
+    var tree struct {
+        SHA string `json:"sha"`
+    }
+    treeBody := map[string]interface{}{"base_tree": commit.Tree.SHA, "tree": entries}
+    if err := s.doRequest(ctx, "create tree", "POST", s.repoURL("/git/trees"), treeBody, &tree); err != nil {
+        return err
+    }
+
+    commitBody := map[string]interface{}{
+        "message": message,
+        "tree":    tree.SHA,
+        "parents": []string{head},
+    }
+    if author := s.config.author(); author != nil {
+        commitBody["author"] = author
+    }
+    if committer := s.config.committer(); committer != nil {
+        commitBody["committer"] = committer
+    }
+    var created struct {
+        SHA string `json:"sha"`
+    }
+    if err := s.doRequest(ctx, "create commit", "POST", s.repoURL("/git/commits"), commitBody, &created); err != nil {
+        return err
+    }
+
+    refBody := map[string]interface{}{"sha": created.SHA, "force": false}
+    return s.doRequest(ctx, "update branch", "PATCH", s.repoURL("/git/refs/heads/%s", branch), refBody, nil)
+}
+
+// isConflict reports whether a ref update failed because the branch is no longer at
+// the commit the new commit was built on
+func isConflict(err error) bool {
+    apiErr, ok := err.(*apiError)
+    return ok && apiErr.Action == "update branch" && apiErr.StatusCode == http.StatusUnprocessableEntity
+}
@@ -0,0 +1,143 @@
+package docsify
+
+import (
+    "context"
+    "reflect"
+    "testing"
+)
+
+// batchRun stages a create, an update and a delete in one batch run
+func batchRun(ctx context.Context, service *DocsifyServiceImpl) error {
+    if err := service.BeginRun(ctx, "r1"); err != nil {
+        return err
+    }
+    if _, err := service.CreatePage(ctx, Page{ID: "a.md", Title: "A", Content: "new"}); err != nil {
+        return err
+    }
+    if err := service.UpdatePage(ctx, Page{ID: "b.md", Title: "B", Content: "changed"}); err != nil {
+        return err
+    }
+    if err := service.DeletePage(ctx, "c.md"); err != nil {
+        return err
+    }
+    return service.EndRun(ctx)
+}
+
+func TestBatchCommit(t *testing.T) {
+    fake := newFakeGitHub(map[string]string{"b.md": "old", "c.md": "gone"})
+    config := DocsifyConfig{Batch: true, AuthorName: "Docs Bot", AuthorEmail: "bot@example.com"}
+    service, done := newFakeDocsify(fake, config)
+    defer done()
+
+    if err := batchRun(context.Background(), service); err != nil {
+        t.Fatalf("batch run error = %v", err)
+    }
+
+    want := []string{"POST /git/trees", "POST /git/commits", "PATCH /git/refs/heads/main"}
+    if !reflect.DeepEqual(fake.writes, want) {
+        t.Fatalf("writes = %q, want %q", fake.writes, want)
+    }
+
+    tree := fake.bodies[0]
+    if tree["base_tree"] != "tree-of-head-main" {
+        t.Errorf("base_tree = %v, want tree-of-head-main", tree["base_tree"])
+    }
+    entries := []interface{}{
+        map[string]interface{}{"path": "a.md", "mode": "100644", "type": "blob", "content": "new"},
+        map[string]interface{}{"path": "b.md", "mode": "100644", "type": "blob", "content": "changed"},
+        map[string]interface{}{"path": "c.md", "mode": "100644", "type": "blob", "sha": nil},
+    }
+    if !reflect.DeepEqual(tree["tree"], entries) {
+        t.Errorf("tree = %v, want %v", tree["tree"], entries)
+    }
+
+    commit := fake.bodies[1]
+    author := map[string]interface{}{"name": "Docs Bot", "email": "bot@example.com"}
+    if commit["message"] != "Sync 3 pages (sync run r1)" || commit["tree"] != "tree-1" {
+        t.Errorf("commit message, tree = %v, %v, want batch message on tree-1", commit["message"], commit["tree"])
+    }
+    if !reflect.DeepEqual(commit["parents"], []interface{}{"head-main"}) {
+        t.Errorf("parents = %v, want [head-main]", commit["parents"])
+    }
+    if !reflect.DeepEqual(commit["author"], author) || !reflect.DeepEqual(commit["committer"], author) {
+        t.Errorf("author, committer = %v, %v, want %v", commit["author"], commit["committer"], author)
+    }
+
+    if ref := fake.bodies[2]; ref["sha"] != "commit-1" || ref["force"] != false {
+        t.Errorf("ref update = %v, want commit-1 without force", ref)
+    }
+    wantFiles := map[string]string{"a.md": "new", "b.md": "changed"}
+    if !reflect.DeepEqual(fake.files["main"], wantFiles) {
+        t.Errorf("files = %v, want %v", fake.files["main"], wantFiles)
+    }
+}
+
+func TestBatchCommitStaleBranch(t *testing.T) {
+    tests := []struct {
+        name      string
+        staleRefs int
+        wantErr   bool
+        writes    int
+        parent    string
+    }{
+        {"retried on new head", 1, false, 6, "pushed-0"},
+        {"gives up", maxRefUpdateAttempts, true, 3 * maxRefUpdateAttempts, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGitHub(map[string]string{"b.md": "old", "c.md": "gone"})
+            fake.staleRefs = tt.staleRefs
+            service, done := newFakeDocsify(fake, DocsifyConfig{Batch: true, Branch: "main"})
+            defer done()
+
+            err := batchRun(context.Background(), service)
+            if tt.wantErr {
+                if err == nil || !isConflict(err) {
+                    t.Errorf("batch run error = %v, want a rejected branch update", err)
+                }
+            } else if err != nil {
+                t.Fatalf("batch run error = %v", err)
+            }
+            if len(fake.writes) != tt.writes {
+                t.Errorf("writes = %q, want %d", fake.writes, tt.writes)
+            }
+            if tt.wantErr {
+                return
+            }
+
+            // The retry is built on the commit pushed in between, keeping its changes
+            last := fake.bodies[len(fake.bodies)-2]
+            if !reflect.DeepEqual(last["parents"], []interface{}{tt.parent}) {
+                t.Errorf("parents = %v, want [%s]", last["parents"], tt.parent)
+            }
+            if base := fake.bodies[len(fake.bodies)-3]["base_tree"]; base != "tree-of-"+tt.parent {
+                t.Errorf("base_tree = %v, want tree-of-%s", base, tt.parent)
+            }
+            wantFiles := map[string]string{"a.md": "new", "b.md": "changed", "other.md": "Pushed by someone else"}
+            if !reflect.DeepEqual(fake.files["main"], wantFiles) {
+                t.Errorf("files = %v, want %v", fake.files["main"], wantFiles)
+            }
+        })
+    }
+}
+
+func TestBatchGetPageStaged(t *testing.T) {
+    fake := newFakeGitHub(map[string]string{"b.md": "old"})
+    service, done := newFakeDocsify(fake, DocsifyConfig{Batch: true})
+    defer done()
+
+    ctx := context.Background()
+    service.BeginRun(ctx, "r1")
+    service.UpdatePage(ctx, Page{ID: "b.md", Title: "B", Content: "staged"})
+    service.DeletePage(ctx, "gone.md")
+
+    if page, err := service.GetPage(ctx, "b.md"); err != nil || page.Content != "staged" {
+        t.Errorf("GetPage(b.md) = %q, %v, want staged content", page.Content, err)
+    }
+    if _, err := service.GetPage(ctx, "gone.md"); err == nil {
+        t.Error("GetPage(gone.md) error = nil, want staged for deletion")
+    }
+    if len(fake.writes) != 0 {
+        t.Errorf("writes before EndRun = %q, want none", fake.writes)
+    }
+}
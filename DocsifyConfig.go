@@ -0,0 +1,110 @@
+package docsify
+
+import (
+    "strings"
+
+    "Support_Site_Sync/slug"
+)
+
+// Backends supported by DocsifyConfig.Backend
+const (
+    BackendGitHub = "github" // Commit through the GitHub REST API
+    BackendLocal  = "local"  // Commit to a local git clone of any host and optionally push
+)
+
+// Default templates used when DocsifyConfig leaves them empty
+const (
+    defaultMessageTemplate = "{{.Action}} page {{.Title}}{{if .RunID}} (sync run {{.RunID}}){{end}}"
+    defaultPRTitleTemplate = "Sync support content{{if .RunID}} (run {{.RunID}}){{end}}"
+    defaultBranchPrefix    = "sync/"
+)
+
+// DocsifyConfig holds the branch, identity and review settings used when committing
+// pages to the Docsify repository
+type DocsifyConfig struct {
+    Backend   string `yaml:"backend"`    // BackendGitHub (default) or BackendLocal
+    APIURL    string `yaml:"api_url"`    // GitHub API URL, defaults to https://api.github.com; set for GitHub Enterprise
+    LocalPath string `yaml:"local_path"` // Working copy used by BackendLocal
+    Remote    string `yaml:"remote"`     // Remote BackendLocal pushes to, defaults to origin
+    Push      bool   `yaml:"push"`       // Push local commits to Remote
+
+    Branch          string `yaml:"branch"`      // Branch pages are committed to, empty for the repository default
+    AuthorName      string `yaml:"author_name"` // Commit author, empty for the token owner
+    AuthorEmail     string `yaml:"author_email"`
+    CommitterName   string `yaml:"committer_name"` // Commit committer, empty to use the author
+    CommitterEmail  string `yaml:"committer_email"`
+    MessageTemplate string `yaml:"message_template"` // text/template for commit messages with .Action, .Title, .Path and .RunID
+
+    Batch                bool   `yaml:"batch"`                  // Commit all changes of a run as one commit through the Git Data API
+    BatchMessageTemplate string `yaml:"batch_message_template"` // text/template for batch commits with .Count, .Paths and .RunID
+
+    Slugs bool        `yaml:"slugs"` // Store pages at paths generated from title and section instead of the page ID
+    Slug  slug.Config `yaml:"slug"`  // Path generation settings
+
+    Sidebar     bool   `yaml:"sidebar"`      // Maintain the sidebar from the section paths of synced pages
+    SidebarPath string `yaml:"sidebar_path"` // Sidebar file, defaults to _sidebar.md
+    Navbar      bool   `yaml:"navbar"`       // Maintain the navbar with a dropdown of pages per top-level section
+    NavbarPath  string `yaml:"navbar_path"`  // Navbar file, defaults to _navbar.md; set loadNavbar in the Docsify site config
+    HomePage    string `yaml:"home_page"`    // ID of the page written to README.md, the home page of the site
+
+    PullRequest     bool   `yaml:"pull_request"`      // Commit a run's changes to a feature branch and open one pull request
+    BranchPrefix    string `yaml:"branch_prefix"`     // Prefix of feature branch names, defaults to "sync/"
+    PRTitleTemplate string `yaml:"pr_title_template"` // text/template for the pull request title with .RunID
+    PRBody          string `yaml:"pr_body"`           // Pull request description
+}
+
+// author returns the commit author, or nil to let GitHub use the token owner
+func (c DocsifyConfig) author() map[string]string {
+    if c.AuthorName == "" || c.AuthorEmail == "" {
+        return nil
+    }
+    return map[string]string{"name": c.AuthorName, "email": c.AuthorEmail}
+}
+
+// committer returns the commit committer, or nil to use the author
+func (c DocsifyConfig) committer() map[string]string {
+    if c.CommitterName == "" || c.CommitterEmail == "" {
+        return c.author()
+    }
+    return map[string]string{"name": c.CommitterName, "email": c.CommitterEmail}
+}
+
+// branchPrefix returns the prefix of feature branches created in pull request mode
+func (c DocsifyConfig) branchPrefix() string {
+    if c.BranchPrefix == "" {
+        return defaultBranchPrefix
+    }
+    return c.BranchPrefix
+}
+
+// apiURL returns the base URL of the GitHub REST API
+func (c DocsifyConfig) apiURL() string {
+    if c.APIURL == "" {
+        return "https://api.github.com"
+    }
+    return strings.TrimSuffix(c.APIURL, "/")
+}
+
+// remote returns the remote local commits are pushed to
+func (c DocsifyConfig) remote() string {
+    if c.Remote == "" {
+        return "origin"
+    }
+    return c.Remote
+}
+
+// sidebarPath returns the path of the Docsify sidebar file
+func (c DocsifyConfig) sidebarPath() string {
+    if c.SidebarPath == "" {
+        return "_sidebar.md"
+    }
+    return c.SidebarPath
+}
+
+// navbarPath returns the path of the Docsify navbar file
+func (c DocsifyConfig) navbarPath() string {
+    if c.NavbarPath == "" {
+        return "_navbar.md"
+    }
+    return c.NavbarPath
+}
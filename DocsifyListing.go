@@ -0,0 +1,180 @@
+package docsify
+
+import (
+    "context"
+    "path"
+    "strings"
+
+    "Support_Site_Sync/listing"
+)
+
+// treeEntry is an entry of a GitHub tree listing
+type treeEntry struct {
+    Path string `json:"path"`
+    Type string `json:"type"`
+    SHA  string `json:"sha"`
+}
+
+// ListPages lists the Markdown pages of the repository. The GitHub backend walks the
+// tree one directory per request, so large repositories are listed lazily; the local
+// backend lists the working copy's branch at once. Pages take their titles from the
+// sidebar when it links to them and their file names otherwise, and report their
+// directory as their parent. Docsify's own files, such as _sidebar.md, are skipped
+func (s *DocsifyServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    var titles map[string]string
+
+    return listing.New(ctx, func(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+        if titles == nil {
+            var err error
+            if titles, err = s.sidebarTitles(ctx); err != nil {
+                return nil, "", err
+            }
+        }
+        if s.config.Backend == BackendLocal {
+            return s.listLocal(ctx, titles)
+        }
+        return s.listTree(ctx, cursor, titles)
+    })
+}
+
+// listTree lists one directory of the repository tree. The cursor is the queue of
+// directories still to list, one "sha path" pair per line
+func (s *DocsifyServiceImpl) listTree(ctx context.Context, cursor string, titles map[string]string) ([]listing.Summary, string, error) {
+    var queue []string
+    if cursor == "" {
+        branch := s.readBranch()
+        if branch == "" {
+            var err error
+            if branch, err = s.baseBranch(ctx); err != nil {
+                return nil, "", err
+            }
+        }
+        queue = []string{branch + " "}
+    } else {
+        queue = strings.Split(cursor, "\n")
+    }
+
+    sha, dir := queue[0], ""
+    if i := strings.Index(queue[0], " "); i >= 0 {
+        sha, dir = queue[0][:i], queue[0][i+1:]
+    }
+    queue = queue[1:]
+
+    var tree struct {
+        Tree []treeEntry `json:"tree"`
+    }
+    if err := s.doRequest(ctx, "list "+dir, "GET", s.repoURL("/git/trees/%s", sha), nil, &tree); err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    for _, entry := range tree.Tree {
+        file := path.Join(dir, entry.Path)
+        switch entry.Type {
+        case "tree":
+            queue = append(queue, entry.SHA+" "+file)
+        case "blob":
+            if s.isPageFile(file) {
+                summaries = append(summaries, pageSummary(file, titles))
+            }
+        }
+    }
+
+    return summaries, strings.Join(queue, "\n"), nil
+}
+
+// listLocal lists every page on the checked out branch of the working copy
+func (s *DocsifyServiceImpl) listLocal(ctx context.Context, titles map[string]string) ([]listing.Summary, string, error) {
+    out, err := s.git(ctx, "ls-tree", "-r", "--name-only", "HEAD")
+    if err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    for _, file := range strings.Split(out, "\n") {
+        if file != "" && s.isPageFile(file) {
+            summaries = append(summaries, pageSummary(file, titles))
+        }
+    }
+    return summaries, "", nil
+}
+
+// RootPages returns the README.md files, which Docsify serves as the home page of the
+// site and of each directory rather than as synced pages
+func (s *DocsifyServiceImpl) RootPages(ctx context.Context) (map[string]bool, error) {
+    summaries, err := s.ListPages(ctx).All()
+    if err != nil {
+        return nil, err
+    }
+    roots := map[string]bool{}
+    for _, summary := range summaries {
+        if path.Base(summary.ID) == "README.md" {
+            roots[summary.ID] = true
+        }
+    }
+    return roots, nil
+}
+
+// isPageFile reports whether a repository file is a page rather than an asset or one
+// of Docsify's own files, whose names start with an underscore
+func (s *DocsifyServiceImpl) isPageFile(file string) bool {
+    extension := s.config.Slug.Extension
+    if extension == "" {
+        extension = ".md"
+    }
+    return strings.HasSuffix(file, extension) && !strings.HasPrefix(path.Base(file), "_")
+}
+
+// sidebarTitles returns the titles the sidebar gives pages, keyed by path, or an empty
+// map when the sidebar is not maintained
+func (s *DocsifyServiceImpl) sidebarTitles(ctx context.Context) (map[string]string, error) {
+    titles := map[string]string{}
+    if !s.config.Sidebar {
+        return titles, nil
+    }
+    content, err := s.readFile(ctx, s.readBranch(), s.config.sidebarPath())
+    if err != nil {
+        return nil, err
+    }
+
+    var collect func(nodes []*sidebarNode)
+    collect = func(nodes []*sidebarNode) {
+        for _, node := range nodes {
+            if node.Link != "" {
+                titles[node.Link] = node.Title
+            }
+            collect(node.Children)
+        }
+    }
+    _, nodes := parseSidebar(content)
+    collect(nodes)
+    return titles, nil
+}
+
+// pageSummary describes a page file, titled the same way GetPage titles it
+func pageSummary(file string, titles map[string]string) listing.Summary {
+    parent := path.Dir(file)
+    if parent == "." {
+        parent = ""
+    }
+    return listing.Summary{ID: file, Title: fileTitle(file, titles), ParentID: parent}
+}
+
+// fileTitle returns the title of a page file: the sidebar's title when it links to
+// the file, otherwise the file name without its extension
+func fileTitle(file string, titles map[string]string) string {
+    if title, ok := titles[sidebarLink(file)]; ok {
+        return title
+    }
+    return strings.TrimSuffix(path.Base(file), path.Ext(file))
+}
+
+// pageTitle returns the title of a single page file. A sidebar that cannot be read
+// leaves the page titled by its file name
+func (s *DocsifyServiceImpl) pageTitle(ctx context.Context, file string) string {
+    titles, err := s.sidebarTitles(ctx)
+    if err != nil {
+        titles = nil
+    }
+    return fileTitle(file, titles)
+}
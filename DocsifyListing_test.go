@@ -0,0 +1,67 @@
+package docsify
+
+import (
+    "context"
+    "reflect"
+    "testing"
+
+    "Support_Site_Sync/listing"
+)
+
+func TestListPagesTree(t *testing.T) {
+    fake := newFakeGitHub(map[string]string{
+        "README.md":                "Home",
+        "_sidebar.md":              "- [Installing](guide/install.md)\n- [Questions](faq.md)\n",
+        "faq.md":                   "FAQ",
+        "guide/README.md":          "Guide",
+        "guide/install.md":         "Install",
+        "guide/advanced/tuning.md": "Tuning",
+        "images/logo.png":          "PNG",
+    })
+    service, done := newFakeDocsify(fake, DocsifyConfig{Sidebar: true})
+    defer done()
+
+    got, err := service.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want := []listing.Summary{
+        {ID: "README.md", Title: "README"},
+        {ID: "faq.md", Title: "Questions"},
+        {ID: "guide/README.md", Title: "README", ParentID: "guide"},
+        {ID: "guide/install.md", Title: "Installing", ParentID: "guide"},
+        {ID: "guide/advanced/tuning.md", Title: "tuning", ParentID: "guide/advanced"},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListPages() = %v, want %v", got, want)
+    }
+    // One directory per request, breadth first
+    if want := []string{"main", "dir:main:guide", "dir:main:images", "dir:main:guide/advanced"}; !reflect.DeepEqual(fake.treeReads, want) {
+        t.Errorf("listed trees %q, want %q", fake.treeReads, want)
+    }
+
+    roots, err := service.RootPages(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := map[string]bool{"README.md": true, "guide/README.md": true}; !reflect.DeepEqual(roots, want) {
+        t.Errorf("RootPages() = %v, want %v", roots, want)
+    }
+}
+
+func TestListPagesLocal(t *testing.T) {
+    service, _, _ := newLocalRepos(t)
+    pushFile(t, service.config.LocalPath, "_sidebar.md", "- [FAQ](faq.md)\n")
+    pushFile(t, service.config.LocalPath, "faq.md", "FAQ")
+    pushFile(t, service.config.LocalPath, "logo.png", "PNG")
+
+    got, err := service.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []listing.Summary{{ID: "README.md", Title: "README"}, {ID: "faq.md", Title: "faq"}}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListPages() = %v, want %v", got, want)
+    }
+}
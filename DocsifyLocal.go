@@ -0,0 +1,189 @@
+package docsify
+
+import (
+    "context"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+)
+
+// git runs a git command in the local working copy and returns its trimmed output
+func (s *DocsifyServiceImpl) git(ctx context.Context, args ...string) (string, error) {
+    cmd := exec.CommandContext(ctx, "git", args...)
+    cmd.Dir = s.config.LocalPath
+    cmd.Env = os.Environ()
+    if committer := s.config.committer(); committer != nil {
+        cmd.Env = append(cmd.Env, "GIT_COMMITTER_NAME="+committer["name"], "GIT_COMMITTER_EMAIL="+committer["email"])
+    }
+
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        return "", fmt.Errorf("failed to run git %s: %v - %s", args[0], err, strings.TrimSpace(string(out)))
+    }
+    return strings.TrimSpace(string(out)), nil
+}
+
+// localFile returns the path of a page in the working copy, refusing paths that
+// would leave it
+func (s *DocsifyServiceImpl) localFile(id string) (string, error) {
+    cleaned := filepath.Clean(filepath.FromSlash(id))
+    if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+        return "", fmt.Errorf("page path %s is outside the working copy", id)
+    }
+    return filepath.Join(s.config.LocalPath, cleaned), nil
+}
+
+// commitLocal writes changes into the checked out branch of the working copy, stages
+// and commits them, and pushes the commit unless a run is in progress, in which case
+// EndRun pushes. The branch is brought up to date with the remote first, once per run
+func (s *DocsifyServiceImpl) commitLocal(ctx context.Context, message string, paths []string, changes map[string]*Page) error {
+    s.mu.Lock()
+    pulled := s.runActive && s.localPulled
+    s.mu.Unlock()
+    if !pulled {
+        if err := s.pullLocal(ctx); err != nil {
+            return err
+        }
+        s.mu.Lock()
+        s.localPulled = s.runActive
+        s.mu.Unlock()
+    }
+
+    for _, id := range paths {
+        file, err := s.localFile(id)
+        if err != nil {
+            return err
+        }
+        page := changes[id]
+        if page == nil {
+            if _, err := s.git(ctx, "rm", "-q", "--ignore-unmatch", "--", filepath.ToSlash(id)); err != nil {
+                return err
+            }
+            continue
+        }
+        if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+            return err
+        }
+        if err := ioutil.WriteFile(file, []byte(page.Content), 0644); err != nil {
+            return err
+        }
+        if _, err := s.git(ctx, "add", "--", filepath.ToSlash(id)); err != nil {
+            return err
+        }
+    }
+
+    // Nothing to commit when the files already had this content
+    if _, err := s.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+        return nil
+    }
+
+    args := []string{"commit", "-q", "-m", message}
+    if author := s.config.author(); author != nil {
+        args = append(args, fmt.Sprintf("--author=%s <%s>", author["name"], author["email"]))
+    }
+    if _, err := s.git(ctx, args...); err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    s.localUnpushed = true
+    inRun := s.runActive
+    s.mu.Unlock()
+    if inRun {
+        return nil
+    }
+    return s.pushLocal(ctx)
+}
+
+// pullLocal brings the checked out branch up to date with its branch on the remote
+// when pushing is enabled. The branch is fast-forwarded, or its unpushed commits are
+// rebased onto the remote head; a rebase that conflicts is aborted and reported
+func (s *DocsifyServiceImpl) pullLocal(ctx context.Context) error {
+    if !s.config.Push {
+        return nil
+    }
+    branch, err := s.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+    if err != nil {
+        return err
+    }
+
+    // Branches that were never pushed have nothing to catch up with
+    heads, err := s.git(ctx, "ls-remote", "--heads", s.config.remote(), branch)
+    if err != nil || heads == "" {
+        return err
+    }
+    if _, err := s.git(ctx, "fetch", "-q", s.config.remote(), branch); err != nil {
+        return err
+    }
+    if _, err := s.git(ctx, "merge", "-q", "--ff-only", "FETCH_HEAD"); err == nil {
+        return nil
+    }
+    if _, err := s.git(ctx, "rebase", "-q", "FETCH_HEAD"); err != nil {
+        s.git(ctx, "rebase", "--abort")
+        return err
+    }
+    return nil
+}
+
+// pushLocal pushes the checked out branch to the configured remote when pushing is
+// enabled and there are commits that have not been pushed. When the remote branch
+// moved since the last pull, the commits are rebased onto it and the push retried once
+func (s *DocsifyServiceImpl) pushLocal(ctx context.Context) error {
+    s.mu.Lock()
+    unpushed := s.localUnpushed
+    s.mu.Unlock()
+    if !s.config.Push || !unpushed {
+        return nil
+    }
+
+    if _, err := s.git(ctx, "push", "-q", "-u", s.config.remote(), "HEAD"); err != nil {
+        if err := s.pullLocal(ctx); err != nil {
+            return err
+        }
+        if _, err := s.git(ctx, "push", "-q", "-u", s.config.remote(), "HEAD"); err != nil {
+            return err
+        }
+    }
+    s.mu.Lock()
+    s.localUnpushed = false
+    s.mu.Unlock()
+    return nil
+}
+
+// endLocalRun pushes the commits of a run. In pull request mode the feature branch is
+// pushed for review on the git host, or removed when the run changed nothing, and the
+// working copy returns to the base branch
+func (s *DocsifyServiceImpl) endLocalRun(ctx context.Context, branch, base string, changed bool) error {
+    if branch != "" && !changed {
+        if _, err := s.git(ctx, "checkout", "-q", base); err != nil {
+            return err
+        }
+        _, err := s.git(ctx, "branch", "-q", "-D", branch)
+        return err
+    }
+
+    if err := s.pushLocal(ctx); err != nil {
+        return err
+    }
+    if branch != "" {
+        _, err := s.git(ctx, "checkout", "-q", base)
+        return err
+    }
+    return nil
+}
+
+// getLocal reads a page from the working copy
+func (s *DocsifyServiceImpl) getLocal(ctx context.Context, id string) (Page, error) {
+    file, err := s.localFile(id)
+    if err != nil {
+        return Page{}, err
+    }
+    content, err := ioutil.ReadFile(file)
+    if err != nil {
+        return Page{}, fmt.Errorf("failed to get page: %v", err)
+    }
+    return Page{ID: id, Title: s.pageTitle(ctx, id), Content: string(content)}, nil
+}
@@ -0,0 +1,138 @@
+package docsify
+
+import (
+    "context"
+    "io/ioutil"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// gitRun runs git in dir with a fixed identity and fails the test on error
+func gitRun(t *testing.T, dir string, args ...string) string {
+    t.Helper()
+    cmd := exec.Command("git", args...)
+    cmd.Dir = dir
+    cmd.Env = append(os.Environ(),
+        "GIT_AUTHOR_NAME=Other", "GIT_AUTHOR_EMAIL=other@example.com",
+        "GIT_COMMITTER_NAME=Other", "GIT_COMMITTER_EMAIL=other@example.com")
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        t.Fatalf("git %s: %v - %s", strings.Join(args, " "), err, out)
+    }
+    return strings.TrimSpace(string(out))
+}
+
+// pushFile commits a file in the clone at dir and pushes it, as another writer would
+func pushFile(t *testing.T, dir, name, content string) {
+    t.Helper()
+    if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    gitRun(t, dir, "add", name)
+    gitRun(t, dir, "commit", "-q", "-m", "Add "+name)
+    gitRun(t, dir, "push", "-q", "origin", "HEAD")
+}
+
+// newLocalRepos creates a bare remote with one commit on main, a clone for the service
+// and a clone for another writer, and returns the service and the other clone
+func newLocalRepos(t *testing.T) (*DocsifyServiceImpl, string, string) {
+    if _, err := exec.LookPath("git"); err != nil {
+        t.Skip("git is not installed")
+    }
+    root, err := ioutil.TempDir("", "docsify")
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.RemoveAll(root) })
+
+    remote, local, other := filepath.Join(root, "remote.git"), filepath.Join(root, "local"), filepath.Join(root, "other")
+    gitRun(t, root, "init", "-q", "--bare", "-b", "main", remote)
+    gitRun(t, root, "clone", "-q", remote, other)
+    gitRun(t, other, "checkout", "-q", "-b", "main")
+    pushFile(t, other, "README.md", "Home")
+    gitRun(t, root, "clone", "-q", remote, local)
+
+    config := DocsifyConfig{
+        Backend:     BackendLocal,
+        LocalPath:   local,
+        Push:        true,
+        AuthorName:  "Docs Bot",
+        AuthorEmail: "bot@example.com",
+    }
+    return NewDocsifyServiceWithConfig("owner", "docs", "", config), remote, other
+}
+
+// remoteFiles lists the files on main in the bare remote
+func remoteFiles(t *testing.T, remote string) []string {
+    return strings.Fields(gitRun(t, remote, "ls-tree", "--name-only", "main"))
+}
+
+func TestLocalPullsBeforeCommit(t *testing.T) {
+    service, remote, other := newLocalRepos(t)
+    pushFile(t, other, "other.md", "Pushed after the clone")
+
+    if _, err := service.CreatePage(context.Background(), Page{ID: "setup.md", Title: "Setup", Content: "v1"}); err != nil {
+        t.Fatalf("CreatePage() error = %v", err)
+    }
+
+    want := []string{"README.md", "other.md", "setup.md"}
+    if got := remoteFiles(t, remote); !reflect.DeepEqual(got, want) {
+        t.Errorf("remote files = %v, want %v", got, want)
+    }
+    if parents := gitRun(t, remote, "log", "-1", "--format=%P", "main"); strings.Contains(parents, " ") {
+        t.Errorf("head has parents %s, want a fast-forward without a merge", parents)
+    }
+}
+
+func TestLocalPushRebasesOnce(t *testing.T) {
+    service, remote, other := newLocalRepos(t)
+    ctx := context.Background()
+
+    if err := service.BeginRun(ctx, "r1"); err != nil {
+        t.Fatalf("BeginRun() error = %v", err)
+    }
+    if _, err := service.CreatePage(ctx, Page{ID: "setup.md", Title: "Setup", Content: "v1"}); err != nil {
+        t.Fatalf("CreatePage() error = %v", err)
+    }
+    // Pushed while the run is in progress, so the run's push is rejected
+    pushFile(t, other, "other.md", "Pushed during the run")
+    if err := service.EndRun(ctx); err != nil {
+        t.Fatalf("EndRun() error = %v", err)
+    }
+
+    want := []string{"README.md", "other.md", "setup.md"}
+    if got := remoteFiles(t, remote); !reflect.DeepEqual(got, want) {
+        t.Errorf("remote files = %v, want %v", got, want)
+    }
+    subjects := gitRun(t, remote, "log", "--format=%s %an", "main")
+    wantLog := "Create page Setup (sync run r1) Docs Bot\nAdd other.md Other\nAdd README.md Other"
+    if subjects != wantLog {
+        t.Errorf("remote log = %q, want %q", subjects, wantLog)
+    }
+}
+
+func TestLocalPushConflict(t *testing.T) {
+    service, remote, other := newLocalRepos(t)
+    ctx := context.Background()
+
+    service.BeginRun(ctx, "r1")
+    if err := service.UpdatePage(ctx, Page{ID: "README.md", Title: "Home", Content: "Synced home"}); err != nil {
+        t.Fatalf("UpdatePage() error = %v", err)
+    }
+    pushFile(t, other, "README.md", "Edited by hand")
+    if err := service.EndRun(ctx); err == nil {
+        t.Fatal("EndRun() error = nil, want a rebase conflict")
+    }
+
+    // The working copy is left on its own commit, outside a rebase
+    if _, err := os.Stat(filepath.Join(service.config.LocalPath, ".git", "rebase-merge")); !os.IsNotExist(err) {
+        t.Errorf("rebase still in progress: %v", err)
+    }
+    if got := gitRun(t, remote, "show", "main:README.md"); got != "Edited by hand" {
+        t.Errorf("remote README.md = %q, want the other writer's edit", got)
+    }
+}
@@ -0,0 +1,150 @@
+package docsify
+
+import (
+    "context"
+    "net/http"
+    "net/url"
+    "os"
+
+    "Support_Site_Sync/slug"
+)
+
+// slugs returns the path generator, creating it on first use
+func (s *DocsifyServiceImpl) slugs() (*slug.Generator, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.paths == nil {
+        paths, err := slug.New(s.config.Slug)
+        if err != nil {
+            return nil, err
+        }
+        s.paths = paths
+    }
+    return s.paths, nil
+}
+
+// ReservePath records the path a page was given in an earlier run, as kept in the ID
+// mapping, so the page stays at that path and other pages do not take it
+func (s *DocsifyServiceImpl) ReservePath(pageID, path string) {
+    if paths, err := s.slugs(); err == nil {
+        paths.Reserve(pageID, path)
+    }
+}
+
+// homePath is the file Docsify serves as the home page of the site
+const homePath = "README.md"
+
+// newPagePath returns the repository path for a page being created. The configured
+// home page is stored as README.md. With slugs enabled the path is generated from the
+// page's title and section, otherwise page.ID is used
+func (s *DocsifyServiceImpl) newPagePath(ctx context.Context, page Page) (string, error) {
+    home := s.config.HomePage != "" && page.CanonicalID() == s.config.HomePage
+    if !s.config.Slugs {
+        if home {
+            return homePath, nil
+        }
+        return page.ID, nil
+    }
+    paths, err := s.slugs()
+    if err != nil {
+        return "", err
+    }
+    if home {
+        paths.Reserve(page.ID, homePath)
+        return homePath, nil
+    }
+    branch := s.readBranch()
+    return paths.Path(page.ID, page.Title, page.Section, func(path string) (bool, error) {
+        return s.fileExists(ctx, branch, path)
+    })
+}
+
+// pagePath returns the repository path of an existing page, which is either the path
+// generated for the page ID or the ID itself
+func (s *DocsifyServiceImpl) pagePath(id string) string {
+    if !s.config.Slugs {
+        return id
+    }
+    paths, err := s.slugs()
+    if err != nil {
+        return id
+    }
+    if path, ok := paths.Assigned(id); ok {
+        return path
+    }
+    return id
+}
+
+// PagePath returns the current path of a page by its canonical ID, which changes when
+// the page is moved to another section
+func (s *DocsifyServiceImpl) PagePath(pageID string) (string, bool) {
+    if !s.config.Slugs {
+        return "", false
+    }
+    paths, err := s.slugs()
+    if err != nil {
+        return "", false
+    }
+    return paths.Assigned(pageID)
+}
+
+// movedPath returns the path a page is stored at after an update. With slugs enabled
+// a page whose section changed moves to the section's directory; other pages, and the
+// home page, stay at their current path
+func (s *DocsifyServiceImpl) movedPath(ctx context.Context, current string, page Page) (string, error) {
+    if !s.config.Slugs || current == homePath {
+        return current, nil
+    }
+    paths, err := s.slugs()
+    if err != nil {
+        return "", err
+    }
+    key, ok := paths.Key(current)
+    if !ok {
+        return current, nil
+    }
+    branch := s.readBranch()
+    moved, _, err := paths.Move(key, page.Title, page.Section, func(path string) (bool, error) {
+        return s.fileExists(ctx, branch, path)
+    })
+    return moved, err
+}
+
+// releasePath frees the path of a deleted page, given by page ID or by path
+func (s *DocsifyServiceImpl) releasePath(id string) {
+    if !s.config.Slugs {
+        return
+    }
+    if paths, err := s.slugs(); err == nil {
+        paths.ReleasePath(s.pagePath(id))
+    }
+}
+
+// fileExists reports whether a file exists on branch
+func (s *DocsifyServiceImpl) fileExists(ctx context.Context, branch, path string) (bool, error) {
+    if staged, ok := s.staged(path); ok {
+        return staged != nil, nil
+    }
+
+    if s.config.Backend == BackendLocal {
+        file, err := s.localFile(path)
+        if err != nil {
+            return false, err
+        }
+        _, err = os.Stat(file)
+        if os.IsNotExist(err) {
+            return false, nil
+        }
+        return err == nil, err
+    }
+
+    fileURL := s.repoURL("/contents/%s", path)
+    if branch != "" {
+        fileURL += "?ref=" + url.QueryEscape(branch)
+    }
+    err := s.doRequest(ctx, "check "+path, "GET", fileURL, nil, nil)
+    if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == http.StatusNotFound {
+        return false, nil
+    }
+    return err == nil, err
+}
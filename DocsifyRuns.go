@@ -0,0 +1,263 @@
+package docsify
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// commitInfo is the data available to commit message and pull request templates
+type commitInfo struct {
+    Action string // "Create", "Update" or "Delete"
+    Title  string
+    Path   string
+    RunID  string
+}
+
+// renderTemplate executes a text/template, using fallback when text is empty
+func renderTemplate(text, fallback string, data interface{}) (string, error) {
+    if text == "" {
+        text = fallback
+    }
+    tmpl, err := template.New("docsify").Parse(text)
+    if err != nil {
+        return "", err
+    }
+    var out strings.Builder
+    if err := tmpl.Execute(&out, data); err != nil {
+        return "", err
+    }
+    return out.String(), nil
+}
+
+// commitMessage renders the commit message for a change to a page
+func (s *DocsifyServiceImpl) commitMessage(action, title, path string) (string, error) {
+    s.mu.Lock()
+    runID := s.runID
+    s.mu.Unlock()
+    return renderTemplate(s.config.MessageTemplate, defaultMessageTemplate, commitInfo{Action: action, Title: title, Path: path, RunID: runID})
+}
+
+// contentsBody builds the common fields of a Contents API write
+func (s *DocsifyServiceImpl) contentsBody(message, branch string) map[string]interface{} {
+    body := map[string]interface{}{"message": message}
+    if branch != "" {
+        body["branch"] = branch
+    }
+    if author := s.config.author(); author != nil {
+        body["author"] = author
+    }
+    if committer := s.config.committer(); committer != nil {
+        body["committer"] = committer
+    }
+    return body
+}
+
+// BeginRun starts a sync run. The run ID is available to commit message templates,
+// and in pull request mode a feature branch is created for the run's changes
+func (s *DocsifyServiceImpl) BeginRun(ctx context.Context, runID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.runID = runID
+    s.runBranch = ""
+    s.runChanged = false
+    s.runActive = true
+    s.pending = nil
+    s.localPulled = false
+
+    if !s.config.PullRequest {
+        return nil
+    }
+    return s.startFeatureBranch(ctx)
+}
+
+// EndRun finishes a sync run. Changes staged in batch mode are committed together,
+// and in pull request mode a single pull request is opened for the run's feature
+// branch, or the branch is removed when the run changed nothing
+func (s *DocsifyServiceImpl) EndRun(ctx context.Context) error {
+    if err := s.flushBatch(ctx); err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    runID, branch, base, changed := s.runID, s.runBranch, s.runBase, s.runChanged
+    s.runID, s.runBranch, s.runBase, s.runChanged, s.runActive = "", "", "", false, false
+    s.mu.Unlock()
+
+    if s.config.Backend == BackendLocal {
+        return s.endLocalRun(ctx, branch, base, changed)
+    }
+    if branch == "" {
+        return nil
+    }
+    if !changed {
+        return s.doRequest(ctx, "delete branch", "DELETE", s.repoURL("/git/refs/heads/%s", branch), nil, nil)
+    }
+
+    title, err := renderTemplate(s.config.PRTitleTemplate, defaultPRTitleTemplate, commitInfo{RunID: runID})
+    if err != nil {
+        return err
+    }
+
+    reqBody := map[string]interface{}{
+        "title": title,
+        "head":  branch,
+        "base":  base,
+        "body":  s.config.PRBody,
+    }
+    return s.doRequest(ctx, "open pull request", "POST", s.repoURL("/pulls"), reqBody, nil)
+}
+
+// writeBranch returns the branch page changes are committed to. In pull request mode
+// a write outside BeginRun and EndRun starts a run of its own, whose pull request is
+// opened by the next call to EndRun
+func (s *DocsifyServiceImpl) writeBranch(ctx context.Context) (string, error) {
+    if !s.config.PullRequest {
+        return s.config.Branch, nil
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.runChanged = true
+    if s.runBranch != "" {
+        return s.runBranch, nil
+    }
+    if s.runID == "" {
+        s.runID = time.Now().UTC().Format("20060102T150405Z")
+    }
+    if err := s.startFeatureBranch(ctx); err != nil {
+        return "", err
+    }
+    return s.runBranch, nil
+}
+
+// readBranch returns the branch pages are read from, which in pull request mode is
+// the feature branch of the current run if it has one
+func (s *DocsifyServiceImpl) readBranch() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.runBranch != "" {
+        return s.runBranch
+    }
+    return s.config.Branch
+}
+
+// startFeatureBranch creates the feature branch of the current run from the head of
+// the base branch. The caller must hold s.mu
+func (s *DocsifyServiceImpl) startFeatureBranch(ctx context.Context) error {
+    base, err := s.baseBranch(ctx)
+    if err != nil {
+        return err
+    }
+    branch := s.config.branchPrefix() + s.runID
+
+    if s.config.Backend == BackendLocal {
+        // The feature branch starts from the remote head of the base branch
+        if _, err := s.git(ctx, "checkout", "-q", base); err != nil {
+            return err
+        }
+        if err := s.pullLocal(ctx); err != nil {
+            return err
+        }
+        if _, err := s.git(ctx, "checkout", "-q", "-B", branch, base); err != nil {
+            return err
+        }
+        s.runBranch, s.runBase = branch, base
+        return nil
+    }
+
+    var ref struct {
+        Object struct {
+            SHA string `json:"sha"`
+        } `json:"object"`
+    }
+    if err := s.doRequest(ctx, "get branch", "GET", s.repoURL("/git/ref/heads/%s", base), nil, &ref); err != nil {
+        return err
+    }
+
+    reqBody := map[string]interface{}{"ref": "refs/heads/" + branch, "sha": ref.Object.SHA}
+    if err := s.doRequest(ctx, "create branch", "POST", s.repoURL("/git/refs"), reqBody, nil); err != nil {
+        return err
+    }
+
+    s.runBranch, s.runBase = branch, base
+    return nil
+}
+
+// baseBranch returns the configured branch, or the repository's default branch
+func (s *DocsifyServiceImpl) baseBranch(ctx context.Context) (string, error) {
+    if s.config.Branch != "" {
+        return s.config.Branch, nil
+    }
+    if s.config.Backend == BackendLocal {
+        return s.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+    }
+
+    var repo struct {
+        DefaultBranch string `json:"default_branch"`
+    }
+    if err := s.doRequest(ctx, "get repository", "GET", s.repoURL(""), nil, &repo); err != nil {
+        return "", err
+    }
+    return repo.DefaultBranch, nil
+}
+
+// repoURL returns the GitHub API URL of a path within the repository
+func (s *DocsifyServiceImpl) repoURL(format string, args ...interface{}) string {
+    return fmt.Sprintf("%s/repos/%s/%s", s.config.apiURL(), s.repoOwner, s.repoName) + fmt.Sprintf(format, args...)
+}
+
+// doRequest sends an authenticated JSON request to the GitHub API and decodes the
+// response into out when it is not nil
+func (s *DocsifyServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
+    req.Header.Set("Accept", "application/vnd.github+json")
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return &apiError{Action: action, StatusCode: resp.StatusCode, Status: resp.Status, Body: string(respBody)}
+    }
+
+    if out != nil && resp.StatusCode != http.StatusNoContent {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
+
+// apiError is returned by doRequest when GitHub responds with a non-2xx status
+type apiError struct {
+    Action     string
+    StatusCode int
+    Status     string
+    Body       string
+}
+
+func (e *apiError) Error() string {
+    return fmt.Sprintf("failed to %s: %s - %s", e.Action, e.Status, e.Body)
+}
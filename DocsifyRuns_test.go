@@ -0,0 +1,373 @@
+package docsify
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+// fakeGitHub serves the Contents, Git Data and pull request APIs of the repository
+// owner/docs. Writes are recorded as "METHOD path" relative to the repository, with
+// their bodies in the same order
+type fakeGitHub struct {
+    defaultBranch string
+    refs          map[string]string            // Head commit SHAs by branch
+    files         map[string]map[string]string // File contents by branch and path
+    trees         map[string][]interface{}     // Entries of created trees by SHA
+    commits       map[string]map[string]interface{}
+    pulls         []map[string]interface{}
+    writes        []string
+    bodies        []map[string]interface{}
+    staleRefs     int      // Branch updates to reject as if someone else pushed first
+    treeReads     []string // SHAs of the trees listed
+}
+
+func newFakeGitHub(files map[string]string) *fakeGitHub {
+    return &fakeGitHub{
+        defaultBranch: "main",
+        refs:          map[string]string{"main": "head-main"},
+        files:         map[string]map[string]string{"main": files},
+        trees:         map[string][]interface{}{},
+        commits:       map[string]map[string]interface{}{},
+    }
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/repos/owner/docs")
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    if r.Method != "GET" {
+        f.writes = append(f.writes, r.Method+" "+path)
+        f.bodies = append(f.bodies, body)
+    }
+
+    switch {
+    case path == "" && r.Method == "GET":
+        json.NewEncoder(w).Encode(map[string]string{"default_branch": f.defaultBranch})
+
+    case strings.HasPrefix(path, "/git/ref/heads/"):
+        sha, ok := f.refs[strings.TrimPrefix(path, "/git/ref/heads/")]
+        if !ok {
+            http.NotFound(w, r)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": sha}})
+
+    case path == "/git/refs":
+        branch, sha := strings.TrimPrefix(body["ref"].(string), "refs/heads/"), body["sha"].(string)
+        f.refs[branch] = sha
+        f.files[branch] = map[string]string{}
+        for from, head := range f.refs {
+            if head == sha && from != branch {
+                for name, content := range f.files[from] {
+                    f.files[branch][name] = content
+                }
+            }
+        }
+        w.WriteHeader(http.StatusCreated)
+
+    case strings.HasPrefix(path, "/git/refs/heads/") && r.Method == "DELETE":
+        delete(f.refs, strings.TrimPrefix(path, "/git/refs/heads/"))
+        w.WriteHeader(http.StatusNoContent)
+
+    case strings.HasPrefix(path, "/git/refs/heads/"):
+        branch, sha := strings.TrimPrefix(path, "/git/refs/heads/"), body["sha"].(string)
+        parents, _ := f.commits[sha]["parents"].([]interface{})
+        if f.staleRefs > 0 {
+            f.staleRefs--
+            f.refs[branch] = fmt.Sprintf("pushed-%d", f.staleRefs)
+            f.files[branch]["other.md"] = "Pushed by someone else"
+        }
+        if len(parents) != 1 || parents[0] != f.refs[branch] {
+            http.Error(w, `{"message": "Update is not a fast forward"}`, http.StatusUnprocessableEntity)
+            return
+        }
+        f.refs[branch] = sha
+        for _, entry := range f.trees[f.commits[sha]["tree"].(string)] {
+            entry := entry.(map[string]interface{})
+            if content, ok := entry["content"].(string); ok {
+                f.files[branch][entry["path"].(string)] = content
+            } else {
+                delete(f.files[branch], entry["path"].(string))
+            }
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": sha}})
+
+    case strings.HasPrefix(path, "/git/commits/"):
+        sha := strings.TrimPrefix(path, "/git/commits/")
+        json.NewEncoder(w).Encode(map[string]interface{}{"sha": sha, "tree": map[string]string{"sha": "tree-of-" + sha}})
+
+    case strings.HasPrefix(path, "/git/trees/") && r.Method == "GET":
+        // A branch name lists its root; directories are listed by the SHA
+        // "dir:<branch>:<path>" their parent gives them
+        sha := strings.TrimPrefix(path, "/git/trees/")
+        f.treeReads = append(f.treeReads, sha)
+        branch, dir := sha, ""
+        if parts := strings.SplitN(sha, ":", 3); len(parts) == 3 {
+            branch, dir = parts[1], parts[2]+"/"
+        }
+        var entries []treeEntry
+        seen := map[string]bool{}
+        for name := range f.files[branch] {
+            if !strings.HasPrefix(name, dir) {
+                continue
+            }
+            rest := strings.TrimPrefix(name, dir)
+            if i := strings.Index(rest, "/"); i >= 0 {
+                if !seen[rest[:i]] {
+                    seen[rest[:i]] = true
+                    entries = append(entries, treeEntry{Path: rest[:i], Type: "tree", SHA: "dir:" + branch + ":" + dir + rest[:i]})
+                }
+                continue
+            }
+            entries = append(entries, treeEntry{Path: rest, Type: "blob", SHA: "blob-" + name})
+        }
+        sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+        json.NewEncoder(w).Encode(map[string]interface{}{"sha": sha, "tree": entries})
+
+    case path == "/git/trees":
+        sha := fmt.Sprintf("tree-%d", len(f.trees)+1)
+        f.trees[sha], _ = body["tree"].([]interface{})
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]string{"sha": sha})
+
+    case path == "/git/commits":
+        sha := fmt.Sprintf("commit-%d", len(f.commits)+1)
+        f.commits[sha] = body
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]string{"sha": sha})
+
+    case strings.HasPrefix(path, "/contents/"):
+        name := strings.TrimPrefix(path, "/contents/")
+        branch := r.URL.Query().Get("ref")
+        if b, ok := body["branch"].(string); ok {
+            branch = b
+        }
+        if branch == "" {
+            branch = f.defaultBranch
+        }
+        content, exists := f.files[branch][name]
+        switch r.Method {
+        case "GET":
+            if !exists {
+                http.NotFound(w, r)
+                return
+            }
+            json.NewEncoder(w).Encode(map[string]string{"sha": "blob-" + name, "content": encodeBase64(content)})
+        case "PUT":
+            f.files[branch][name] = decodeBase64(body["content"].(string))
+            if exists {
+                w.WriteHeader(http.StatusOK)
+            } else {
+                w.WriteHeader(http.StatusCreated)
+            }
+        case "DELETE":
+            delete(f.files[branch], name)
+            w.WriteHeader(http.StatusNoContent)
+        }
+
+    case path == "/pulls":
+        f.pulls = append(f.pulls, body)
+        w.WriteHeader(http.StatusCreated)
+
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+// newFakeDocsify returns a service committing to the fake repository
+func newFakeDocsify(fake *fakeGitHub, config DocsifyConfig) (*DocsifyServiceImpl, func()) {
+    server := httptest.NewServer(fake)
+    config.APIURL = server.URL
+    return NewDocsifyServiceWithConfig("owner", "docs", "token", config), server.Close
+}
+
+func TestContentsWrites(t *testing.T) {
+    author := map[string]interface{}{"name": "Docs Bot", "email": "bot@example.com"}
+    release := map[string]interface{}{"name": "Release", "email": "release@example.com"}
+
+    tests := []struct {
+        name      string
+        config    DocsifyConfig
+        branch    interface{}
+        author    interface{}
+        committer interface{}
+        messages  []string
+    }{
+        {
+            name:     "defaults",
+            config:   DocsifyConfig{},
+            messages: []string{"Create page Setup (sync run r1)", "Update page Setup (sync run r1)", "Delete page setup.md (sync run r1)"},
+        },
+        {
+            name:      "branch and identity",
+            config:    DocsifyConfig{Branch: "main", AuthorName: "Docs Bot", AuthorEmail: "bot@example.com"},
+            branch:    "main",
+            author:    author,
+            committer: author,
+            messages:  []string{"Create page Setup (sync run r1)", "Update page Setup (sync run r1)", "Delete page setup.md (sync run r1)"},
+        },
+        {
+            name: "committer and template",
+            config: DocsifyConfig{
+                AuthorName: "Docs Bot", AuthorEmail: "bot@example.com",
+                CommitterName: "Release", CommitterEmail: "release@example.com",
+                MessageTemplate: "docs: {{.Action | printf \"%.1s\"}} {{.Path}} [{{.RunID}}]",
+            },
+            author:    author,
+            committer: release,
+            messages:  []string{"docs: C setup.md [r1]", "docs: U setup.md [r1]", "docs: D setup.md [r1]"},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGitHub(map[string]string{})
+            service, done := newFakeDocsify(fake, tt.config)
+            defer done()
+
+            ctx := context.Background()
+            if err := service.BeginRun(ctx, "r1"); err != nil {
+                t.Fatalf("BeginRun() error = %v", err)
+            }
+            if _, err := service.CreatePage(ctx, Page{ID: "setup.md", Title: "Setup", Content: "v1"}); err != nil {
+                t.Fatalf("CreatePage() error = %v", err)
+            }
+            if err := service.UpdatePage(ctx, Page{ID: "setup.md", Title: "Setup", Content: "v2"}); err != nil {
+                t.Fatalf("UpdatePage() error = %v", err)
+            }
+            if err := service.DeletePage(ctx, "setup.md"); err != nil {
+                t.Fatalf("DeletePage() error = %v", err)
+            }
+            if err := service.EndRun(ctx); err != nil {
+                t.Fatalf("EndRun() error = %v", err)
+            }
+
+            want := []string{"PUT /contents/setup.md", "PUT /contents/setup.md", "DELETE /contents/setup.md"}
+            if !reflect.DeepEqual(fake.writes, want) {
+                t.Fatalf("writes = %q, want %q", fake.writes, want)
+            }
+            for i, body := range fake.bodies {
+                if body["message"] != tt.messages[i] {
+                    t.Errorf("write %d message = %q, want %q", i+1, body["message"], tt.messages[i])
+                }
+                if body["branch"] != tt.branch {
+                    t.Errorf("write %d branch = %v, want %v", i+1, body["branch"], tt.branch)
+                }
+                if !reflect.DeepEqual(body["author"], tt.author) || !reflect.DeepEqual(body["committer"], tt.committer) {
+                    t.Errorf("write %d author, committer = %v, %v, want %v, %v", i+1, body["author"], body["committer"], tt.author, tt.committer)
+                }
+            }
+            if i := len(fake.bodies) - 1; fake.bodies[i]["sha"] != "blob-setup.md" {
+                t.Errorf("delete sha = %v, want blob-setup.md", fake.bodies[i]["sha"])
+            }
+        })
+    }
+}
+
+func TestPullRequestMode(t *testing.T) {
+    tests := []struct {
+        name   string
+        config DocsifyConfig
+        write  bool
+        writes []string
+        pull   map[string]interface{}
+    }{
+        {
+            name:   "changed run",
+            config: DocsifyConfig{PullRequest: true, PRBody: "Synced from the help center"},
+            write:  true,
+            writes: []string{"POST /git/refs", "PUT /contents/setup.md", "POST /pulls"},
+            pull: map[string]interface{}{
+                "title": "Sync support content (run r1)",
+                "head":  "sync/r1",
+                "base":  "main",
+                "body":  "Synced from the help center",
+            },
+        },
+        {
+            name:   "configured branch and templates",
+            config: DocsifyConfig{PullRequest: true, Branch: "release", BranchPrefix: "bot/", PRTitleTemplate: "Docs {{.RunID}}"},
+            write:  true,
+            writes: []string{"POST /git/refs", "PUT /contents/setup.md", "POST /pulls"},
+            pull:   map[string]interface{}{"title": "Docs r1", "head": "bot/r1", "base": "release", "body": ""},
+        },
+        {
+            name:   "unchanged run",
+            config: DocsifyConfig{PullRequest: true},
+            writes: []string{"POST /git/refs", "DELETE /git/refs/heads/sync/r1"},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGitHub(map[string]string{"README.md": "Home"})
+            fake.refs["release"], fake.files["release"] = "head-release", map[string]string{}
+            service, done := newFakeDocsify(fake, tt.config)
+            defer done()
+
+            ctx := context.Background()
+            if err := service.BeginRun(ctx, "r1"); err != nil {
+                t.Fatalf("BeginRun() error = %v", err)
+            }
+            if tt.write {
+                if _, err := service.CreatePage(ctx, Page{ID: "setup.md", Title: "Setup", Content: "v1"}); err != nil {
+                    t.Fatalf("CreatePage() error = %v", err)
+                }
+                head := tt.pull["head"].(string)
+                if page, err := service.GetPage(ctx, "setup.md"); err != nil || page.Content != "v1" {
+                    t.Errorf("GetPage() = %q, %v, want the page on %s", page.Content, err, head)
+                }
+                if _, ok := fake.files[tt.pull["base"].(string)]["setup.md"]; ok {
+                    t.Errorf("page committed to the base branch, want %s only", head)
+                }
+            }
+            if err := service.EndRun(ctx); err != nil {
+                t.Fatalf("EndRun() error = %v", err)
+            }
+
+            if !reflect.DeepEqual(fake.writes, tt.writes) {
+                t.Fatalf("writes = %q, want %q", fake.writes, tt.writes)
+            }
+            if tt.pull == nil {
+                if len(fake.pulls) != 0 {
+                    t.Errorf("pulls = %v, want none", fake.pulls)
+                }
+                return
+            }
+            if len(fake.pulls) != 1 || !reflect.DeepEqual(fake.pulls[0], tt.pull) {
+                t.Errorf("pulls = %v, want %v", fake.pulls, tt.pull)
+            }
+        })
+    }
+}
+
+func TestPullRequestWithoutRun(t *testing.T) {
+    fake := newFakeGitHub(map[string]string{})
+    service, done := newFakeDocsify(fake, DocsifyConfig{PullRequest: true})
+    defer done()
+
+    ctx := context.Background()
+    for _, id := range []string{"a.md", "b.md"} {
+        if _, err := service.CreatePage(ctx, Page{ID: id, Title: id, Content: id}); err != nil {
+            t.Fatalf("CreatePage(%q) error = %v", id, err)
+        }
+    }
+    if err := service.EndRun(ctx); err != nil {
+        t.Fatalf("EndRun() error = %v", err)
+    }
+
+    // Both writes share the branch started by the first one
+    want := []string{"POST /git/refs", "PUT /contents/a.md", "PUT /contents/b.md", "POST /pulls"}
+    if !reflect.DeepEqual(fake.writes, want) {
+        t.Fatalf("writes = %q, want %q", fake.writes, want)
+    }
+    branch := fake.pulls[0]["head"].(string)
+    if !strings.HasPrefix(branch, "sync/") || fake.bodies[1]["branch"] != branch || fake.bodies[2]["branch"] != branch {
+        t.Errorf("writes on %v, %v and pull request from %q, want one sync/ branch", fake.bodies[1]["branch"], fake.bodies[2]["branch"], branch)
+    }
+}
@@ -1,6 +1,10 @@
 package docsify
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // DocsifyService defines the methods for interacting with Docsify
 type DocsifyService interface {
@@ -8,4 +12,5 @@ type DocsifyService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
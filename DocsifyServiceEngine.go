@@ -2,11 +2,17 @@ package docsify
 
 import (
     "context"
+    "encoding/base64"
     "encoding/json"
     "fmt"
     "net/http"
     "bytes"
     "io/ioutil"
+    "net/url"
+    "strings"
+    "sync"
+
+    "Support_Site_Sync/slug"
 )
 
 // DocsifyServiceImpl is the implementation of the DocsifyService interface
@@ -14,6 +20,20 @@ type DocsifyServiceImpl struct {
     repoOwner    string
     repoName     string
     apiKey        string
+    config        DocsifyConfig
+
+    mu         sync.Mutex
+    runID      string             // ID of the current sync run
+    runBranch  string             // Feature branch of the current run in pull request mode
+    runBase    string             // Branch the feature branch was created from
+    runChanged bool               // Whether the current run has committed anything
+    runActive  bool               // Whether BeginRun has been called without EndRun
+    pending    map[string]*Page   // Changes staged in batch mode by path, nil to delete
+
+    localUnpushed bool // Whether the local working copy has commits that were not pushed
+    localPulled   bool // Whether the current run has brought the working copy up to date
+
+    paths *slug.Generator // Page paths, used when slugs are enabled
 }
 
 // NewDocsifyService creates a new instance of DocsifyService
@@ -21,15 +41,45 @@ func NewDocsifyService(repoOwner, repoName, apiKey string) *DocsifyServiceImpl {
     return &DocsifyServiceImpl{repoOwner: repoOwner, repoName: repoName, apiKey: apiKey}
 }
 
-// CreatePage creates a new page in the Docsify repository
+// NewDocsifyServiceWithConfig creates a new instance of DocsifyService that commits to
+// the configured branch with the configured identity, or opens pull requests
+func NewDocsifyServiceWithConfig(repoOwner, repoName, apiKey string, config DocsifyConfig) *DocsifyServiceImpl {
+    return &DocsifyServiceImpl{repoOwner: repoOwner, repoName: repoName, apiKey: apiKey, config: config}
+}
+
+// CreatePage creates a new page in the Docsify repository, adding it to the sidebar
+// and navbar in the same commit when their maintenance is enabled. The returned ID is
+// the path of the page, generated from its title and section when slugs are enabled,
+// or README.md for the configured home page
 func (s *DocsifyServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
+    path, err := s.newPagePath(ctx, page)
+    if err != nil {
+        return "", err
+    }
+    page.ID = path
+
+    if s.batching() {
+        s.stage(page.ID, &page)
+        return page.ID, nil
+    }
+    if s.config.Backend == BackendLocal || s.config.Sidebar || s.config.Navbar {
+        return page.ID, s.writePage(ctx, "Create", page.Title, page.ID, &page)
+    }
+
     // GitHub API URL to create a new file
-    url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", s.repoOwner, s.repoName, page.ID)
+    url := s.repoURL("/contents/%s", page.ID)
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "message": "Create page " + page.Title,
-        "content": encodeBase64(page.Content),
-    })
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return "", err
+    }
+    message, err := s.commitMessage("Create", page.Title, page.ID)
+    if err != nil {
+        return "", err
+    }
+    body := s.contentsBody(message, branch)
+    body["content"] = encodeBase64(page.Content)
+    reqBody, _ := json.Marshal(body)
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
@@ -50,10 +100,38 @@ func (s *DocsifyServiceImpl) CreatePage(ctx context.Context, page Page) (string,
     return page.ID, nil
 }
 
-// UpdatePage updates an existing page in the Docsify repository
+// UpdatePage updates an existing page in the Docsify repository, moving or renaming
+// its sidebar and navbar entries in the same commit when their maintenance is enabled. With slugs
+// enabled a page whose section changed is moved to the section's directory
 func (s *DocsifyServiceImpl) UpdatePage(ctx context.Context, page Page) error {
+    current := s.pagePath(page.ID)
+    moved, err := s.movedPath(ctx, current, page)
+    if err != nil {
+        return err
+    }
+    page.ID = moved
+
+    if s.batching() {
+        if moved != current {
+            s.stage(current, nil)
+        }
+        s.stage(page.ID, &page)
+        return nil
+    }
+    if moved != current {
+        return s.movePage(ctx, current, &page)
+    }
+    if s.config.Backend == BackendLocal || s.config.Sidebar || s.config.Navbar {
+        return s.writePage(ctx, "Update", page.Title, page.ID, &page)
+    }
+
     // GitHub API URL to update a file
-    url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", s.repoOwner, s.repoName, page.ID)
+    url := s.repoURL("/contents/%s", page.ID)
+
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return err
+    }
 
     // Fetch the current file to get the SHA
     sha, err := s.getFileSHA(ctx, page.ID)
@@ -61,11 +139,14 @@ func (s *DocsifyServiceImpl) UpdatePage(ctx context.Context, page Page) error {
         return err
     }
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "message": "Update page " + page.Title,
-        "content": encodeBase64(page.Content),
-        "sha":     sha,
-    })
+    message, err := s.commitMessage("Update", page.Title, page.ID)
+    if err != nil {
+        return err
+    }
+    body := s.contentsBody(message, branch)
+    body["content"] = encodeBase64(page.Content)
+    body["sha"] = sha
+    reqBody, _ := json.Marshal(body)
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
@@ -85,10 +166,27 @@ func (s *DocsifyServiceImpl) UpdatePage(ctx context.Context, page Page) error {
     return nil
 }
 
-// DeletePage deletes a page from the Docsify repository
+// DeletePage deletes a page from the Docsify repository, removing its sidebar and
+// navbar entries in the same commit when their maintenance is enabled
 func (s *DocsifyServiceImpl) DeletePage(ctx context.Context, id string) error {
+    defer s.releasePath(id)
+    id = s.pagePath(id)
+
+    if s.batching() {
+        s.stage(id, nil)
+        return nil
+    }
+    if s.config.Backend == BackendLocal || s.config.Sidebar || s.config.Navbar {
+        return s.writePage(ctx, "Delete", id, id, nil)
+    }
+
     // GitHub API URL to delete a file
-    url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", s.repoOwner, s.repoName, id)
+    url := s.repoURL("/contents/%s", id)
+
+    branch, err := s.writeBranch(ctx)
+    if err != nil {
+        return err
+    }
 
     // Fetch the current file to get the SHA
     sha, err := s.getFileSHA(ctx, id)
@@ -96,10 +194,13 @@ func (s *DocsifyServiceImpl) DeletePage(ctx context.Context, id string) error {
         return err
     }
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "message": "Delete page",
-        "sha":     sha,
-    })
+    message, err := s.commitMessage("Delete", id, id)
+    if err != nil {
+        return err
+    }
+    body := s.contentsBody(message, branch)
+    body["sha"] = sha
+    reqBody, _ := json.Marshal(body)
 
     req, _ := http.NewRequest("DELETE", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
@@ -119,9 +220,22 @@ func (s *DocsifyServiceImpl) DeletePage(ctx context.Context, id string) error {
     return nil
 }
 
-// GetPage retrieves a page from the Docsify repository
+// GetPage retrieves a page from the Docsify repository, including changes staged in
+// batch mode that are not committed yet
 func (s *DocsifyServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
-    url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", s.repoOwner, s.repoName, id)
+    id = s.pagePath(id)
+
+    if staged, ok := s.staged(id); ok {
+        if staged == nil {
+            return Page{}, fmt.Errorf("failed to get page: %s is staged for deletion", id)
+        }
+        return Page{ID: id, Title: s.pageTitle(ctx, id), Content: staged.Content}, nil
+    }
+    if s.config.Backend == BackendLocal {
+        return s.getLocal(ctx, id)
+    }
+
+    url := s.contentsURL(id)
 
     req, _ := http.NewRequest("GET", url, nil)
     req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
@@ -147,14 +261,14 @@ func (s *DocsifyServiceImpl) GetPage(ctx context.Context, id string) (Page, erro
 
     return Page{
         ID:      id,
-        Title:   result["name"].(string),
+        Title:   s.pageTitle(ctx, id),
         Content: decodeBase64(content),
     }, nil
 }
 
 // getFileSHA fetches the SHA of the file
 func (s *DocsifyServiceImpl) getFileSHA(ctx context.Context, path string) (string, error) {
-    url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", s.repoOwner, s.repoName, path)
+    url := s.contentsURL(path)
 
     req, _ := http.NewRequest("GET", url, nil)
     req.Header.Set("Authorization", fmt.Sprintf("token %s", s.apiKey))
@@ -181,13 +295,23 @@ func (s *DocsifyServiceImpl) getFileSHA(ctx context.Context, path string) (strin
     return sha, nil
 }
 
+// contentsURL returns the Contents API URL of a file on the branch pages are read from
+func (s *DocsifyServiceImpl) contentsURL(path string) string {
+    contentsURL := s.repoURL("/contents/%s", path)
+    if branch := s.readBranch(); branch != "" {
+        contentsURL += "?ref=" + url.QueryEscape(branch)
+    }
+    return contentsURL
+}
+
 // encodeBase64 encodes a string to base64
 func encodeBase64(content string) string {
     return base64.StdEncoding.EncodeToString([]byte(content))
 }
 
-// decodeBase64 decodes a base64 string
+// decodeBase64 decodes a base64 string. GitHub wraps the encoded content at 60
+// characters, which the standard decoder does not accept
 func decodeBase64(content string) string {
-    decoded, _ := base64.StdEncoding.DecodeString(content)
+    decoded, _ := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
     return string(decoded)
 }
@@ -0,0 +1,356 @@
+package docsify
+
+import (
+    "context"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// sidebarItemPattern matches a list item of _sidebar.md with its indentation
+var sidebarItemPattern = regexp.MustCompile(`^(\s*)[-*+] (.*)$`)
+
+// sidebarLinkPattern matches a Markdown link making up a whole sidebar item
+var sidebarLinkPattern = regexp.MustCompile(`^\[(.*)\]\((.*)\)$`)
+
+// sidebarPositionPattern matches the comment recording the position of a page entry,
+// which Docsify does not render
+var sidebarPositionPattern = regexp.MustCompile(`\s*<!-- position:(\d+) -->$`)
+
+// sidebarNode is an entry of the sidebar: a page link, a section heading when Link is
+// empty, or text kept verbatim when Raw is set
+type sidebarNode struct {
+    Title    string
+    Link     string
+    Position int    // Sibling position of a synced page, 0 when it has none
+    Raw      string // Lines between or after the items, such as comments or a footer
+    Children []*sidebarNode
+}
+
+// parseSidebar reads _sidebar.md into the lines before the first item, which are kept
+// as they are, and the tree of items. Other lines after the first item are kept as raw
+// nodes where they appear: indented lines under the item above them, without their
+// indentation, and others at the top level, where they end the nested lists
+func parseSidebar(content string) ([]string, []*sidebarNode) {
+    var header []string
+    var roots []*sidebarNode
+    var stack []*sidebarNode // Last node seen at each depth
+    blanks := 0              // Blank lines since the last non-blank line
+
+    for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+        match := sidebarItemPattern.FindStringSubmatch(line)
+        if match == nil {
+            switch {
+            case strings.TrimSpace(line) == "":
+                blanks++
+                continue
+            case roots == nil:
+                header = append(header, line)
+            case strings.TrimLeft(line, " \t") != line && len(stack) > 0:
+                parent := stack[len(stack)-1]
+                parent.Children = append(parent.Children, &sidebarNode{Raw: strings.TrimSpace(line)})
+            case roots[len(roots)-1].Raw != "":
+                // Paragraphs of one block stay together, with their blank lines
+                roots[len(roots)-1].Raw += strings.Repeat("\n", blanks+1) + line
+            default:
+                roots = append(roots, &sidebarNode{Raw: line})
+                stack = nil
+            }
+            blanks = 0
+            continue
+        }
+        blanks = 0
+
+        node := &sidebarNode{Title: strings.TrimSpace(match[2])}
+        if position := sidebarPositionPattern.FindStringSubmatch(node.Title); position != nil {
+            node.Position, _ = strconv.Atoi(position[1])
+            node.Title = strings.TrimSpace(strings.TrimSuffix(node.Title, position[0]))
+        }
+        if link := sidebarLinkPattern.FindStringSubmatch(node.Title); link != nil {
+            node.Title, node.Link = unescapeTitle(link[1]), link[2]
+        }
+
+        depth := len(strings.ReplaceAll(match[1], "\t", "  ")) / 2
+        if depth > len(stack) {
+            depth = len(stack)
+        }
+        stack = stack[:depth]
+        if depth == 0 {
+            roots = append(roots, node)
+        } else {
+            parent := stack[depth-1]
+            parent.Children = append(parent.Children, node)
+        }
+        stack = append(stack, node)
+    }
+
+    return header, roots
+}
+
+// renderSidebar writes the header lines and the item tree back as _sidebar.md. Raw
+// nodes at the top level are separated from the lists around them by blank lines, and
+// nested ones are indented to their depth
+func renderSidebar(header []string, nodes []*sidebarNode) string {
+    var out strings.Builder
+    for _, line := range header {
+        out.WriteString(line + "\n")
+    }
+    if len(header) > 0 {
+        out.WriteString("\n")
+    }
+
+    var write func(nodes []*sidebarNode, depth int)
+    write = func(nodes []*sidebarNode, depth int) {
+        for i, node := range nodes {
+            if node.Raw != "" {
+                if depth == 0 && i > 0 {
+                    out.WriteString("\n")
+                }
+                out.WriteString(strings.Repeat("  ", depth) + node.Raw + "\n")
+                continue
+            }
+            if depth == 0 && i > 0 && nodes[i-1].Raw != "" {
+                out.WriteString("\n")
+            }
+            out.WriteString(strings.Repeat("  ", depth) + "- ")
+            if node.Link != "" {
+                out.WriteString("[" + escapeTitle(node.Title) + "](" + node.Link + ")")
+                if node.Position != 0 {
+                    out.WriteString(fmt.Sprintf(" <!-- position:%d -->", node.Position))
+                }
+            } else {
+                out.WriteString(node.Title)
+            }
+            out.WriteString("\n")
+            write(node.Children, depth+1)
+        }
+    }
+    write(nodes, 0)
+
+    return out.String()
+}
+
+// removeSidebarLink removes the entries linking to link, and the sections left empty
+// by their removal
+func removeSidebarLink(nodes []*sidebarNode, link string) []*sidebarNode {
+    kept := nodes[:0]
+    for _, node := range nodes {
+        if node.Link == link {
+            continue
+        }
+        if len(node.Children) > 0 {
+            node.Children = removeSidebarLink(node.Children, link)
+            if len(node.Children) == 0 && node.Link == "" {
+                continue
+            }
+        }
+        kept = append(kept, node)
+    }
+    return kept
+}
+
+// addSidebarLink adds an entry for a page under its section path, creating the section
+// headings that do not exist yet. Entries with a position go before the first sibling
+// entry with a higher one; other entries go at the end of their section, before any
+// raw lines closing it
+func addSidebarLink(nodes []*sidebarNode, section []string, title, link string, position int) []*sidebarNode {
+    end := len(nodes)
+    for end > 0 && nodes[end-1].Raw != "" {
+        end--
+    }
+
+    if len(section) == 0 {
+        entry := &sidebarNode{Title: title, Link: link, Position: position}
+        if position != 0 {
+            for i, node := range nodes[:end] {
+                if node.Link != "" && node.Position > position {
+                    return insertNode(nodes, i, entry)
+                }
+            }
+        }
+        return insertNode(nodes, end, entry)
+    }
+
+    for _, node := range nodes {
+        if node.Link == "" && node.Raw == "" && node.Title == section[0] {
+            node.Children = addSidebarLink(node.Children, section[1:], title, link, position)
+            return nodes
+        }
+    }
+    heading := &sidebarNode{Title: section[0]}
+    heading.Children = addSidebarLink(nil, section[1:], title, link, position)
+    return insertNode(nodes, end, heading)
+}
+
+// insertNode inserts node into nodes at index i
+func insertNode(nodes []*sidebarNode, i int, node *sidebarNode) []*sidebarNode {
+    return append(nodes[:i], append([]*sidebarNode{node}, nodes[i:]...)...)
+}
+
+// updateSidebar applies page changes to the content of _sidebar.md. Changed pages are
+// moved to their current section and position and renamed, deleted pages are removed
+func updateSidebar(content string, changes map[string]*Page, paths []string) string {
+    header, nodes := parseSidebar(content)
+    for _, path := range paths {
+        link := sidebarLink(path)
+        page := changes[path]
+        if page == nil {
+            nodes = removeSidebarLink(nodes, link)
+            continue
+        }
+        existing := findSidebarLink(nodes, link)
+        if existing != nil && sameSection(nodes, existing, page.Section) && (page.Position == 0 || existing.Position == page.Position) {
+            existing.Title = page.Title
+            continue
+        }
+        nodes = removeSidebarLink(nodes, link)
+        nodes = addSidebarLink(nodes, page.Section, page.Title, link, page.Position)
+        if existing != nil {
+            // Entries and lines nested under a moved entry move with it
+            findSidebarLink(nodes, link).Children = existing.Children
+        }
+    }
+    return renderSidebar(header, nodes)
+}
+
+// updateNavbar applies page changes to the content of _navbar.md, which lists the
+// pages of each top-level section in a dropdown named after it. Pages in nested
+// sections are listed in the dropdown of their top-level section
+func updateNavbar(content string, changes map[string]*Page, paths []string) string {
+    top := make(map[string]*Page, len(changes))
+    for path, page := range changes {
+        if page != nil && len(page.Section) > 1 {
+            flattened := *page
+            flattened.Section = page.Section[:1]
+            page = &flattened
+        }
+        top[path] = page
+    }
+    return updateSidebar(content, top, paths)
+}
+
+// findSidebarLink returns the first entry linking to link
+func findSidebarLink(nodes []*sidebarNode, link string) *sidebarNode {
+    for _, node := range nodes {
+        if node.Link == link {
+            return node
+        }
+        if found := findSidebarLink(node.Children, link); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+// sameSection reports whether target sits directly under the section headings named
+// by section, so that updating it in place keeps its position
+func sameSection(nodes []*sidebarNode, target *sidebarNode, section []string) bool {
+    for _, name := range section {
+        var next []*sidebarNode
+        for _, node := range nodes {
+            if node.Link == "" && node.Raw == "" && node.Title == name {
+                next = node.Children
+                break
+            }
+        }
+        if next == nil {
+            return false
+        }
+        nodes = next
+    }
+    for _, node := range nodes {
+        if node == target {
+            return true
+        }
+    }
+    return false
+}
+
+// sidebarLink returns the sidebar link of a page path
+func sidebarLink(path string) string {
+    return (&url.URL{Path: path}).EscapedPath()
+}
+
+// escapeTitle escapes brackets in a link title
+func escapeTitle(title string) string {
+    return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(title)
+}
+
+// unescapeTitle reverses escapeTitle
+func unescapeTitle(title string) string {
+    return strings.NewReplacer(`\[`, `[`, `\]`, `]`).Replace(title)
+}
+
+// withNavigation returns changes extended with the updated sidebar and navbar when
+// their maintenance is enabled and the changes affect them
+func (s *DocsifyServiceImpl) withNavigation(ctx context.Context, branch string, changes map[string]*Page) (map[string]*Page, error) {
+    files := []struct {
+        enabled bool
+        path    string
+        update  func(content string, changes map[string]*Page, paths []string) string
+    }{
+        {s.config.Sidebar, s.config.sidebarPath(), updateSidebar},
+        {s.config.Navbar, s.config.navbarPath(), updateNavbar},
+    }
+
+    all := changes
+    paths := sortedPaths(changes)
+    for _, file := range files {
+        if _, ok := changes[file.path]; !file.enabled || ok {
+            continue
+        }
+        current, err := s.readFile(ctx, branch, file.path)
+        if err != nil {
+            return nil, err
+        }
+        updated := file.update(current, changes, paths)
+        if updated == current {
+            continue
+        }
+
+        if len(all) == len(changes) {
+            all = make(map[string]*Page, len(changes)+len(files))
+            for path, page := range changes {
+                all[path] = page
+            }
+        }
+        all[file.path] = &Page{ID: file.path, Content: updated}
+    }
+    return all, nil
+}
+
+// readFile returns the content of a file on branch, or "" when it does not exist
+func (s *DocsifyServiceImpl) readFile(ctx context.Context, branch, path string) (string, error) {
+    if s.config.Backend == BackendLocal {
+        file, err := s.localFile(path)
+        if err != nil {
+            return "", err
+        }
+        content, err := ioutil.ReadFile(file)
+        if os.IsNotExist(err) {
+            return "", nil
+        }
+        return string(content), err
+    }
+
+    var result struct {
+        Content string `json:"content"`
+    }
+    fileURL := s.repoURL("/contents/%s", path)
+    if branch != "" {
+        fileURL += "?ref=" + url.QueryEscape(branch)
+    }
+    err := s.doRequest(ctx, "get "+path, "GET", fileURL, nil, &result)
+    if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == http.StatusNotFound {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    return decodeBase64(result.Content), nil
+}
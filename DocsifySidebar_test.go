@@ -0,0 +1,249 @@
+package docsify
+
+import (
+    "context"
+    "reflect"
+    "testing"
+)
+
+func TestParseSidebar(t *testing.T) {
+    tests := []struct {
+        name       string
+        content    string
+        wantHeader []string
+        wantNodes  []*sidebarNode
+    }{
+        {
+            name:       "header and nested sections",
+            content:    "<!-- docs -->\n\n- Guides\n  - [Install](guides/install.md)\n  - [Use \\[beta\\]](guides/use.md) <!-- position:2 -->\n- [Home](README.md)\n",
+            wantHeader: []string{"<!-- docs -->"},
+            wantNodes: []*sidebarNode{
+                {Title: "Guides", Children: []*sidebarNode{
+                    {Title: "Install", Link: "guides/install.md"},
+                    {Title: "Use [beta]", Link: "guides/use.md", Position: 2},
+                }},
+                {Title: "Home", Link: "README.md"},
+            },
+        },
+        {
+            name:    "tabs and over-indented items",
+            content: "* Top\n\t* [A](a.md)\n        * [B](b.md)",
+            wantNodes: []*sidebarNode{
+                {Title: "Top", Children: []*sidebarNode{
+                    {Title: "A", Link: "a.md", Children: []*sidebarNode{
+                        {Title: "B", Link: "b.md"},
+                    }},
+                }},
+            },
+        },
+        {
+            name:    "lines after the first item",
+            content: "- [Home](README.md)\n  Start here\n- Guides\n  - [Install](install.md)\n\n---\n\n[Edit on GitHub](https://example.com)\n- [FAQ](faq.md)\n",
+            wantNodes: []*sidebarNode{
+                {Title: "Home", Link: "README.md", Children: []*sidebarNode{{Raw: "Start here"}}},
+                {Title: "Guides", Children: []*sidebarNode{
+                    {Title: "Install", Link: "install.md"},
+                }},
+                {Raw: "---\n\n[Edit on GitHub](https://example.com)"},
+                {Title: "FAQ", Link: "faq.md"},
+            },
+        },
+        {
+            name:    "empty sidebar",
+            content: "",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            header, nodes := parseSidebar(tt.content)
+            if !reflect.DeepEqual(header, tt.wantHeader) {
+                t.Errorf("header = %q, want %q", header, tt.wantHeader)
+            }
+            if !reflect.DeepEqual(nodes, tt.wantNodes) {
+                t.Errorf("nodes = %s, want %s", renderSidebar(nil, nodes), renderSidebar(nil, tt.wantNodes))
+            }
+        })
+    }
+}
+
+func TestRenderSidebarRoundTrip(t *testing.T) {
+    contents := []string{
+        "- [Home](README.md)\n",
+        "<!-- docs -->\n\n- Guides\n  - [Install](guides/install.md) <!-- position:1 -->\n  - [Use \\[beta\\]](guides/use.md)\n",
+        "- [Home](README.md)\n  Start here\n\n<!-- more -->\n\n**Reference**\n\n- [API](api.md)\n\n[Edit](https://example.com)\n",
+    }
+
+    for _, content := range contents {
+        t.Run(content, func(t *testing.T) {
+            if got := renderSidebar(parseSidebar(content)); got != content {
+                t.Errorf("renderSidebar(parseSidebar(%q)) = %q", content, got)
+            }
+        })
+    }
+}
+
+func TestUpdateSidebar(t *testing.T) {
+    const sidebar = "- Guides\n  - [Install](guides/install.md) <!-- position:1 -->\n  - [Use](guides/use.md) <!-- position:3 -->\n- [Home](README.md)\n"
+
+    tests := []struct {
+        name    string
+        changes map[string]*Page
+        paths   []string
+        want    string
+    }{
+        {
+            name:    "rename in place",
+            changes: map[string]*Page{"guides/use.md": {Title: "Usage", Section: []string{"Guides"}, Position: 3}},
+            paths:   []string{"guides/use.md"},
+            want:    "- Guides\n  - [Install](guides/install.md) <!-- position:1 -->\n  - [Usage](guides/use.md) <!-- position:3 -->\n- [Home](README.md)\n",
+        },
+        {
+            name:    "insert by position",
+            changes: map[string]*Page{"guides/setup.md": {Title: "Setup", Section: []string{"Guides"}, Position: 2}},
+            paths:   []string{"guides/setup.md"},
+            want:    "- Guides\n  - [Install](guides/install.md) <!-- position:1 -->\n  - [Setup](guides/setup.md) <!-- position:2 -->\n  - [Use](guides/use.md) <!-- position:3 -->\n- [Home](README.md)\n",
+        },
+        {
+            name:    "move to a new section",
+            changes: map[string]*Page{"guides/use.md": {Title: "Use", Section: []string{"Reference", "CLI"}}},
+            paths:   []string{"guides/use.md"},
+            want:    "- Guides\n  - [Install](guides/install.md) <!-- position:1 -->\n- [Home](README.md)\n- Reference\n  - CLI\n    - [Use](guides/use.md)\n",
+        },
+        {
+            name:    "delete removes empty sections",
+            changes: map[string]*Page{},
+            paths:   []string{"guides/install.md", "guides/use.md"},
+            want:    "- [Home](README.md)\n",
+        },
+        {
+            name:    "links are escaped",
+            changes: map[string]*Page{"guides/a b.md": {Title: "A B"}},
+            paths:   []string{"guides/a b.md"},
+            want:    sidebar + "- [A B](guides/a%20b.md)\n",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := updateSidebar(sidebar, tt.changes, tt.paths); got != tt.want {
+                t.Errorf("updateSidebar() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestUpdateSidebarKeepsRawLines(t *testing.T) {
+    const sidebar = "- [Home](README.md)\n  Start here\n- Guides\n  - [Install](guides/install.md)\n\n[Edit on GitHub](https://example.com)\n"
+
+    tests := []struct {
+        name    string
+        changes map[string]*Page
+        paths   []string
+        want    string
+    }{
+        {
+            name:    "new entries go before the footer",
+            changes: map[string]*Page{"faq.md": {Title: "FAQ"}, "ref/cli.md": {Title: "CLI", Section: []string{"Reference"}}},
+            paths:   []string{"faq.md", "ref/cli.md"},
+            want:    "- [Home](README.md)\n  Start here\n- Guides\n  - [Install](guides/install.md)\n- [FAQ](faq.md)\n- Reference\n  - [CLI](ref/cli.md)\n\n[Edit on GitHub](https://example.com)\n",
+        },
+        {
+            name:    "text under an entry moves with it",
+            changes: map[string]*Page{"README.md": {Title: "Start", Section: []string{"Guides"}}},
+            paths:   []string{"README.md"},
+            want:    "- Guides\n  - [Install](guides/install.md)\n  - [Start](README.md)\n    Start here\n\n[Edit on GitHub](https://example.com)\n",
+        },
+        {
+            name:    "deleting every entry keeps the footer",
+            changes: map[string]*Page{},
+            paths:   []string{"README.md", "guides/install.md"},
+            want:    "[Edit on GitHub](https://example.com)\n",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := updateSidebar(sidebar, tt.changes, tt.paths); got != tt.want {
+                t.Errorf("updateSidebar() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestUpdateNavbar(t *testing.T) {
+    const navbar = "- Guides\n  - [Install](guides/install.md)\n- [Support](https://example.com/support)\n"
+
+    changes := map[string]*Page{
+        "guides/cli/flags.md": {Title: "Flags", Section: []string{"Guides", "CLI"}},
+        "faq.md":              {Title: "FAQ", Section: []string{"Help"}},
+    }
+    paths := []string{"faq.md", "guides/cli/flags.md", "guides/install.md"}
+    want := "- Guides\n  - [Flags](guides/cli/flags.md)\n- [Support](https://example.com/support)\n- Help\n  - [FAQ](faq.md)\n"
+
+    if got := updateNavbar(navbar, changes, paths); got != want {
+        t.Errorf("updateNavbar() = %q, want %q", got, want)
+    }
+    if changes["guides/cli/flags.md"].Section[1] != "CLI" {
+        t.Error("updateNavbar() changed the section of the page")
+    }
+}
+
+func TestNavigationCommit(t *testing.T) {
+    fake := newFakeGitHub(map[string]string{
+        "README.md":   "Old home",
+        "_sidebar.md": "- [Home](README.md)\n\n<!-- footer -->\n",
+    })
+    config := DocsifyConfig{Sidebar: true, Navbar: true, HomePage: "home", Slugs: true}
+    service, done := newFakeDocsify(fake, config)
+    defer done()
+
+    ctx := context.Background()
+    home, err := service.CreatePage(ctx, Page{ID: "home", Title: "Home", Content: "Welcome"})
+    if err != nil {
+        t.Fatalf("CreatePage(home) error = %v", err)
+    }
+    if home != "README.md" {
+        t.Errorf("CreatePage(home) = %q, want README.md", home)
+    }
+    if path, ok := service.PagePath("home"); !ok || path != "README.md" {
+        t.Errorf("PagePath(home) = %q, %v, want README.md", path, ok)
+    }
+    if _, err := service.CreatePage(ctx, Page{ID: "p1", Title: "Flags", Section: []string{"Guides", "CLI"}, Content: "--help"}); err != nil {
+        t.Fatalf("CreatePage(p1) error = %v", err)
+    }
+    // The home page stays at README.md when its section changes
+    if err := service.UpdatePage(ctx, Page{ID: "README.md", SourceID: "home", Title: "Home", Section: []string{"Guides"}, Content: "Welcome back"}); err != nil {
+        t.Fatalf("UpdatePage(home) error = %v", err)
+    }
+
+    want := map[string]string{
+        "README.md":           "Welcome back",
+        "guides/cli/flags.md": "--help",
+        "_sidebar.md":         "- Guides\n  - CLI\n    - [Flags](guides/cli/flags.md)\n  - [Home](README.md)\n\n<!-- footer -->\n",
+        "_navbar.md":          "- Guides\n  - [Flags](guides/cli/flags.md)\n  - [Home](README.md)\n",
+    }
+    if !reflect.DeepEqual(fake.files["main"], want) {
+        t.Errorf("files = %q, want %q", fake.files["main"], want)
+    }
+
+    // Each change is one commit with its navigation updates
+    var trees [][]string
+    for i, write := range fake.writes {
+        if write == "POST /git/trees" {
+            var paths []string
+            for _, entry := range fake.bodies[i]["tree"].([]interface{}) {
+                paths = append(paths, entry.(map[string]interface{})["path"].(string))
+            }
+            trees = append(trees, paths)
+        }
+    }
+    wantTrees := [][]string{
+        {"README.md", "_navbar.md"},
+        {"_navbar.md", "_sidebar.md", "guides/cli/flags.md"},
+        {"README.md", "_navbar.md", "_sidebar.md"},
+    }
+    if !reflect.DeepEqual(trees, wantTrees) {
+        t.Errorf("commits = %q, want %q", trees, wantTrees)
+    }
+}
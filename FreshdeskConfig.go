@@ -0,0 +1,27 @@
+package freshdesk
+
+// FreshdeskConfig holds the solution hierarchy settings used when syncing pages to Freshdesk
+type FreshdeskConfig struct {
+    DefaultFolderID  int64 `yaml:"default_folder_id"` // Folder for pages without a section path
+    CreateMissing    bool  `yaml:"create_missing"`    // Create categories and folders that do not exist yet
+    FolderVisibility int   `yaml:"folder_visibility"` // Visibility of created folders, defaults to 1 (all users)
+
+    PrimaryLanguage string   `yaml:"primary_language"` // Language of the article itself, defaults to en
+    Languages       []string `yaml:"languages"`        // Portal languages every page is expected to be translated into
+}
+
+// primaryLanguage returns the language code of the primary article version
+func (c FreshdeskConfig) primaryLanguage() string {
+    if c.PrimaryLanguage == "" {
+        return "en"
+    }
+    return c.PrimaryLanguage
+}
+
+// folderVisibility returns the visibility used for folders created by the sync
+func (c FreshdeskConfig) folderVisibility() int {
+    if c.FolderVisibility == 0 {
+        return 1
+    }
+    return c.FolderVisibility
+}
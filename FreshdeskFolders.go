@@ -0,0 +1,121 @@
+package freshdesk
+
+import (
+    "context"
+    "fmt"
+    "strings"
+)
+
+// resolveFolder returns the ID of the folder a page belongs in. The first element of
+// the section path names a solution category, the second a folder inside it and any
+// further elements nested subfolders. Missing levels are created when the config
+// allows it; pages without a section path go to the default folder
+func (s *FreshdeskServiceImpl) resolveFolder(ctx context.Context, section []string) (int64, error) {
+    if len(section) == 0 {
+        if s.config.DefaultFolderID == 0 {
+            return 0, fmt.Errorf("page has no section path and no default Freshdesk folder is configured")
+        }
+        return s.config.DefaultFolderID, nil
+    }
+    if len(section) < 2 {
+        return 0, fmt.Errorf("section path %q needs at least a category and a folder", strings.Join(section, " / "))
+    }
+
+    key := strings.Join(section, "\x00")
+    s.mu.Lock()
+    id, ok := s.folders[key]
+    s.mu.Unlock()
+    if ok {
+        return id, nil
+    }
+
+    categoryID, err := s.findOrCreate(ctx,
+        fmt.Sprintf("%s/api/v2/solutions/categories", s.baseURL),
+        section[0],
+        map[string]interface{}{"name": section[0]})
+    if err != nil {
+        return 0, err
+    }
+
+    id, err = s.findOrCreate(ctx,
+        fmt.Sprintf("%s/api/v2/solutions/categories/%d/folders", s.baseURL, categoryID),
+        section[1],
+        map[string]interface{}{"name": section[1], "visibility": s.config.folderVisibility()})
+    if err != nil {
+        return 0, err
+    }
+
+    for _, name := range section[2:] {
+        id, err = s.findOrCreate(ctx,
+            fmt.Sprintf("%s/api/v2/solutions/folders/%d/subfolders", s.baseURL, id),
+            name,
+            map[string]interface{}{"name": name, "visibility": s.config.folderVisibility()})
+        if err != nil {
+            return 0, err
+        }
+    }
+
+    s.mu.Lock()
+    if s.folders == nil {
+        s.folders = map[string]int64{}
+    }
+    s.folders[key] = id
+    s.mu.Unlock()
+
+    return id, nil
+}
+
+// findOrCreate looks up a category or folder by name in a listing endpoint and
+// creates it through the same endpoint when it is missing
+func (s *FreshdeskServiceImpl) findOrCreate(ctx context.Context, url, name string, create map[string]interface{}) (int64, error) {
+    items, err := s.listAll(ctx, "list "+name, url)
+    if err != nil {
+        return 0, err
+    }
+    for _, item := range items {
+        itemName, _ := item["name"].(string)
+        if strings.EqualFold(strings.TrimSpace(itemName), strings.TrimSpace(name)) {
+            id, _ := item["id"].(float64)
+            return int64(id), nil
+        }
+    }
+
+    if !s.config.CreateMissing {
+        return 0, fmt.Errorf("no %q found in Freshdesk and creating missing sections is disabled", name)
+    }
+
+    var created map[string]interface{}
+    if err := s.doRequest(ctx, "create "+name, "POST", url, create, &created); err != nil {
+        return 0, err
+    }
+    id, ok := created["id"].(float64)
+    if !ok {
+        return 0, fmt.Errorf("failed to parse ID of %q", name)
+    }
+    return int64(id), nil
+}
+
+// seoData builds the SEO metadata sent with an article
+func seoData(page Page) map[string]interface{} {
+    title := page.MetaTitle
+    if title == "" {
+        title = page.Title
+    }
+    seo := map[string]interface{}{"meta_title": title}
+    if page.MetaDescription != "" {
+        seo["meta_description"] = page.MetaDescription
+    }
+    if len(page.Labels) > 0 {
+        seo["meta_keywords"] = strings.Join(page.Labels, ", ")
+    }
+    return seo
+}
+
+// pageTags returns the page labels as Freshdesk tags, never nil so that removed
+// labels are cleared on update
+func pageTags(page Page) []string {
+    if page.Labels == nil {
+        return []string{}
+    }
+    return page.Labels
+}
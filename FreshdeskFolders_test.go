@@ -0,0 +1,120 @@
+package freshdesk
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeSolutions serves category and folder listings and records what is created
+type fakeSolutions struct {
+    listings map[string][]map[string]interface{} // Listing path to its items
+    created  []string                            // "path name" of every item created
+    next     int
+}
+
+func (f *fakeSolutions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/api/v2/solutions")
+    if r.Method == "GET" {
+        items := f.listings[path]
+        if items == nil {
+            items = []map[string]interface{}{}
+        }
+        json.NewEncoder(w).Encode(items)
+        return
+    }
+
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    f.created = append(f.created, path+" "+body["name"].(string))
+    f.next++
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(map[string]interface{}{"id": 100 + f.next})
+}
+
+func TestResolveFolder(t *testing.T) {
+    fake := &fakeSolutions{listings: map[string][]map[string]interface{}{
+        "/categories": {{"id": 1, "name": "Other"}, {"id": 2, "name": " guides "}},
+    }}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewFreshdeskServiceWithConfig(server.URL, "key", FreshdeskConfig{CreateMissing: true})
+    ctx := context.Background()
+    id, err := s.resolveFolder(ctx, []string{"Guides", "Setup", "Advanced"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if id != 102 {
+        t.Errorf("resolveFolder() = %d, want the created subfolder 102", id)
+    }
+    want := []string{"/categories/2/folders Setup", "/folders/101/subfolders Advanced"}
+    if !reflect.DeepEqual(fake.created, want) {
+        t.Errorf("created = %q, want %q", fake.created, want)
+    }
+
+    // Resolved paths are cached
+    fake.listings = nil
+    if again, err := s.resolveFolder(ctx, []string{"Guides", "Setup", "Advanced"}); err != nil || again != id {
+        t.Errorf("resolveFolder() again = %d, %v, want %d", again, err, id)
+    }
+}
+
+func TestResolveFolderErrors(t *testing.T) {
+    server := httptest.NewServer(&fakeSolutions{})
+    defer server.Close()
+
+    tests := []struct {
+        name    string
+        config  FreshdeskConfig
+        section []string
+        want    int64
+        wantErr bool
+    }{
+        {name: "default folder", config: FreshdeskConfig{DefaultFolderID: 5}, want: 5},
+        {name: "no default folder", wantErr: true},
+        {name: "category only", section: []string{"Guides"}, wantErr: true},
+        {name: "missing and not created", section: []string{"Guides", "Setup"}, wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s := NewFreshdeskServiceWithConfig(server.URL, "key", tt.config)
+            got, err := s.resolveFolder(context.Background(), tt.section)
+            if (err != nil) != tt.wantErr || got != tt.want {
+                t.Errorf("resolveFolder(%q) = %d, %v, want %d, error %v", tt.section, got, err, tt.want, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestSEOData(t *testing.T) {
+    tests := []struct {
+        name string
+        page Page
+        want map[string]interface{}
+    }{
+        {name: "title falls back to the page title", page: Page{Title: "Setup"}, want: map[string]interface{}{"meta_title": "Setup"}},
+        {
+            name: "meta fields and keywords",
+            page: Page{Title: "Setup", MetaTitle: "Set up", MetaDescription: "How to", Labels: []string{"a", "b"}},
+            want: map[string]interface{}{"meta_title": "Set up", "meta_description": "How to", "meta_keywords": "a, b"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := seoData(tt.page); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("seoData() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+
+    if tags := pageTags(Page{}); tags == nil || len(tags) != 0 {
+        t.Errorf("pageTags() = %#v, want an empty list so removed tags are cleared", tags)
+    }
+}
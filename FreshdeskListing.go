@@ -0,0 +1,120 @@
+package freshdesk
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// solutionsPerPage is the largest page size the solutions API accepts
+const solutionsPerPage = 100
+
+// ListPages lists the articles of every solution folder. Freshdesk has no endpoint
+// listing all articles, so the folders are read first and their articles then paged
+// through with page and per_page. Each article's folder is reported as its parent
+func (s *FreshdeskServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    var folders []int64
+    loaded := false
+
+    // The cursor holds the index of the folder being listed and the next page number
+    return listing.New(ctx, func(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+        if !loaded {
+            var err error
+            if folders, err = s.listFolders(ctx); err != nil {
+                return nil, "", err
+            }
+            loaded = true
+        }
+        if len(folders) == 0 {
+            return nil, "", nil
+        }
+
+        index, page := 0, 1
+        if cursor != "" {
+            if _, err := fmt.Sscanf(cursor, "%d:%d", &index, &page); err != nil {
+                return nil, "", fmt.Errorf("invalid listing cursor %q", cursor)
+            }
+        }
+
+        var articles []struct {
+            ID        int64     `json:"id"`
+            Title     string    `json:"title"`
+            UpdatedAt time.Time `json:"updated_at"`
+            FolderID  int64     `json:"folder_id"`
+        }
+        url := fmt.Sprintf("%s/api/v2/solutions/folders/%d/articles?page=%d&per_page=%d", s.baseURL, folders[index], page, solutionsPerPage)
+        if err := s.doRequest(ctx, "list articles", "GET", url, nil, &articles); err != nil {
+            return nil, "", err
+        }
+
+        summaries := make([]listing.Summary, 0, len(articles))
+        for _, article := range articles {
+            summaries = append(summaries, listing.Summary{
+                ID:       strconv.FormatInt(article.ID, 10),
+                Title:    article.Title,
+                Updated:  article.UpdatedAt,
+                ParentID: strconv.FormatInt(article.FolderID, 10),
+            })
+        }
+
+        if len(articles) == solutionsPerPage {
+            return summaries, fmt.Sprintf("%d:%d", index, page+1), nil
+        }
+        if index+1 < len(folders) {
+            return summaries, fmt.Sprintf("%d:%d", index+1, 1), nil
+        }
+        return summaries, "", nil
+    })
+}
+
+// listFolders returns the IDs of all solution folders, including subfolders
+func (s *FreshdeskServiceImpl) listFolders(ctx context.Context) ([]int64, error) {
+    categories, err := s.listAll(ctx, "list categories", fmt.Sprintf("%s/api/v2/solutions/categories", s.baseURL))
+    if err != nil {
+        return nil, err
+    }
+
+    var folders []int64
+    var walk func(url string) error
+    walk = func(url string) error {
+        items, err := s.listAll(ctx, "list folders", url)
+        if err != nil {
+            return err
+        }
+        for _, item := range items {
+            id, _ := item["id"].(float64)
+            folders = append(folders, int64(id))
+            if err := walk(fmt.Sprintf("%s/api/v2/solutions/folders/%d/subfolders", s.baseURL, int64(id))); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    for _, category := range categories {
+        id, _ := category["id"].(float64)
+        if err := walk(fmt.Sprintf("%s/api/v2/solutions/categories/%d/folders", s.baseURL, int64(id))); err != nil {
+            return nil, err
+        }
+    }
+    return folders, nil
+}
+
+// listAll reads every page of a solutions listing endpoint, such as the categories or
+// the folders of a category, which Freshdesk returns 30 at a time unless asked for more
+func (s *FreshdeskServiceImpl) listAll(ctx context.Context, action, url string) ([]map[string]interface{}, error) {
+    var all []map[string]interface{}
+    for page := 1; ; page++ {
+        var items []map[string]interface{}
+        if err := s.doRequest(ctx, action, "GET", fmt.Sprintf("%s?page=%d&per_page=%d", url, page, solutionsPerPage), nil, &items); err != nil {
+            return nil, err
+        }
+        all = append(all, items...)
+        if len(items) < solutionsPerPage {
+            return all, nil
+        }
+    }
+}
@@ -0,0 +1,93 @@
+package freshdesk
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+)
+
+// fakeSolutionPages serves solutions listings a page at a time by page and per_page,
+// recording the article listings requested as "folder page"
+type fakeSolutionPages struct {
+    listings map[string][]map[string]interface{} // Listing path to its items
+    requests []string
+}
+
+func (f *fakeSolutionPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/api/v2/solutions")
+    page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+    perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+    if strings.HasSuffix(path, "/articles") {
+        folder := strings.TrimSuffix(strings.TrimPrefix(path, "/folders/"), "/articles")
+        f.requests = append(f.requests, fmt.Sprintf("%s %d", folder, page))
+    }
+
+    items := []map[string]interface{}{}
+    for i := (page - 1) * perPage; i < len(f.listings[path]) && i < page*perPage; i++ {
+        items = append(items, f.listings[path][i])
+    }
+    json.NewEncoder(w).Encode(items)
+}
+
+// solutionArticles returns count articles of a folder, numbered from first
+func solutionArticles(folder, first, count int) []map[string]interface{} {
+    var articles []map[string]interface{}
+    for i := first; i < first+count; i++ {
+        articles = append(articles, map[string]interface{}{
+            "id":         i,
+            "title":      fmt.Sprintf("Article %d", i),
+            "updated_at": "2024-03-01T12:00:00Z",
+            "folder_id":  folder,
+        })
+    }
+    return articles
+}
+
+func TestListPages(t *testing.T) {
+    fake := &fakeSolutionPages{listings: map[string][]map[string]interface{}{
+        "/categories":            {{"id": 1}, {"id": 2}},
+        "/categories/1/folders":  {{"id": 10}},
+        "/folders/10/subfolders": {{"id": 11}},
+        "/categories/2/folders":  {{"id": 20}},
+        "/folders/10/articles":   solutionArticles(10, 1, solutionsPerPage+1),
+        "/folders/11/articles":   solutionArticles(11, 1000, solutionsPerPage),
+        "/folders/20/articles":   solutionArticles(20, 2000, 1),
+    }}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewFreshdeskService(server.URL, "key")
+    got, err := s.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if len(got) != 2*solutionsPerPage+2 {
+        t.Fatalf("ListPages() listed %d articles, want %d", len(got), 2*solutionsPerPage+2)
+    }
+    last := got[len(got)-1]
+    if last.ID != "2000" || last.Title != "Article 2000" || last.ParentID != "20" || !last.Updated.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+        t.Errorf("last article = %+v, want article 2000 in folder 20", last)
+    }
+    // A full page is followed by another, even when it turns out empty
+    if want := []string{"10 1", "10 2", "11 1", "11 2", "20 1"}; !reflect.DeepEqual(fake.requests, want) {
+        t.Errorf("article listings = %q, want %q", fake.requests, want)
+    }
+}
+
+func TestListPagesWithoutFolders(t *testing.T) {
+    server := httptest.NewServer(&fakeSolutionPages{})
+    defer server.Close()
+
+    got, err := NewFreshdeskService(server.URL, "key").ListPages(context.Background()).All()
+    if err != nil || len(got) != 0 {
+        t.Errorf("ListPages() = %v, %v, want no articles", got, err)
+    }
+}
@@ -1,6 +1,10 @@
 package freshdesk
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // FreshdeskService defines the methods for interacting with Freshdesk
 type FreshdeskService interface {
@@ -8,4 +12,5 @@ type FreshdeskService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
@@ -7,12 +7,18 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "sync"
 )
 
 // FreshdeskServiceImpl is the implementation of the FreshdeskService interface
 type FreshdeskServiceImpl struct {
     baseURL    string
     apiKey      string
+    config      FreshdeskConfig
+
+    mu      sync.Mutex
+    folders map[string]int64            // Resolved folder IDs by section path
+    results map[string]map[string]error // operation -> translation language -> error
 }
 
 // NewFreshdeskService creates a new instance of FreshdeskService
@@ -20,13 +26,26 @@ func NewFreshdeskService(baseURL, apiKey string) *FreshdeskServiceImpl {
     return &FreshdeskServiceImpl{baseURL: baseURL, apiKey: apiKey}
 }
 
-// CreatePage creates a new page in Freshdesk
+// NewFreshdeskServiceWithConfig creates a new instance of FreshdeskService that places
+// articles in solution categories and folders according to the given settings
+func NewFreshdeskServiceWithConfig(baseURL, apiKey string, config FreshdeskConfig) *FreshdeskServiceImpl {
+    return &FreshdeskServiceImpl{baseURL: baseURL, apiKey: apiKey, config: config}
+}
+
+// CreatePage creates a new article in the Freshdesk folder matching the page's section path
 func (s *FreshdeskServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
-    url := fmt.Sprintf("%s/api/v2/solutions/articles", s.baseURL)
+    folderID, err := s.resolveFolder(ctx, page.Section)
+    if err != nil {
+        return "", err
+    }
+
+    url := fmt.Sprintf("%s/api/v2/solutions/folders/%d/articles", s.baseURL, folderID)
     reqBody, _ := json.Marshal(map[string]interface{}{
         "title":   page.Title,
         "description": page.Content,
         "status":  2, // Published
+        "tags":     pageTags(page),
+        "seo_data": seoData(page),
     })
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
@@ -53,17 +72,31 @@ func (s *FreshdeskServiceImpl) CreatePage(ctx context.Context, page Page) (strin
         return "", fmt.Errorf("failed to parse article ID")
     }
 
-    return fmt.Sprintf("%.0f", articleID), nil
+    id := fmt.Sprintf("%.0f", articleID)
+    if err := s.syncTranslations(ctx, "create", id, page); err != nil {
+        return id, err
+    }
+
+    return id, nil
 }
 
-// UpdatePage updates an existing page in Freshdesk
+// UpdatePage updates an existing page in Freshdesk, moving it to another folder
+// when its section path has changed
 func (s *FreshdeskServiceImpl) UpdatePage(ctx context.Context, page Page) error {
     url := fmt.Sprintf("%s/api/v2/solutions/articles/%s", s.baseURL, page.ID)
 
+    folderID, err := s.resolveFolder(ctx, page.Section)
+    if err != nil {
+        return err
+    }
+
     reqBody, _ := json.Marshal(map[string]interface{}{
         "title":       page.Title,
         "description": page.Content,
         "status":      2, // Published
+        "folder_id":   folderID,
+        "tags":        pageTags(page),
+        "seo_data":    seoData(page),
     })
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
@@ -81,7 +114,7 @@ func (s *FreshdeskServiceImpl) UpdatePage(ctx context.Context, page Page) error
         return fmt.Errorf("failed to update page: %s - %s", resp.Status, body)
     }
 
-    return nil
+    return s.syncTranslations(ctx, "update", page.ID, page)
 }
 
 // DeletePage deletes a page in Freshdesk
@@ -105,6 +138,11 @@ func (s *FreshdeskServiceImpl) DeletePage(ctx context.Context, id string) error
     return nil
 }
 
+// ReadsLabels reports that GetPage returns the tags of an article as its labels
+func (s *FreshdeskServiceImpl) ReadsLabels() bool {
+    return true
+}
+
 // GetPage retrieves a page from Freshdesk
 func (s *FreshdeskServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
     url := fmt.Sprintf("%s/api/v2/solutions/articles/%s", s.baseURL, id)
@@ -126,14 +164,87 @@ func (s *FreshdeskServiceImpl) GetPage(ctx context.Context, id string) (Page, er
     var result map[string]interface{}
     json.NewDecoder(resp.Body).Decode(&result)
 
-    article := result["article"].(map[string]interface{})
+    // The API returns the article itself; older responses wrapped it in "article"
+    article := result
+    if wrapped, ok := result["article"].(map[string]interface{}); ok {
+        article = wrapped
+    }
     pageID := article["id"].(float64)
     title := article["title"].(string)
     content := article["description"].(string)
 
+    var tags []string
+    if items, ok := article["tags"].([]interface{}); ok {
+        for _, item := range items {
+            if tag, ok := item.(string); ok {
+                tags = append(tags, tag)
+            }
+        }
+    }
+    seo, _ := article["seo_data"].(map[string]interface{})
+    metaTitle, _ := seo["meta_title"].(string)
+    metaDescription, _ := seo["meta_description"].(string)
+
     return Page{
-        ID:      fmt.Sprintf("%.0f", pageID),
-        Title:   title,
-        Content: content,
+        ID:              fmt.Sprintf("%.0f", pageID),
+        Title:           title,
+        Content:         content,
+        Labels:          tags,
+        MetaTitle:       metaTitle,
+        MetaDescription: metaDescription,
     }, nil
 }
+
+// doRequest sends an authenticated JSON request to the Freshdesk API and decodes
+// the response into out when it is not nil
+func (s *FreshdeskServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.SetBasicAuth(s.apiKey, "X")
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return &apiError{Action: action, StatusCode: resp.StatusCode, Status: resp.Status, Body: string(respBody)}
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
+
+// apiError is returned by doRequest when Freshdesk responds with a non-2xx status
+type apiError struct {
+    Action     string
+    StatusCode int
+    Status     string
+    Body       string
+}
+
+func (e *apiError) Error() string {
+    return fmt.Sprintf("failed to %s: %s - %s", e.Action, e.Status, e.Body)
+}
+
+// isNotFound reports whether err is a 404 response from Freshdesk
+func isNotFound(err error) bool {
+    apiErr, ok := err.(*apiError)
+    return ok && apiErr.StatusCode == http.StatusNotFound
+}
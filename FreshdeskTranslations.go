@@ -0,0 +1,175 @@
+package freshdesk
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// MissingTranslationsError reports a configured language that a page has no variant
+// for. The article and every available translation have still been written, so it
+// is reported per language through TargetResults rather than failing the write
+type MissingTranslationsError struct {
+    ArticleID string
+    Languages []string
+}
+
+func (e *MissingTranslationsError) Error() string {
+    return fmt.Sprintf("article %s is missing translations for: %s", e.ArticleID, strings.Join(e.Languages, ", "))
+}
+
+// syncTranslations writes every locale variant of the page as a Freshdesk
+// translation of the article, creating translations that do not exist yet. The
+// outcome for each language, including configured languages without a variant, is
+// recorded under op for TargetResults
+func (s *FreshdeskServiceImpl) syncTranslations(ctx context.Context, op, articleID string, page Page) error {
+    primary := s.config.primaryLanguage()
+    results := map[string]error{}
+    defer s.recordResults(op, results)
+
+    languages := make([]string, 0, len(page.Translations))
+    for language := range page.Translations {
+        if !strings.EqualFold(language, primary) {
+            languages = append(languages, language)
+        }
+    }
+    sort.Strings(languages)
+
+    for _, language := range languages {
+        err := s.writeTranslation(ctx, articleID, language, page.Translations[language])
+        results[language] = err
+        if err != nil {
+            return err
+        }
+    }
+
+    for _, language := range s.config.Languages {
+        if strings.EqualFold(language, primary) || hasTranslation(page, language) {
+            continue
+        }
+        results[language] = &MissingTranslationsError{ArticleID: articleID, Languages: []string{language}}
+    }
+
+    return nil
+}
+
+// hasTranslation reports whether a page has a variant for a language, ignoring case
+// as Freshdesk language codes do
+func hasTranslation(page Page, language string) bool {
+    for variant := range page.Translations {
+        if strings.EqualFold(variant, language) {
+            return true
+        }
+    }
+    return false
+}
+
+// recordResults replaces the per-language results of an operation
+func (s *FreshdeskServiceImpl) recordResults(op string, results map[string]error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.results == nil {
+        s.results = map[string]map[string]error{}
+    }
+    s.results[op] = results
+}
+
+// TargetResults reports the outcome of the latest create and update per translation
+// language, keyed by operation and then language; a nil error means the translation
+// was written, and a MissingTranslationsError that the page has no variant for it
+func (s *FreshdeskServiceImpl) TargetResults() map[string]map[string]error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    results := make(map[string]map[string]error, len(s.results))
+    for op, languages := range s.results {
+        results[op] = make(map[string]error, len(languages))
+        for language, err := range languages {
+            results[op][language] = err
+        }
+    }
+    return results
+}
+
+// writeTranslation updates one language version of an article, creating it when
+// Freshdesk does not have it yet
+func (s *FreshdeskServiceImpl) writeTranslation(ctx context.Context, articleID, language string, variant Page) error {
+    url := fmt.Sprintf("%s/api/v2/solutions/articles/%s/%s", s.baseURL, articleID, language)
+    translation := map[string]interface{}{
+        "title":       variant.Title,
+        "description": variant.Content,
+        "status":      2, // Published
+        "tags":        pageTags(variant),
+        "seo_data":    seoData(variant),
+    }
+
+    err := s.doRequest(ctx, "update "+language+" translation", "PUT", url, translation, nil)
+    if !isNotFound(err) {
+        return err
+    }
+
+    // A translated article can only live in a category and folder translated into the same language
+    if err := s.ensureFolderTranslated(ctx, articleID, language); err != nil {
+        return err
+    }
+    return s.doRequest(ctx, "create "+language+" translation", "POST", url, translation, nil)
+}
+
+// ensureFolderTranslated creates translations of the article's folder and category
+// in the given language, reusing their primary names, when they are missing
+func (s *FreshdeskServiceImpl) ensureFolderTranslated(ctx context.Context, articleID, language string) error {
+    var article map[string]interface{}
+    if err := s.doRequest(ctx, "get article", "GET", fmt.Sprintf("%s/api/v2/solutions/articles/%s", s.baseURL, articleID), nil, &article); err != nil {
+        return err
+    }
+    categoryID, _ := article["category_id"].(float64)
+    folderID, _ := article["folder_id"].(float64)
+
+    for _, level := range []string{
+        fmt.Sprintf("%s/api/v2/solutions/categories/%.0f", s.baseURL, categoryID),
+        fmt.Sprintf("%s/api/v2/solutions/folders/%.0f", s.baseURL, folderID),
+    } {
+        err := s.doRequest(ctx, "get "+language+" translation", "GET", level+"/"+language, nil, nil)
+        if err == nil {
+            continue
+        }
+        if !isNotFound(err) {
+            return err
+        }
+
+        var primary map[string]interface{}
+        if err := s.doRequest(ctx, "get section", "GET", level, nil, &primary); err != nil {
+            return err
+        }
+        name, _ := primary["name"].(string)
+        if err := s.doRequest(ctx, "create "+language+" section translation", "POST", level+"/"+language, map[string]interface{}{"name": name}, nil); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// GetPageTranslation retrieves one language version of a Freshdesk article
+func (s *FreshdeskServiceImpl) GetPageTranslation(ctx context.Context, id, language string) (Page, error) {
+    if strings.EqualFold(language, s.config.primaryLanguage()) {
+        return s.GetPage(ctx, id)
+    }
+
+    var article map[string]interface{}
+    url := fmt.Sprintf("%s/api/v2/solutions/articles/%s/%s", s.baseURL, id, language)
+    if err := s.doRequest(ctx, "get "+language+" translation", "GET", url, nil, &article); err != nil {
+        return Page{}, err
+    }
+
+    title, _ := article["title"].(string)
+    content, _ := article["description"].(string)
+
+    return Page{
+        ID:      id,
+        Title:   title,
+        Content: content,
+        Locale:  language,
+    }, nil
+}
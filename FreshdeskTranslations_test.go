@@ -0,0 +1,96 @@
+package freshdesk
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeTranslations serves article 7 in folder 3 of category 1. Only the paths in
+// existing are found; writes are recorded as "METHOD path"
+type fakeTranslations struct {
+    existing map[string]bool
+    writes   []string
+}
+
+func (f *fakeTranslations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/api/v2/solutions")
+    if r.Method != "GET" {
+        f.writes = append(f.writes, r.Method+" "+path)
+        if r.Method == "PUT" && !f.existing[path] {
+            http.NotFound(w, r)
+            return
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{})
+        return
+    }
+
+    switch {
+    case path == "/articles/7":
+        json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "category_id": 1, "folder_id": 3})
+    case path == "/categories/1" || path == "/folders/3":
+        json.NewEncoder(w).Encode(map[string]interface{}{"name": "Guides"})
+    case f.existing[path]:
+        json.NewEncoder(w).Encode(map[string]interface{}{"title": "Hallo", "description": "<p>Hallo</p>"})
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func TestSyncTranslations(t *testing.T) {
+    fake := &fakeTranslations{existing: map[string]bool{"/articles/7/de": true, "/folders/3/fr": true}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewFreshdeskServiceWithConfig(server.URL, "key", FreshdeskConfig{Languages: []string{"en", "de", "fr", "es"}})
+    page := Page{ID: "7", Translations: map[string]Page{
+        "EN": {Title: "Hello"},
+        "de": {Title: "Hallo"},
+        "fr": {Title: "Bonjour"},
+    }}
+    if err := s.syncTranslations(context.Background(), "update", "7", page); err != nil {
+        t.Fatal(err)
+    }
+
+    want := []string{
+        "PUT /articles/7/de",
+        "PUT /articles/7/fr",
+        "POST /categories/1/fr",
+        "POST /articles/7/fr",
+    }
+    if !reflect.DeepEqual(fake.writes, want) {
+        t.Errorf("writes = %q, want %q", fake.writes, want)
+    }
+
+    results := s.TargetResults()["update"]
+    if results["de"] != nil || results["fr"] != nil {
+        t.Errorf("TargetResults() = %v, want de and fr written", results)
+    }
+    if _, ok := results["es"].(*MissingTranslationsError); !ok {
+        t.Errorf("TargetResults()[es] = %v, want a missing translation", results["es"])
+    }
+    if _, ok := results["en"]; ok {
+        t.Errorf("TargetResults() reports the primary language")
+    }
+}
+
+func TestGetPageTranslation(t *testing.T) {
+    server := httptest.NewServer(&fakeTranslations{existing: map[string]bool{"/articles/7/de": true}})
+    defer server.Close()
+
+    s := NewFreshdeskServiceWithConfig(server.URL, "key", FreshdeskConfig{})
+    page, err := s.GetPageTranslation(context.Background(), "7", "de")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := (Page{ID: "7", Title: "Hallo", Content: "<p>Hallo</p>", Locale: "de"}); !reflect.DeepEqual(page, want) {
+        t.Errorf("GetPageTranslation() = %+v, want %+v", page, want)
+    }
+    if _, err := s.GetPageTranslation(context.Background(), "7", "fr"); !isNotFound(err) {
+        t.Errorf("GetPageTranslation() of a missing language = %v, want not found", err)
+    }
+}
@@ -0,0 +1,84 @@
+package guru
+
+import (
+    "context"
+    "fmt"
+    "strings"
+)
+
+// boardIDs returns the boards a card is added to. Boards configured for the page win;
+// with section boards enabled the last element of the section path names the board,
+// and otherwise the default boards are used
+func (s *GuruServiceImpl) boardIDs(ctx context.Context, page Page, settings GuruPageConfig) ([]string, error) {
+    if override, ok := s.config.Pages[page.CanonicalID()]; ok && override.BoardIDs != nil {
+        return override.BoardIDs, nil
+    }
+    if !s.config.SectionBoards || len(page.Section) == 0 {
+        return settings.BoardIDs, nil
+    }
+
+    id, err := s.resolveBoard(ctx, settings.CollectionID, page.Section[len(page.Section)-1])
+    if err != nil {
+        return nil, err
+    }
+    return []string{id}, nil
+}
+
+// resolveBoard returns the ID of the board with the given title in a collection,
+// creating it when it is missing and the config allows it
+func (s *GuruServiceImpl) resolveBoard(ctx context.Context, collectionID, title string) (string, error) {
+    key := collectionID + "\x00" + strings.ToLower(strings.TrimSpace(title))
+    s.mu.Lock()
+    id, ok := s.boards[key]
+    s.mu.Unlock()
+    if ok {
+        return id, nil
+    }
+
+    var boards []struct {
+        ID         string `json:"id"`
+        Title      string `json:"title"`
+        Collection struct {
+            ID string `json:"id"`
+        } `json:"collection"`
+    }
+    url := fmt.Sprintf("%s/v1/boards", s.baseURL)
+    if err := s.doRequest(ctx, "list boards", "GET", url, nil, &boards); err != nil {
+        return "", err
+    }
+    for _, board := range boards {
+        if collectionID != "" && board.Collection.ID != collectionID {
+            continue
+        }
+        if strings.EqualFold(strings.TrimSpace(board.Title), strings.TrimSpace(title)) {
+            id = board.ID
+            break
+        }
+    }
+
+    if id == "" {
+        if !s.config.CreateMissingBoards {
+            return "", fmt.Errorf("no Guru board %q found and creating missing boards is disabled", title)
+        }
+        var created struct {
+            ID string `json:"id"`
+        }
+        body := map[string]interface{}{"title": title, "collection": map[string]interface{}{"id": collectionID}}
+        if err := s.doRequest(ctx, "create board "+title, "POST", url, body, &created); err != nil {
+            return "", err
+        }
+        if created.ID == "" {
+            return "", fmt.Errorf("failed to parse ID of board %q", title)
+        }
+        id = created.ID
+    }
+
+    s.mu.Lock()
+    if s.boards == nil {
+        s.boards = map[string]string{}
+    }
+    s.boards[key] = id
+    s.mu.Unlock()
+
+    return id, nil
+}
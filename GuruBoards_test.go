@@ -0,0 +1,77 @@
+package guru
+
+import (
+    "context"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+)
+
+func TestSectionBoards(t *testing.T) {
+    tests := []struct {
+        name    string
+        create  bool
+        section []string
+        want    string
+        writes  []string
+        wantErr bool
+    }{
+        {"existing board", false, []string{"Guides", "install"}, "b-install", nil, false},
+        {"board of other collection", true, []string{"Billing"}, "board-3", []string{"POST /v1/boards", "POST /v1/cards/extended"}, false},
+        {"missing board", false, []string{"Billing"}, "", nil, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGuru()
+            fake.boards = []map[string]interface{}{
+                {"id": "b-install", "title": "Install", "collection": map[string]interface{}{"id": "docs"}},
+                {"id": "b-billing", "title": "Billing", "collection": map[string]interface{}{"id": "other"}},
+            }
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            config := GuruConfig{CollectionID: "docs", SectionBoards: true, CreateMissingBoards: tt.create}
+            service := NewGuruServiceWithConfig(server.URL, "key", config)
+            id, err := service.CreatePage(context.Background(), Page{ID: "p1", Title: "Page", Section: tt.section})
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatal("CreatePage() error = nil, want missing board")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("CreatePage() error = %v", err)
+            }
+
+            want := []interface{}{map[string]interface{}{"id": tt.want}}
+            if got := fake.cards[id]["boards"]; !reflect.DeepEqual(got, want) {
+                t.Errorf("boards = %v, want %v", got, want)
+            }
+            if tt.writes == nil {
+                tt.writes = []string{"POST /v1/cards/extended"}
+            }
+            if !reflect.DeepEqual(fake.writes, tt.writes) {
+                t.Errorf("writes = %q, want %q", fake.writes, tt.writes)
+            }
+        })
+    }
+}
+
+func TestResolveBoardIsCached(t *testing.T) {
+    fake := newFakeGuru()
+    fake.boards = []map[string]interface{}{{"id": "b1", "title": "FAQ", "collection": map[string]interface{}{"id": "docs"}}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    service := NewGuruServiceWithConfig(server.URL, "key", GuruConfig{CollectionID: "docs"})
+    if _, err := service.resolveBoard(context.Background(), "docs", "FAQ"); err != nil {
+        t.Fatalf("resolveBoard() error = %v", err)
+    }
+    server.Close()
+
+    // A second lookup, even spelled differently, must not reach the closed server
+    id, err := service.resolveBoard(context.Background(), "docs", " faq ")
+    if err != nil || id != "b1" {
+        t.Errorf("resolveBoard() = %q, %v, want b1", id, err)
+    }
+}
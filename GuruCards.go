@@ -0,0 +1,164 @@
+package guru
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "html"
+    "io/ioutil"
+    "net/http"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// Verification states reported in Page.Status for Guru cards
+const (
+    StatusVerified   = "verified"
+    StatusUnverified = "unverified"
+)
+
+// cardPayload builds the extended card body for a page, including its collection,
+// boards and verification settings
+func (s *GuruServiceImpl) cardPayload(ctx context.Context, page Page) (map[string]interface{}, error) {
+    settings := s.config.page(page.CanonicalID())
+
+    boardIDs, err := s.boardIDs(ctx, page, settings)
+    if err != nil {
+        return nil, err
+    }
+    boards := []map[string]interface{}{}
+    for _, id := range boardIDs {
+        boards = append(boards, map[string]interface{}{"id": id})
+    }
+
+    payload := map[string]interface{}{
+        "preferredPhrase":      page.Title,
+        "content":              page.Content,
+        "shareStatus":          "TEAM",
+        "collection":           map[string]interface{}{"id": settings.CollectionID},
+        "boards":               boards,
+        "verificationInterval": settings.VerificationInterval,
+    }
+
+    switch {
+    case settings.Verifier != "":
+        payload["verifiers"] = []map[string]interface{}{{
+            "type": "user",
+            "user": map[string]interface{}{"email": settings.Verifier},
+        }}
+    case settings.VerifierGroupID != "":
+        payload["verifiers"] = []map[string]interface{}{{
+            "type":      "user-group",
+            "userGroup": map[string]interface{}{"id": settings.VerifierGroupID},
+        }}
+    }
+
+    return payload, nil
+}
+
+// pageFromCard converts an extended card returned by the Guru API
+func pageFromCard(card map[string]interface{}) Page {
+    page := Page{}
+    page.ID, _ = card["id"].(string)
+    page.Title, _ = card["preferredPhrase"].(string)
+    page.Content, _ = card["content"].(string)
+
+    tags, _ := card["tags"].([]interface{})
+    for _, tag := range tags {
+        if value, _ := tag.(map[string]interface{})["value"].(string); value != "" {
+            page.Labels = append(page.Labels, value)
+        }
+    }
+
+    if modified, ok := card["lastModified"].(string); ok {
+        page.Timestamp, _ = time.Parse(time.RFC3339, modified)
+    }
+
+    // Guru reports TRUSTED for verified cards and NEEDS_VERIFICATION otherwise
+    if state, _ := card["verificationState"].(string); state == "TRUSTED" {
+        page.Status = StatusVerified
+    } else {
+        page.Status = StatusUnverified
+    }
+
+    return page
+}
+
+var (
+    // Markdown images and links, compared by their text only since Guru keeps the
+    // image source and link target in attributes
+    cardImagePattern = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+    cardLinkPattern  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+    // Ordered list markers, which HTML lists do not spell out
+    cardNumberPattern = regexp.MustCompile(`(?m)^\s*\d+\.\s`)
+    // HTML tags and Markdown syntax characters
+    cardMarkupPattern = regexp.MustCompile(`<[^>]*>|[\\#*_>\[\]()|` + "`" + `~-]`)
+)
+
+// sameContent reports whether the content Guru stores for a card, which it returns as
+// HTML, says the same as Markdown content once markup and spacing are ignored
+func sameContent(stored, markdown string) bool {
+    return plainText(stored) == plainText(markdown)
+}
+
+// plainText reduces HTML or Markdown content to its words separated by single spaces
+func plainText(content string) string {
+    content = cardImagePattern.ReplaceAllString(content, " ")
+    content = cardLinkPattern.ReplaceAllString(content, "$1")
+    content = cardNumberPattern.ReplaceAllString(content, " ")
+    content = html.UnescapeString(cardMarkupPattern.ReplaceAllString(content, " "))
+    return strings.Join(strings.Fields(content), " ")
+}
+
+// reverifyCard applies the configured re-verification behaviour after the content
+// of a card has changed
+func (s *GuruServiceImpl) reverifyCard(ctx context.Context, id string) error {
+    switch s.config.reverify() {
+    case ReverifyVerify:
+        url := fmt.Sprintf("%s/v1/cards/%s/verify", s.baseURL, id)
+        return s.doRequest(ctx, "verify card", "PUT", url, nil, nil)
+    case ReverifyUnverify:
+        url := fmt.Sprintf("%s/v1/cards/%s/unverify", s.baseURL, id)
+        return s.doRequest(ctx, "unverify card", "POST", url, nil, nil)
+    }
+    return nil
+}
+
+// doRequest sends an authenticated JSON request to the Guru API and decodes the
+// response into out when it is not nil
+func (s *GuruServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil && resp.StatusCode != http.StatusNoContent {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
@@ -0,0 +1,172 @@
+package guru
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeGuru serves cards and boards. Writes are recorded as "METHOD path"
+type fakeGuru struct {
+    cards  map[string]map[string]interface{} // Extended cards by ID
+    boards []map[string]interface{}
+    writes []string
+}
+
+func newFakeGuru() *fakeGuru {
+    return &fakeGuru{cards: map[string]map[string]interface{}{}}
+}
+
+func (f *fakeGuru) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    if r.Method != "GET" {
+        f.writes = append(f.writes, r.Method+" "+r.URL.Path)
+    }
+
+    parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+    switch {
+    case r.URL.Path == "/v1/boards" && r.Method == "GET":
+        json.NewEncoder(w).Encode(f.boards)
+    case r.URL.Path == "/v1/boards":
+        body["id"] = fmt.Sprintf("board-%d", len(f.boards)+1)
+        f.boards = append(f.boards, body)
+        json.NewEncoder(w).Encode(body)
+    case r.URL.Path == "/v1/cards/extended":
+        body["id"] = fmt.Sprintf("card-%d", len(f.cards)+1)
+        f.cards[body["id"].(string)] = body
+        json.NewEncoder(w).Encode(body)
+    case len(parts) == 4 && parts[3] == "extended" && r.Method == "GET":
+        json.NewEncoder(w).Encode(f.cards[parts[2]])
+    case len(parts) == 4 && parts[3] == "extended":
+        f.cards[parts[2]] = body
+        json.NewEncoder(w).Encode(body)
+    default:
+        w.WriteHeader(http.StatusNoContent)
+    }
+}
+
+func TestCreatePageCard(t *testing.T) {
+    tests := []struct {
+        name      string
+        config    GuruConfig
+        verifiers interface{}
+    }{
+        {
+            "user verifier",
+            GuruConfig{CollectionID: "docs", BoardIDs: []string{"b1", "b2"}, Verifier: "owner@example.com", VerificationInterval: 30},
+            []interface{}{map[string]interface{}{"type": "user", "user": map[string]interface{}{"email": "owner@example.com"}}},
+        },
+        {
+            "group verifier",
+            GuruConfig{CollectionID: "docs", BoardIDs: []string{"b1", "b2"}, VerifierGroupID: "experts", VerificationInterval: 30},
+            []interface{}{map[string]interface{}{"type": "user-group", "userGroup": map[string]interface{}{"id": "experts"}}},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGuru()
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            service := NewGuruServiceWithConfig(server.URL, "key", tt.config)
+            id, err := service.CreatePage(context.Background(), Page{ID: "p1", Title: "Setup", Content: "Install it"})
+            if err != nil {
+                t.Fatalf("CreatePage() error = %v", err)
+            }
+
+            card := fake.cards[id]
+            if got := card["collection"]; !reflect.DeepEqual(got, map[string]interface{}{"id": "docs"}) {
+                t.Errorf("collection = %v, want docs", got)
+            }
+            wantBoards := []interface{}{map[string]interface{}{"id": "b1"}, map[string]interface{}{"id": "b2"}}
+            if got := card["boards"]; !reflect.DeepEqual(got, wantBoards) {
+                t.Errorf("boards = %v, want %v", got, wantBoards)
+            }
+            if got := card["verificationInterval"]; got != float64(30) {
+                t.Errorf("verificationInterval = %v, want 30", got)
+            }
+            if got := card["verifiers"]; !reflect.DeepEqual(got, tt.verifiers) {
+                t.Errorf("verifiers = %v, want %v", got, tt.verifiers)
+            }
+        })
+    }
+}
+
+func TestUpdatePageReverifies(t *testing.T) {
+    tests := []struct {
+        name     string
+        reverify string
+        stored   string // Content Guru returns for the card, as HTML
+        content  string // Synced Markdown content
+        want     []string
+    }{
+        {"unchanged", "", "<p>Install <strong>it</strong></p>", "Install **it**", []string{"PUT /v1/cards/c1/extended"}},
+        {"verified", "", "<p>Install it</p>", "Install it now", []string{"PUT /v1/cards/c1/extended", "PUT /v1/cards/c1/verify"}},
+        {"unverified", ReverifyUnverify, "<p>Install it</p>", "Install it now", []string{"PUT /v1/cards/c1/extended", "POST /v1/cards/c1/unverify"}},
+        {"left to Guru", ReverifyNone, "<p>Install it</p>", "Install it now", []string{"PUT /v1/cards/c1/extended"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeGuru()
+            fake.cards["c1"] = map[string]interface{}{"id": "c1", "preferredPhrase": "Setup", "content": tt.stored}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            service := NewGuruServiceWithConfig(server.URL, "key", GuruConfig{CollectionID: "docs", Reverify: tt.reverify})
+            if err := service.UpdatePage(context.Background(), Page{ID: "c1", Title: "Setup", Content: tt.content}); err != nil {
+                t.Fatalf("UpdatePage() error = %v", err)
+            }
+            if !reflect.DeepEqual(fake.writes, tt.want) {
+                t.Errorf("writes = %q, want %q", fake.writes, tt.want)
+            }
+        })
+    }
+}
+
+func TestGetPageVerificationState(t *testing.T) {
+    fake := newFakeGuru()
+    fake.cards["trusted"] = map[string]interface{}{"id": "trusted", "verificationState": "TRUSTED"}
+    fake.cards["stale"] = map[string]interface{}{"id": "stale", "verificationState": "NEEDS_VERIFICATION"}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    service := NewGuruService(server.URL, "key")
+    for id, want := range map[string]string{"trusted": StatusVerified, "stale": StatusUnverified} {
+        page, err := service.GetPage(context.Background(), id)
+        if err != nil {
+            t.Fatalf("GetPage(%q) error = %v", id, err)
+        }
+        if page.Status != want {
+            t.Errorf("GetPage(%q).Status = %q, want %q", id, page.Status, want)
+        }
+    }
+}
+
+func TestSameContent(t *testing.T) {
+    tests := []struct {
+        name     string
+        stored   string
+        markdown string
+        want     bool
+    }{
+        {"emphasis", "<p>Install <em>it</em> &amp; run</p>", "Install *it* & run", true},
+        {"link", `<p>See <a href="https://example.com">the guide</a></p>`, "See [the guide](https://example.com)", true},
+        {"image", `<p><img src="a.png"> Done</p>`, "![diagram](a.png) Done", true},
+        {"ordered list", "<ol><li>One</li><li>Two</li></ol>", "1. One\n2. Two", true},
+        {"heading", "<h2>Setup</h2><p>Text</p>", "## Setup\n\nText", true},
+        {"changed words", "<p>Install it</p>", "Install it now", false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := sameContent(tt.stored, tt.markdown); got != tt.want {
+                t.Errorf("sameContent() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
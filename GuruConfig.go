@@ -0,0 +1,69 @@
+package guru
+
+// Re-verification behaviours supported by GuruConfig.Reverify
+const (
+    ReverifyVerify   = "verify"   // Mark changed cards as verified, the canonical source having been reviewed
+    ReverifyUnverify = "unverify" // Flag changed cards so their verifier reviews them in Guru
+    ReverifyNone     = "none"     // Leave the verification state to Guru
+)
+
+// GuruConfig holds the collection, board and verification settings used when
+// syncing pages to Guru cards
+type GuruConfig struct {
+    CollectionID         string                    `yaml:"collection_id"`         // Collection new cards are created in
+    BoardIDs             []string                  `yaml:"board_ids"`             // Boards cards are added to, optional
+    SectionBoards        bool                      `yaml:"section_boards"`        // Add cards to the board named by the last element of their section path instead
+    CreateMissingBoards  bool                      `yaml:"create_missing_boards"` // Create boards named by section paths that do not exist yet
+    Verifier             string                    `yaml:"verifier"`              // Email of the user who verifies cards
+    VerifierGroupID      string                    `yaml:"verifier_group_id"`     // Group that verifies cards, used when Verifier is empty
+    VerificationInterval int                       `yaml:"verification_interval"` // Days between verifications, defaults to 90
+    Reverify             string                    `yaml:"reverify"`              // What to do when synced content changes, defaults to ReverifyVerify
+    Pages                map[string]GuruPageConfig `yaml:"pages"`                 // Per-page overrides keyed by page ID
+}
+
+// GuruPageConfig overrides the default placement and verification for a single page
+type GuruPageConfig struct {
+    CollectionID         string   `yaml:"collection_id"`
+    BoardIDs             []string `yaml:"board_ids"`
+    Verifier             string   `yaml:"verifier"`
+    VerifierGroupID      string   `yaml:"verifier_group_id"`
+    VerificationInterval int      `yaml:"verification_interval"`
+}
+
+// page returns the settings for a page with its overrides applied
+func (c GuruConfig) page(pageID string) GuruPageConfig {
+    settings := GuruPageConfig{
+        CollectionID:         c.CollectionID,
+        BoardIDs:             c.BoardIDs,
+        Verifier:             c.Verifier,
+        VerifierGroupID:      c.VerifierGroupID,
+        VerificationInterval: c.VerificationInterval,
+    }
+    if override, ok := c.Pages[pageID]; ok {
+        if override.CollectionID != "" {
+            settings.CollectionID = override.CollectionID
+        }
+        if override.BoardIDs != nil {
+            settings.BoardIDs = override.BoardIDs
+        }
+        if override.Verifier != "" || override.VerifierGroupID != "" {
+            settings.Verifier = override.Verifier
+            settings.VerifierGroupID = override.VerifierGroupID
+        }
+        if override.VerificationInterval != 0 {
+            settings.VerificationInterval = override.VerificationInterval
+        }
+    }
+    if settings.VerificationInterval == 0 {
+        settings.VerificationInterval = 90
+    }
+    return settings
+}
+
+// reverify returns the behaviour used when synced content changes
+func (c GuruConfig) reverify() string {
+    if c.Reverify == "" {
+        return ReverifyVerify
+    }
+    return c.Reverify
+}
@@ -0,0 +1,36 @@
+package guru
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestPageSettings(t *testing.T) {
+    config := GuruConfig{
+        CollectionID: "docs",
+        BoardIDs:     []string{"general"},
+        Verifier:     "owner@example.com",
+        Pages: map[string]GuruPageConfig{
+            "moved":    {CollectionID: "support", BoardIDs: []string{}},
+            "grouped":  {VerifierGroupID: "experts", VerificationInterval: 30},
+            "interval": {VerificationInterval: 7},
+        },
+    }
+
+    tests := []struct {
+        pageID string
+        want   GuruPageConfig
+    }{
+        {"plain", GuruPageConfig{CollectionID: "docs", BoardIDs: []string{"general"}, Verifier: "owner@example.com", VerificationInterval: 90}},
+        {"moved", GuruPageConfig{CollectionID: "support", BoardIDs: []string{}, Verifier: "owner@example.com", VerificationInterval: 90}},
+        {"grouped", GuruPageConfig{CollectionID: "docs", BoardIDs: []string{"general"}, VerifierGroupID: "experts", VerificationInterval: 30}},
+        {"interval", GuruPageConfig{CollectionID: "docs", BoardIDs: []string{"general"}, Verifier: "owner@example.com", VerificationInterval: 7}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.pageID, func(t *testing.T) {
+            if got := config.page(tt.pageID); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("page() = %+v, want %+v", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,82 @@
+package guru
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "regexp"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// nextPagePattern finds the next page URL in the Link header of a Guru listing
+var nextPagePattern = regexp.MustCompile(`<([^>]+)>;\s*rel="?next-page"?`)
+
+// ListPages lists the cards of the configured collection, or every card the user can
+// see without one, through the card manager search. Guru pages listings with a Link
+// header pointing at the next page. Each card's first board is reported as its parent
+func (s *GuruServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.listCards)
+}
+
+// listCards fetches one page of card manager search results
+func (s *GuruServiceImpl) listCards(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    endpoint := cursor
+    if endpoint == "" {
+        endpoint = fmt.Sprintf("%s/v1/search/cardmgr?maxResults=50", s.baseURL)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+    if err != nil {
+        return nil, "", err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return nil, "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        body, _ := ioutil.ReadAll(resp.Body)
+        return nil, "", fmt.Errorf("failed to list cards: %s - %s", resp.Status, body)
+    }
+
+    var cards []struct {
+        ID              string    `json:"id"`
+        PreferredPhrase string    `json:"preferredPhrase"`
+        LastModified    time.Time `json:"lastModified"`
+        Collection      struct {
+            ID string `json:"id"`
+        } `json:"collection"`
+        Boards []struct {
+            ID string `json:"id"`
+        } `json:"boards"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&cards); err != nil {
+        return nil, "", err
+    }
+
+    summaries := make([]listing.Summary, 0, len(cards))
+    for _, card := range cards {
+        if s.config.CollectionID != "" && card.Collection.ID != s.config.CollectionID {
+            continue
+        }
+        summary := listing.Summary{ID: card.ID, Title: card.PreferredPhrase, Updated: card.LastModified}
+        if len(card.Boards) > 0 {
+            summary.ParentID = card.Boards[0].ID
+        }
+        summaries = append(summaries, summary)
+    }
+
+    next := ""
+    if match := nextPagePattern.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+        next = match[1]
+    }
+    return summaries, next, nil
+}
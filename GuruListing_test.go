@@ -0,0 +1,102 @@
+package guru
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakeCardSearch serves the card manager search a page at a time, pointing at the
+// next page through the Link header
+type fakeCardSearch struct {
+    pages    [][]map[string]interface{}
+    requests []string // Requested page numbers, "" for the first
+}
+
+// searchCard builds a search result for a card in a collection and on the given boards
+func searchCard(id, collection string, boards ...string) map[string]interface{} {
+    var onBoards []map[string]string
+    for _, board := range boards {
+        onBoards = append(onBoards, map[string]string{"id": board})
+    }
+    return map[string]interface{}{
+        "id":              id,
+        "preferredPhrase": "Card " + id,
+        "lastModified":    "2024-03-01T12:00:00Z",
+        "collection":      map[string]string{"id": collection},
+        "boards":          onBoards,
+    }
+}
+
+func (f *fakeCardSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/v1/search/cardmgr" || r.Header.Get("Authorization") != "Bearer key" {
+        http.NotFound(w, r)
+        return
+    }
+    page := r.URL.Query().Get("page")
+    f.requests = append(f.requests, page)
+
+    index, _ := strconv.Atoi(page)
+    if index+1 < len(f.pages) {
+        w.Header().Set("Link", fmt.Sprintf(`<http://%s/v1/search/cardmgr?maxResults=50&page=%d>; rel="next-page"`, r.Host, index+1))
+    }
+    json.NewEncoder(w).Encode(f.pages[index])
+}
+
+func TestListPages(t *testing.T) {
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    tests := []struct {
+        name   string
+        config GuruConfig
+        want   []listing.Summary
+    }{
+        {
+            name: "every card",
+            want: []listing.Summary{
+                {ID: "c1", Title: "Card c1", Updated: updated, ParentID: "b1"},
+                {ID: "c2", Title: "Card c2", Updated: updated},
+                {ID: "c3", Title: "Card c3", Updated: updated, ParentID: "b2"},
+            },
+        },
+        {
+            name:   "configured collection",
+            config: GuruConfig{CollectionID: "docs"},
+            want: []listing.Summary{
+                {ID: "c1", Title: "Card c1", Updated: updated, ParentID: "b1"},
+                {ID: "c3", Title: "Card c3", Updated: updated, ParentID: "b2"},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeCardSearch{pages: [][]map[string]interface{}{
+                {searchCard("c1", "docs", "b1", "b3"), searchCard("c2", "other")},
+                {},
+                {searchCard("c3", "docs", "b2")},
+            }}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewGuruServiceWithConfig(server.URL, "key", tt.config)
+            got, err := s.ListPages(context.Background()).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("ListPages() = %v, want %v", got, tt.want)
+            }
+            if want := []string{"", "1", "2"}; !reflect.DeepEqual(fake.requests, want) {
+                t.Errorf("requested pages %q, want %q", fake.requests, want)
+            }
+        })
+    }
+}
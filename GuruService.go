@@ -1,6 +1,10 @@
 package guru
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // GuruService defines the methods for interacting with Guru
 type GuruService interface {
@@ -8,4 +12,5 @@ type GuruService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
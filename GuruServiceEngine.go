@@ -7,12 +7,17 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "sync"
 )
 
 // GuruServiceImpl is the implementation of the GuruService interface
 type GuruServiceImpl struct {
     baseURL   string
     apiKey    string
+    config    GuruConfig
+
+    mu        sync.Mutex
+    boards    map[string]string // Board IDs by collection ID and lower-cased title
 }
 
 // NewGuruService creates a new instance of GuruService
@@ -20,14 +25,21 @@ func NewGuruService(baseURL, apiKey string) *GuruServiceImpl {
     return &GuruServiceImpl{baseURL: baseURL, apiKey: apiKey}
 }
 
-// CreatePage creates a new card in Guru
+// NewGuruServiceWithConfig creates a new instance of GuruService that places cards in
+// the configured collection and boards and assigns their verifier
+func NewGuruServiceWithConfig(baseURL, apiKey string, config GuruConfig) *GuruServiceImpl {
+    return &GuruServiceImpl{baseURL: baseURL, apiKey: apiKey, config: config}
+}
+
+// CreatePage creates a new card in the configured Guru collection and boards, with
+// the configured verifier and verification interval
 func (s *GuruServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
-    url := fmt.Sprintf("%s/v1/cards", s.baseURL)
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "title":       page.Title,
-        "content":     page.Content,
-        "category_id": "YOUR_CATEGORY_ID", // Replace with your actual category ID
-    })
+    url := fmt.Sprintf("%s/v1/cards/extended", s.baseURL)
+    payload, err := s.cardPayload(ctx, page)
+    if err != nil {
+        return "", err
+    }
+    reqBody, _ := json.Marshal(payload)
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
@@ -40,7 +52,7 @@ func (s *GuruServiceImpl) CreatePage(ctx context.Context, page Page) (string, er
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusCreated {
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
         body, _ := ioutil.ReadAll(resp.Body)
         return "", fmt.Errorf("failed to create card: %s - %s", resp.Status, body)
     }
@@ -56,14 +68,25 @@ func (s *GuruServiceImpl) CreatePage(ctx context.Context, page Page) (string, er
     return cardID, nil
 }
 
-// UpdatePage updates an existing card in Guru
+// UpdatePage updates an existing card in Guru, moving it to other boards when its
+// section changed. When the synced content differs from the card, the card is
+// re-verified according to the configured behaviour
 func (s *GuruServiceImpl) UpdatePage(ctx context.Context, page Page) error {
-    url := fmt.Sprintf("%s/v1/cards/%s", s.baseURL, page.ID)
+    url := fmt.Sprintf("%s/v1/cards/%s/extended", s.baseURL, page.ID)
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "title":   page.Title,
-        "content": page.Content,
-    })
+    var current map[string]interface{}
+    if err := s.doRequest(ctx, "get card", "GET", url, nil, &current); err != nil {
+        return err
+    }
+    existing := pageFromCard(current)
+    changed := existing.Title != page.Title || !sameContent(existing.Content, page.Content)
+
+    payload, err := s.cardPayload(ctx, page)
+    if err != nil {
+        return err
+    }
+    payload["id"] = page.ID
+    reqBody, _ := json.Marshal(payload)
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
@@ -80,6 +103,9 @@ func (s *GuruServiceImpl) UpdatePage(ctx context.Context, page Page) error {
         return fmt.Errorf("failed to update card: %s - %s", resp.Status, body)
     }
 
+    if changed {
+        return s.reverifyCard(ctx, page.ID)
+    }
     return nil
 }
 
@@ -104,9 +130,21 @@ func (s *GuruServiceImpl) DeletePage(ctx context.Context, id string) error {
     return nil
 }
 
-// GetPage retrieves a card from Guru
+// ArchivePage archives a card. Deleting a card moves it to the Guru archive, so this
+// is the same as DeletePage
+func (s *GuruServiceImpl) ArchivePage(ctx context.Context, id string) error {
+    return s.DeletePage(ctx, id)
+}
+
+// ReadsLabels reports that GetPage returns the tags of a card as its labels
+func (s *GuruServiceImpl) ReadsLabels() bool {
+    return true
+}
+
+// GetPage retrieves a card from Guru. Page.Status reports whether the card is
+// verified or needs verification
 func (s *GuruServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
-    url := fmt.Sprintf("%s/v1/cards/%s", s.baseURL, id)
+    url := fmt.Sprintf("%s/v1/cards/%s/extended", s.baseURL, id)
 
     req, _ := http.NewRequest("GET", url, nil)
     req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
@@ -125,13 +163,5 @@ func (s *GuruServiceImpl) GetPage(ctx context.Context, id string) (Page, error)
     var result map[string]interface{}
     json.NewDecoder(resp.Body).Decode(&result)
 
-    cardID, _ := result["id"].(string)
-    title, _ := result["title"].(string)
-    content, _ := result["content"].(string)
-
-    return Page{
-        ID:      cardID,
-        Title:   title,
-        Content: content,
-    }, nil
+    return pageFromCard(result), nil
 }
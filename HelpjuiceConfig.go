@@ -0,0 +1,54 @@
+package helpjuice
+
+import "strings"
+
+// Accessibility levels supported by HelpjuiceConfig.Accessibility
+const (
+    AccessibilityPublic   = "public"   // Visible to anyone visiting the knowledge base
+    AccessibilityInternal = "internal" // Visible to signed-in users only
+    AccessibilityPrivate  = "private"  // Visible to the users and groups set in Helpjuice only
+)
+
+// HelpjuiceConfig holds the category, accessibility and publishing settings used
+// when syncing pages to Helpjuice
+type HelpjuiceConfig struct {
+    CategoryID    int64                          `yaml:"category_id"`   // Category for pages without a mapped section
+    Categories    map[string]int64               `yaml:"categories"`    // Category IDs keyed by section path, e.g. "Billing/Invoices"
+    Accessibility string                         `yaml:"accessibility"` // AccessibilityPublic (default), AccessibilityInternal or AccessibilityPrivate
+    DraftNew      bool                           `yaml:"draft_new"`     // Create new articles as unpublished drafts
+    DraftUpdates  bool                           `yaml:"draft_updates"` // Save updates as draft revisions for an editor to publish
+    Pages         map[string]HelpjuicePageConfig `yaml:"pages"`         // Per-page overrides keyed by page ID
+}
+
+// HelpjuicePageConfig overrides the category and accessibility for a single page
+type HelpjuicePageConfig struct {
+    CategoryID    int64  `yaml:"category_id"`
+    Accessibility string `yaml:"accessibility"`
+}
+
+// category returns the category ID for a page, from its override, its section path
+// or the default category
+func (c HelpjuiceConfig) category(page Page) int64 {
+    if override, ok := c.Pages[page.CanonicalID()]; ok && override.CategoryID != 0 {
+        return override.CategoryID
+    }
+    if id, ok := c.Categories[strings.Join(page.Section, "/")]; ok && len(page.Section) > 0 {
+        return id
+    }
+    return c.CategoryID
+}
+
+// accessibility returns the Helpjuice accessibility code for a page
+func (c HelpjuiceConfig) accessibility(pageID string) int {
+    level := c.Accessibility
+    if override, ok := c.Pages[pageID]; ok && override.Accessibility != "" {
+        level = override.Accessibility
+    }
+    switch level {
+    case AccessibilityInternal:
+        return 0
+    case AccessibilityPrivate:
+        return 2
+    }
+    return 1
+}
@@ -0,0 +1,58 @@
+package helpjuice
+
+import "testing"
+
+func TestCategory(t *testing.T) {
+    config := HelpjuiceConfig{
+        CategoryID: 1,
+        Categories: map[string]int64{"Billing": 10, "Billing/Invoices": 11},
+        Pages:      map[string]HelpjuicePageConfig{"pinned": {CategoryID: 99}, "public": {Accessibility: AccessibilityPublic}},
+    }
+
+    tests := []struct {
+        name string
+        page Page
+        want int64
+    }{
+        {"no section", Page{ID: "p1"}, 1},
+        {"mapped section", Page{ID: "p1", Section: []string{"Billing"}}, 10},
+        {"mapped subsection", Page{ID: "p1", Section: []string{"Billing", "Invoices"}}, 11},
+        {"unmapped section", Page{ID: "p1", Section: []string{"Billing", "Refunds"}}, 1},
+        {"override", Page{ID: "pinned", Section: []string{"Billing"}}, 99},
+        {"override by source ID", Page{ID: "123", SourceID: "pinned"}, 99},
+        {"override without category", Page{ID: "public", Section: []string{"Billing"}}, 10},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := config.category(tt.page); got != tt.want {
+                t.Errorf("category() = %d, want %d", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestAccessibility(t *testing.T) {
+    tests := []struct {
+        name   string
+        level  string
+        pageID string
+        want   int
+    }{
+        {"default", "", "p1", 1},
+        {"public", AccessibilityPublic, "p1", 1},
+        {"internal", AccessibilityInternal, "p1", 0},
+        {"private", AccessibilityPrivate, "p1", 2},
+        {"override", AccessibilityInternal, "open", 1},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            config := HelpjuiceConfig{
+                Accessibility: tt.level,
+                Pages:         map[string]HelpjuicePageConfig{"open": {Accessibility: AccessibilityPublic}},
+            }
+            if got := config.accessibility(tt.pageID); got != tt.want {
+                t.Errorf("accessibility() = %d, want %d", got, tt.want)
+            }
+        })
+    }
+}
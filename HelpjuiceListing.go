@@ -0,0 +1,50 @@
+package helpjuice
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// ListPages lists the articles of the knowledge base, paging with the page parameter
+// until a page comes back empty. Each article's category is reported as its parent
+func (s *HelpjuiceServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.listArticles)
+}
+
+// listArticles fetches the page of articles numbered by cursor
+func (s *HelpjuiceServiceImpl) listArticles(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    page := 1
+    if cursor != "" {
+        page, _ = strconv.Atoi(cursor)
+    }
+
+    var result struct {
+        Articles []map[string]interface{} `json:"articles"`
+    }
+    url := fmt.Sprintf("%s/api/v1/articles?page=%d", s.baseURL, page)
+    if err := s.doRequest(ctx, "list articles", "GET", url, nil, &result); err != nil {
+        return nil, "", err
+    }
+    if len(result.Articles) == 0 {
+        return nil, "", nil
+    }
+
+    summaries := make([]listing.Summary, 0, len(result.Articles))
+    for _, article := range result.Articles {
+        summary := listing.Summary{
+            ID:       idString(article["id"]),
+            ParentID: idString(article["category_id"]),
+        }
+        summary.Title, _ = article["title"].(string)
+        if updated, ok := article["updated_at"].(string); ok {
+            summary.Updated, _ = time.Parse(time.RFC3339, updated)
+        }
+        summaries = append(summaries, summary)
+    }
+
+    return summaries, strconv.Itoa(page + 1), nil
+}
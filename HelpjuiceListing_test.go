@@ -0,0 +1,57 @@
+package helpjuice
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+func TestListPages(t *testing.T) {
+    // Two pages of articles, then an empty page ending the listing
+    articles := [][]map[string]interface{}{
+        {
+            {"id": 1, "title": "Welcome", "updated_at": "2024-03-01T12:00:00Z"},
+            {"id": 2, "title": "Billing", "updated_at": "2024-03-01T12:00:00Z", "category_id": 20},
+        },
+        {
+            {"id": 3, "title": "Invoices", "updated_at": "2024-03-01T12:00:00Z", "category_id": 20},
+        },
+    }
+    var pages []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        page := r.URL.Query().Get("page")
+        pages = append(pages, page)
+        index, _ := strconv.Atoi(page)
+        result := []map[string]interface{}{}
+        if index >= 1 && index <= len(articles) {
+            result = articles[index-1]
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"articles": result})
+    }))
+    defer server.Close()
+
+    got, err := NewHelpjuiceService(server.URL, "key").ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    want := []listing.Summary{
+        {ID: "1", Title: "Welcome", Updated: updated},
+        {ID: "2", Title: "Billing", Updated: updated, ParentID: "20"},
+        {ID: "3", Title: "Invoices", Updated: updated, ParentID: "20"},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListPages() = %v, want %v", got, want)
+    }
+    if want := []string{"1", "2", "3"}; !reflect.DeepEqual(pages, want) {
+        t.Errorf("requested pages %q, want %q", pages, want)
+    }
+}
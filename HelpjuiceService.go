@@ -1,6 +1,10 @@
 package helpjuice
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // HelpjuiceService defines the methods for interacting with Helpjuice
 type HelpjuiceService interface {
@@ -8,4 +12,5 @@ type HelpjuiceService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
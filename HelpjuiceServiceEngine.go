@@ -13,6 +13,7 @@ import (
 type HelpjuiceServiceImpl struct {
     baseURL   string
     apiKey    string
+    config    HelpjuiceConfig
 }
 
 // NewHelpjuiceService creates a new instance of HelpjuiceService
@@ -20,14 +21,17 @@ func NewHelpjuiceService(baseURL, apiKey string) *HelpjuiceServiceImpl {
     return &HelpjuiceServiceImpl{baseURL: baseURL, apiKey: apiKey}
 }
 
-// CreatePage creates a new page in Helpjuice
+// NewHelpjuiceServiceWithConfig creates a new instance of HelpjuiceService that files
+// articles in mapped categories with the configured accessibility and publishing
+func NewHelpjuiceServiceWithConfig(baseURL, apiKey string, config HelpjuiceConfig) *HelpjuiceServiceImpl {
+    return &HelpjuiceServiceImpl{baseURL: baseURL, apiKey: apiKey, config: config}
+}
+
+// CreatePage creates a new page in Helpjuice, published unless new pages are
+// configured to start as drafts
 func (s *HelpjuiceServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
     url := fmt.Sprintf("%s/api/v1/articles", s.baseURL)
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "title":   page.Title,
-        "content": page.Content,
-        "status":  "published",
-    })
+    reqBody, _ := json.Marshal(s.articleFields(page, true))
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
@@ -48,23 +52,21 @@ func (s *HelpjuiceServiceImpl) CreatePage(ctx context.Context, page Page) (strin
     var result map[string]interface{}
     json.NewDecoder(resp.Body).Decode(&result)
 
-    articleID, ok := result["id"].(string)
-    if !ok {
+    articleID := idString(result["id"])
+    if articleID == "" {
         return "", fmt.Errorf("failed to parse article ID")
     }
 
     return articleID, nil
 }
 
-// UpdatePage updates an existing page in Helpjuice
+// UpdatePage updates an existing page in Helpjuice. With draft updates enabled the
+// change is saved as a draft revision and the published version stays live until an
+// editor publishes it
 func (s *HelpjuiceServiceImpl) UpdatePage(ctx context.Context, page Page) error {
     url := fmt.Sprintf("%s/api/v1/articles/%s", s.baseURL, page.ID)
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "title":   page.Title,
-        "content": page.Content,
-        "status":  "published",
-    })
+    reqBody, _ := json.Marshal(s.articleFields(page, false))
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
@@ -126,14 +128,94 @@ func (s *HelpjuiceServiceImpl) GetPage(ctx context.Context, id string) (Page, er
     var result map[string]interface{}
     json.NewDecoder(resp.Body).Decode(&result)
 
-    article := result["article"].(map[string]interface{})
-    pageID := article["id"].(string)
-    title := article["title"].(string)
-    content := article["content"].(string)
+    article, ok := result["article"].(map[string]interface{})
+    if !ok {
+        return Page{}, fmt.Errorf("failed to parse article")
+    }
+    title, _ := article["title"].(string)
+    content, _ := article["content"].(string)
+    status, _ := article["status"].(string)
 
     return Page{
-        ID:      pageID,
+        ID:      idString(article["id"]),
         Title:   title,
         Content: content,
+        Status:  status,
     }, nil
 }
+
+// articleFields builds the article body for a page with its category, accessibility
+// and publishing state. Updates only publish when new articles are published too, so
+// a draft created with DraftNew stays a draft until an editor publishes it
+func (s *HelpjuiceServiceImpl) articleFields(page Page, create bool) map[string]interface{} {
+    fields := map[string]interface{}{
+        "title":         page.Title,
+        "content":       page.Content,
+        "accessibility": s.config.accessibility(page.CanonicalID()),
+    }
+    if category := s.config.category(page); category != 0 {
+        fields["category_id"] = category
+    }
+
+    switch {
+    case create && s.config.DraftNew:
+        fields["status"] = "draft"
+    case create:
+        fields["status"] = "published"
+    case s.config.DraftUpdates:
+        // Saves a draft revision without changing the published version
+        fields["draft"] = true
+    case !s.config.DraftNew:
+        fields["status"] = "published"
+    }
+
+    return fields
+}
+
+// doRequest sends an authenticated JSON request to the Helpjuice API and decodes the
+// response into out when it is not nil
+func (s *HelpjuiceServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil && resp.StatusCode != http.StatusNoContent {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
+
+// idString formats an article ID, which Helpjuice returns as a number
+func idString(value interface{}) string {
+    switch id := value.(type) {
+    case string:
+        return id
+    case float64:
+        return fmt.Sprintf("%.0f", id)
+    }
+    return ""
+}
@@ -0,0 +1,97 @@
+package helpjuice
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+)
+
+// fakeArticles records the body of every write to the articles API and serves a
+// single stored article
+type fakeArticles struct {
+    article map[string]interface{}
+    bodies  []map[string]interface{}
+}
+
+func (f *fakeArticles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case "GET":
+        json.NewEncoder(w).Encode(map[string]interface{}{"article": f.article})
+        return
+    case "POST":
+        w.WriteHeader(http.StatusCreated)
+    }
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    f.bodies = append(f.bodies, body)
+    json.NewEncoder(w).Encode(map[string]interface{}{"id": 42})
+}
+
+func TestArticlePublishing(t *testing.T) {
+    tests := []struct {
+        name   string
+        config HelpjuiceConfig
+        create map[string]interface{} // Publishing fields sent on create
+        update map[string]interface{} // Publishing fields sent on update
+    }{
+        {"published", HelpjuiceConfig{}, map[string]interface{}{"status": "published"}, map[string]interface{}{"status": "published"}},
+        {"draft revisions", HelpjuiceConfig{DraftUpdates: true}, map[string]interface{}{"status": "published"}, map[string]interface{}{"draft": true}},
+        {"draft new", HelpjuiceConfig{DraftNew: true}, map[string]interface{}{"status": "draft"}, map[string]interface{}{}},
+        {"all drafts", HelpjuiceConfig{DraftNew: true, DraftUpdates: true}, map[string]interface{}{"status": "draft"}, map[string]interface{}{"draft": true}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeArticles{}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            tt.config.CategoryID = 7
+            tt.config.Accessibility = AccessibilityInternal
+            service := NewHelpjuiceServiceWithConfig(server.URL, "key", tt.config)
+            id, err := service.CreatePage(context.Background(), Page{ID: "p1", Title: "Setup", Content: "Install it"})
+            if err != nil {
+                t.Fatalf("CreatePage() error = %v", err)
+            }
+            if id != "42" {
+                t.Errorf("CreatePage() = %q, want 42", id)
+            }
+            if err := service.UpdatePage(context.Background(), Page{ID: id, SourceID: "p1", Title: "Setup", Content: "Install it now"}); err != nil {
+                t.Fatalf("UpdatePage() error = %v", err)
+            }
+
+            for i, want := range []map[string]interface{}{tt.create, tt.update} {
+                body := fake.bodies[i]
+                if body["category_id"] != float64(7) || body["accessibility"] != float64(0) {
+                    t.Errorf("write %d category and accessibility = %v, %v, want 7, 0", i+1, body["category_id"], body["accessibility"])
+                }
+                got := map[string]interface{}{}
+                for _, key := range []string{"status", "draft"} {
+                    if value, ok := body[key]; ok {
+                        got[key] = value
+                    }
+                }
+                if !reflect.DeepEqual(got, want) {
+                    t.Errorf("write %d publishing = %v, want %v", i+1, got, want)
+                }
+            }
+        })
+    }
+}
+
+func TestGetPageArticle(t *testing.T) {
+    fake := &fakeArticles{article: map[string]interface{}{"id": 42, "title": "Setup", "content": "<p>Install</p>", "status": "draft"}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    page, err := NewHelpjuiceService(server.URL, "key").GetPage(context.Background(), "42")
+    if err != nil {
+        t.Fatalf("GetPage() error = %v", err)
+    }
+    want := Page{ID: "42", Title: "Setup", Content: "<p>Install</p>", Status: "draft"}
+    if !reflect.DeepEqual(page, want) {
+        t.Errorf("GetPage() = %+v, want %+v", page, want)
+    }
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+    "io/ioutil"
+    "os"
+    "sync"
+
+    "github.com/go-yaml/yaml"
+)
+
+// MappingEntry records where a canonical page lives on one service
+type MappingEntry struct {
+    RemoteID string `yaml:"remote_id"`      // ID of the page on the service
+    Path     string `yaml:"path,omitempty"` // Generated path on path-addressed targets
+}
+
+// IDMapping maps canonical page IDs to their IDs on each service, so later runs update
+// the pages created by earlier ones. It is stored as a YAML file
+type IDMapping struct {
+    file string
+
+    mu    sync.Mutex
+    Pages map[string]map[string]MappingEntry `yaml:"pages"` // Entries by canonical page ID and service name
+}
+
+// PathTarget is implemented by services that store pages at generated paths. Paths
+// recorded in the ID mapping are handed back so pages keep their paths across runs,
+// and PagePath reports the current path of a page after it was moved
+type PathTarget interface {
+    ReservePath(pageID, path string)
+    PagePath(pageID string) (string, bool)
+}
+
+// LoadIDMapping reads the ID mapping from file, starting empty when it does not exist
+func LoadIDMapping(file string) (*IDMapping, error) {
+    mapping := &IDMapping{file: file, Pages: make(map[string]map[string]MappingEntry)}
+
+    data, err := ioutil.ReadFile(file)
+    if os.IsNotExist(err) {
+        return mapping, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    if err := yaml.Unmarshal(data, mapping); err != nil {
+        return nil, err
+    }
+    if mapping.Pages == nil {
+        mapping.Pages = make(map[string]map[string]MappingEntry)
+    }
+    return mapping, nil
+}
+
+// Save writes the ID mapping back to its file
+func (m *IDMapping) Save() error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    data, err := yaml.Marshal(m)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(m.file, data, 0644)
+}
+
+// Get returns the entry of a page on a service
+func (m *IDMapping) Get(pageID, service string) (MappingEntry, bool) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    entry, ok := m.Pages[pageID][service]
+    return entry, ok
+}
+
+// RemoteID returns the ID of a page on a service, or the canonical ID when the page
+// is not mapped
+func (m *IDMapping) RemoteID(pageID, service string) string {
+    if entry, ok := m.Get(pageID, service); ok {
+        return entry.RemoteID
+    }
+    return pageID
+}
+
+// RemoteIDs returns the canonical page IDs of the pages mapped on a service, keyed by
+// their IDs on the service
+func (m *IDMapping) RemoteIDs(service string) map[string]string {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    ids := make(map[string]string)
+    for pageID, services := range m.Pages {
+        if entry, ok := services[service]; ok {
+            ids[entry.RemoteID] = pageID
+        }
+    }
+    return ids
+}
+
+// remoteTargets splits a remote ID into the IDs listings report for it, keyed by
+// target. Services without composite IDs have a single target named ""
+func remoteTargets(svc ServiceInterface, remoteID string) map[string]string {
+    if composite, ok := svc.(CompositeIDs); ok {
+        return composite.SplitID(remoteID)
+    }
+    return map[string]string{"": remoteID}
+}
+
+// listedRemoteIDs maps the IDs the listing of a service reports for mapped pages to
+// their canonical page IDs
+func listedRemoteIDs(svc ServiceInterface, mapping *IDMapping) map[string]string {
+    ids := make(map[string]string)
+    for remoteID, pageID := range mapping.RemoteIDs(svcName(svc)) {
+        for _, id := range remoteTargets(svc, remoteID) {
+            ids[id] = pageID
+        }
+    }
+    return ids
+}
+
+// Set records the entry of a page on a service
+func (m *IDMapping) Set(pageID, service string, entry MappingEntry) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.Pages[pageID] == nil {
+        m.Pages[pageID] = make(map[string]MappingEntry)
+    }
+    m.Pages[pageID][service] = entry
+}
+
+// Delete removes the entry of a page on a service
+func (m *IDMapping) Delete(pageID, service string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    delete(m.Pages[pageID], service)
+    if len(m.Pages[pageID]) == 0 {
+        delete(m.Pages, pageID)
+    }
+}
+
+// ReservePaths hands the recorded paths of a service to a path-addressed target
+func (m *IDMapping) ReservePaths(service string, target PathTarget) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for pageID, services := range m.Pages {
+        if entry, ok := services[service]; ok && entry.Path != "" {
+            target.ReservePath(pageID, entry.Path)
+        }
+    }
+}
@@ -0,0 +1,92 @@
+package listing
+
+import (
+    "context"
+    "time"
+)
+
+// Summary describes a page as listed by a service, without its content
+type Summary struct {
+    ID       string
+    Title    string
+    Updated  time.Time // Last change, zero when the platform does not report it in listings
+    ParentID string    // Parent page, or the section, folder or list holding the page on platforms without nested pages
+}
+
+// FetchFunc returns one batch of a listing starting at cursor, which is "" for the
+// first batch, together with the cursor of the next batch, or "" after the last
+type FetchFunc func(ctx context.Context, cursor string) ([]Summary, string, error)
+
+// Iterator walks a paginated listing, fetching the next batch from the platform only
+// once the current one is used up. It is used like bufio.Scanner:
+//
+//	for it.Next() {
+//	    use(it.Summary())
+//	}
+//	if err := it.Err(); err != nil {
+//	    ...
+//	}
+type Iterator struct {
+    ctx   context.Context
+    fetch FetchFunc
+
+    cursor  string
+    batch   []Summary
+    current Summary
+    started bool
+    err     error
+}
+
+// New creates an Iterator that fetches batches with fetch
+func New(ctx context.Context, fetch FetchFunc) *Iterator {
+    return &Iterator{ctx: ctx, fetch: fetch}
+}
+
+// Failed returns an Iterator that yields nothing and reports err, for listings that
+// cannot start, such as when the service is missing configuration
+func Failed(err error) *Iterator {
+    return &Iterator{started: true, err: err}
+}
+
+// Next advances to the next page, fetching another batch when needed. It returns
+// false at the end of the listing or on error
+func (it *Iterator) Next() bool {
+    for len(it.batch) == 0 {
+        if it.err != nil || (it.started && it.cursor == "") {
+            return false
+        }
+        if err := it.ctx.Err(); err != nil {
+            it.err = err
+            return false
+        }
+        batch, cursor, err := it.fetch(it.ctx, it.cursor)
+        if err != nil {
+            it.err = err
+            return false
+        }
+        it.started = true
+        it.batch, it.cursor = batch, cursor
+    }
+
+    it.current, it.batch = it.batch[0], it.batch[1:]
+    return true
+}
+
+// Summary returns the page Next advanced to
+func (it *Iterator) Summary() Summary {
+    return it.current
+}
+
+// Err returns the error that stopped the listing, if any
+func (it *Iterator) Err() error {
+    return it.err
+}
+
+// All reads the rest of the listing
+func (it *Iterator) All() ([]Summary, error) {
+    var summaries []Summary
+    for it.Next() {
+        summaries = append(summaries, it.Summary())
+    }
+    return summaries, it.Err()
+}
@@ -0,0 +1,113 @@
+package listing
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "strconv"
+    "testing"
+)
+
+// batches returns a FetchFunc serving the batches in turn, with the index of the next
+// batch as cursor, and records the cursors it was called with
+func batches(calls *[]string, all ...[]Summary) FetchFunc {
+    return func(ctx context.Context, cursor string) ([]Summary, string, error) {
+        *calls = append(*calls, cursor)
+        index := 0
+        if cursor != "" {
+            index, _ = strconv.Atoi(cursor)
+        }
+        if index+1 == len(all) {
+            return all[index], "", nil
+        }
+        return all[index], strconv.Itoa(index + 1), nil
+    }
+}
+
+func TestIteratorFetchesLazily(t *testing.T) {
+    var calls []string
+    it := New(context.Background(), batches(&calls,
+        []Summary{{ID: "1"}, {ID: "2"}},
+        nil, // Empty batches are skipped
+        []Summary{{ID: "3"}},
+    ))
+
+    if len(calls) != 0 {
+        t.Fatalf("fetched %q before Next, want nothing", calls)
+    }
+    for _, want := range []struct {
+        id    string
+        calls []string
+    }{
+        {"1", []string{""}},
+        {"2", []string{""}},
+        {"3", []string{"", "1", "2"}},
+    } {
+        if !it.Next() {
+            t.Fatalf("Next() = false, want %s: %v", want.id, it.Err())
+        }
+        if it.Summary().ID != want.id {
+            t.Errorf("Summary().ID = %q, want %q", it.Summary().ID, want.id)
+        }
+        if !reflect.DeepEqual(calls, want.calls) {
+            t.Errorf("fetched %q by %s, want %q", calls, want.id, want.calls)
+        }
+    }
+    if it.Next() || it.Err() != nil {
+        t.Errorf("Next() after the last batch = true or Err() = %v", it.Err())
+    }
+    if len(calls) != 3 {
+        t.Errorf("fetched %q, want no fetch after the last batch", calls)
+    }
+}
+
+func TestIteratorErrors(t *testing.T) {
+    failure := errors.New("listing failed")
+    canceled, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    tests := []struct {
+        name string
+        it   *Iterator
+        want []Summary
+        err  error
+    }{
+        {
+            name: "fetch error after a batch",
+            it: New(context.Background(), func(ctx context.Context, cursor string) ([]Summary, string, error) {
+                if cursor == "" {
+                    return []Summary{{ID: "1"}}, "next", nil
+                }
+                return nil, "", failure
+            }),
+            want: []Summary{{ID: "1"}},
+            err:  failure,
+        },
+        {
+            name: "failed listing",
+            it:   Failed(failure),
+            err:  failure,
+        },
+        {
+            name: "canceled context",
+            it: New(canceled, func(ctx context.Context, cursor string) ([]Summary, string, error) {
+                return []Summary{{ID: "1"}}, "", nil
+            }),
+            err: context.Canceled,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := tt.it.All()
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("All() = %v, want %v", got, tt.want)
+            }
+            if err != tt.err {
+                t.Errorf("All() error = %v, want %v", err, tt.err)
+            }
+            if tt.it.Next() {
+                t.Error("Next() after an error = true, want false")
+            }
+        })
+    }
+}
@@ -0,0 +1,100 @@
+package markdown
+
+import (
+    "fmt"
+    "html"
+    "strings"
+)
+
+// HTML renders parsed blocks as HTML. Alerts render as blockquotes, details blocks
+// as <details> elements and raw blocks unchanged
+func HTML(blocks []Block) string {
+    var b strings.Builder
+    for _, block := range blocks {
+        writeHTML(&b, block)
+    }
+    return b.String()
+}
+
+// writeHTML renders a single block
+func writeHTML(b *strings.Builder, block Block) {
+    switch block.Kind {
+    case ParagraphBlock:
+        b.WriteString("<p>" + InlineHTML(ParseInline(block.Text)) + "</p>")
+    case HeadingBlock:
+        b.WriteString(fmt.Sprintf("<h%d>%s</h%d>", block.Level, InlineHTML(ParseInline(block.Text)), block.Level))
+    case ListBlock:
+        tag := "ul"
+        if block.Ordered {
+            tag = "ol"
+        }
+        b.WriteString("<" + tag + ">")
+        for _, item := range block.Children {
+            b.WriteString("<li>" + html.EscapeString(item.Box()) + InlineHTML(ParseInline(item.Text)))
+            for _, child := range item.Children {
+                writeHTML(b, child)
+            }
+            b.WriteString("</li>")
+        }
+        b.WriteString("</" + tag + ">")
+    case CodeBlock:
+        b.WriteString("<pre>" + html.EscapeString(block.Text) + "</pre>")
+    case QuoteBlock:
+        b.WriteString("<blockquote>")
+        if block.Alert != "" {
+            b.WriteString("<p><strong>" + html.EscapeString(block.Alert) + "</strong> " + InlineHTML(ParseInline(block.Text)) + "</p>")
+        }
+        b.WriteString(HTML(block.Children) + "</blockquote>")
+    case RuleBlock:
+        b.WriteString("<hr />")
+    case TableBlock:
+        b.WriteString("<table><tbody>")
+        for r, row := range block.Rows {
+            cell := "td"
+            if r == 0 {
+                cell = "th"
+            }
+            b.WriteString("<tr>")
+            for _, value := range row {
+                b.WriteString("<" + cell + ">" + InlineHTML(ParseInline(value)) + "</" + cell + ">")
+            }
+            b.WriteString("</tr>")
+        }
+        b.WriteString("</tbody></table>")
+    case DetailsBlock:
+        b.WriteString("<details>")
+        if block.Text != "" {
+            b.WriteString("<summary>" + InlineHTML(ParseInline(block.Text)) + "</summary>")
+        }
+        b.WriteString(HTML(block.Children) + "</details>")
+    case RawBlock:
+        b.WriteString(block.Text)
+    }
+}
+
+// InlineHTML renders parsed spans as HTML; line breaks inside a paragraph become
+// spaces as in rendered Markdown
+func InlineHTML(spans []Span) string {
+    var b strings.Builder
+    for _, span := range spans {
+        switch span.Kind {
+        case TextSpan:
+            b.WriteString(html.EscapeString(span.Text))
+        case BreakSpan:
+            b.WriteString(" ")
+        case CodeSpan:
+            b.WriteString("<code>" + html.EscapeString(span.Text) + "</code>")
+        case StrongSpan:
+            b.WriteString("<strong>" + InlineHTML(span.Children) + "</strong>")
+        case EmphasisSpan:
+            b.WriteString("<em>" + InlineHTML(span.Children) + "</em>")
+        case LinkSpan:
+            b.WriteString(`<a href="` + html.EscapeString(span.URL) + `">` + InlineHTML(span.Children) + "</a>")
+        case ImageSpan:
+            b.WriteString(`<img src="` + html.EscapeString(span.URL) + `" alt="` + html.EscapeString(span.Text) + `" />`)
+        case RawSpan:
+            b.WriteString(span.Text)
+        }
+    }
+    return b.String()
+}
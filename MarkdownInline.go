@@ -0,0 +1,345 @@
+package markdown
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// SpanKind identifies the kind of a parsed inline span
+type SpanKind int
+
+// Kinds of span produced by ParseInline
+const (
+    TextSpan SpanKind = iota
+    BreakSpan
+    CodeSpan
+    StrongSpan
+    EmphasisSpan
+    LinkSpan
+    ImageSpan
+    RawSpan
+)
+
+// Span is an inline element of Markdown text
+type Span struct {
+    Kind     SpanKind
+    Text     string // Plain text, code, image alt text or raw markup
+    URL      string // Link and image target
+    Children []Span // Content of strong, emphasis and link spans
+}
+
+// markdownPunctuation lists the characters a backslash escapes
+const markdownPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// ParseInline parses plain inline Markdown
+func ParseInline(text string) []Span {
+    return Parser{}.ParseInline(text)
+}
+
+// ParseInline parses inline Markdown: backslash escapes, code spans, images, links,
+// strong and emphasis. Line breaks inside the text become BreakSpan. Delimiters that
+// are not closed are kept as text, and "_" does not emphasise inside a word
+func (p Parser) ParseInline(text string) []Span {
+    raw := map[int]int{}
+    if p.RawInline != nil {
+        for _, m := range p.RawInline.FindAllStringIndex(text, -1) {
+            raw[m[0]] = m[1]
+        }
+    }
+
+    var spans []Span
+    var plain strings.Builder
+    flush := func() {
+        if plain.Len() > 0 {
+            spans = append(spans, Span{Kind: TextSpan, Text: plain.String()})
+            plain.Reset()
+        }
+    }
+    add := func(span Span) {
+        flush()
+        spans = append(spans, span)
+    }
+
+    for i := 0; i < len(text); {
+        if end, ok := raw[i]; ok {
+            add(Span{Kind: RawSpan, Text: text[i:end]})
+            i = end
+            continue
+        }
+
+        switch c := text[i]; c {
+        case '\\':
+            if i+1 < len(text) && strings.IndexByte(markdownPunctuation, text[i+1]) >= 0 {
+                plain.WriteByte(text[i+1])
+                i += 2
+                continue
+            }
+        case '\n':
+            add(Span{Kind: BreakSpan})
+            i++
+            continue
+        case '`':
+            if code, n := codeSpanAt(text[i:]); n > 0 {
+                add(Span{Kind: CodeSpan, Text: code})
+                i += n
+                continue
+            }
+            // An unclosed run of backticks is text, including the backticks after the first
+            n := runLength(text, i)
+            plain.WriteString(text[i : i+n])
+            i += n
+            continue
+        case '!':
+            if label, url, n := linkAt(text[i+1:]); n > 0 {
+                add(Span{Kind: ImageSpan, Text: PlainText(p.ParseInline(label)), URL: url})
+                i += 1 + n
+                continue
+            }
+        case '[':
+            if label, url, n := linkAt(text[i:]); n > 0 && label != "" {
+                add(Span{Kind: LinkSpan, URL: url, Children: p.ParseInline(label)})
+                i += n
+                continue
+            }
+        case '*', '_':
+            if span, n := p.emphasisAt(text, i); n > 0 {
+                add(span)
+                i += n
+                continue
+            }
+            n := runLength(text, i)
+            plain.WriteString(text[i : i+n])
+            i += n
+            continue
+        }
+
+        plain.WriteByte(text[i])
+        i++
+    }
+    flush()
+
+    return spans
+}
+
+// runLength counts the repeats of the character at text[i]
+func runLength(text string, i int) int {
+    n := 1
+    for i+n < len(text) && text[i+n] == text[i] {
+        n++
+    }
+    return n
+}
+
+// codeSpanAt parses a code span at the start of text, closed by a backtick run of
+// the same length, and returns its content and length, or 0 when it is not closed
+func codeSpanAt(text string) (string, int) {
+    open := runLength(text, 0)
+    for i := open; i < len(text); {
+        if text[i] != '`' {
+            i++
+            continue
+        }
+        n := runLength(text, i)
+        if n == open {
+            code := text[open:i]
+            if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+                code = code[1 : len(code)-1]
+            }
+            return code, i + n
+        }
+        i += n
+    }
+    return "", 0
+}
+
+// linkAt parses "[label](url)" at the start of text and returns the label, the URL and
+// the length of the link, or 0 when text does not start with a link
+func linkAt(text string) (string, string, int) {
+    if !strings.HasPrefix(text, "[") {
+        return "", "", 0
+    }
+    depth := 0
+    for i := 0; i < len(text); i++ {
+        switch text[i] {
+        case '\\':
+            i++
+        case '`':
+            if _, n := codeSpanAt(text[i:]); n > 0 {
+                i += n - 1
+            }
+        case '[':
+            depth++
+        case ']':
+            depth--
+            if depth > 0 {
+                continue
+            }
+            rest := text[i+1:]
+            if !strings.HasPrefix(rest, "(") {
+                return "", "", 0
+            }
+            // A destination in angle brackets may contain spaces and parentheses
+            if strings.HasPrefix(rest, "(<") {
+                end := strings.IndexAny(rest[2:], "<>\n")
+                if end < 0 || rest[2+end] != '>' || !strings.HasPrefix(rest[2+end+1:], ")") {
+                    return "", "", 0
+                }
+                return text[1:i], rest[2 : 2+end], i + 1 + 2 + end + 2
+            }
+            end := strings.IndexByte(rest, ')')
+            url := ""
+            if end > 0 {
+                url = rest[1:end]
+            }
+            if url == "" || strings.ContainsAny(url, " \t\n") {
+                return "", "", 0
+            }
+            return text[1:i], url, i + 1 + end + 1
+        }
+    }
+    return "", "", 0
+}
+
+// emphasisAt parses strong or emphasised text opened by the delimiter run at
+// text[i]. The closing run must have the same length: one character emphasises, two
+// make strong text and three both. It returns the length consumed, or 0
+func (p Parser) emphasisAt(text string, i int) (Span, int) {
+    delimiter := text[i]
+    open := runLength(text, i)
+    if open > 3 || !canOpen(text, i, open) {
+        return Span{}, 0
+    }
+
+    for j := i + open; j < len(text); {
+        switch text[j] {
+        case '\\':
+            j += 2
+            continue
+        case '`':
+            if _, n := codeSpanAt(text[j:]); n > 0 {
+                j += n
+                continue
+            }
+        case delimiter:
+            n := runLength(text, j)
+            if n == open && j > i+open && canClose(text, j, n) {
+                inner := p.ParseInline(text[i+open : j])
+                var span Span
+                switch open {
+                case 1:
+                    span = Span{Kind: EmphasisSpan, Children: inner}
+                case 2:
+                    span = Span{Kind: StrongSpan, Children: inner}
+                default:
+                    span = Span{Kind: StrongSpan, Children: []Span{{Kind: EmphasisSpan, Children: inner}}}
+                }
+                return span, j + n - i
+            }
+            j += n
+            continue
+        }
+        j++
+    }
+    return Span{}, 0
+}
+
+// canOpen reports whether the delimiter run of n characters at text[i] can open
+// emphasis: it must be followed by a non-space, and "_" must not follow a letter or digit
+func canOpen(text string, i, n int) bool {
+    next, _ := utf8.DecodeRuneInString(text[i+n:])
+    if i+n >= len(text) || unicode.IsSpace(next) {
+        return false
+    }
+    prev, _ := utf8.DecodeLastRuneInString(text[:i])
+    return text[i] != '_' || i == 0 || !isWordRune(prev)
+}
+
+// canClose reports whether the delimiter run of n characters at text[j] can close
+// emphasis: it must follow a non-space, and "_" must not be followed by a letter or digit
+func canClose(text string, j, n int) bool {
+    prev, _ := utf8.DecodeLastRuneInString(text[:j])
+    if j == 0 || unicode.IsSpace(prev) {
+        return false
+    }
+    next, _ := utf8.DecodeRuneInString(text[j+n:])
+    return text[j] != '_' || j+n >= len(text) || !isWordRune(next)
+}
+
+// isWordRune reports whether r is a letter or digit
+func isWordRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// PlainText returns the text of spans without any markup
+func PlainText(spans []Span) string {
+    var b strings.Builder
+    for _, span := range spans {
+        switch span.Kind {
+        case BreakSpan:
+            b.WriteString("\n")
+        case StrongSpan, EmphasisSpan, LinkSpan:
+            b.WriteString(PlainText(span.Children))
+        default:
+            b.WriteString(span.Text)
+        }
+    }
+    return b.String()
+}
+
+// Escape backslash-escapes the characters of plain text that ParseInline would read
+// as markup, so the text parses back unchanged
+func Escape(text string) string {
+    var b strings.Builder
+    for i := 0; i < len(text); i++ {
+        switch c := text[i]; c {
+        case '\\', '`', '*', '[', ']':
+            b.WriteByte('\\')
+        case '_':
+            // "_" inside a word is never markup, so snake_case names stay readable
+            prev, _ := utf8.DecodeLastRuneInString(text[:i])
+            next, _ := utf8.DecodeRuneInString(text[i+1:])
+            if i == 0 || i+1 == len(text) || !isWordRune(prev) || !isWordRune(next) {
+                b.WriteByte('\\')
+            }
+        }
+        b.WriteByte(text[i])
+    }
+    return b.String()
+}
+
+// InlineCode writes code as a code span, using a backtick run longer than any inside
+// the code and padding it with spaces when it starts or ends with a backtick
+func InlineCode(code string) string {
+    fence := strings.Repeat("`", longestRun(code, '`')+1)
+    if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") ||
+        (len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "") {
+        code = " " + code + " "
+    }
+    return fence + code + fence
+}
+
+// Destination writes a link or image target, wrapping it in angle brackets when it
+// contains spaces or parentheses. Angle brackets in the target are percent-encoded
+func Destination(url string) string {
+    if !strings.ContainsAny(url, " \t()<>") && url != "" {
+        return url
+    }
+    url = strings.NewReplacer("<", "%3C", ">", "%3E", "\n", "%0A").Replace(url)
+    return "<" + url + ">"
+}
+
+// longestRun returns the length of the longest run of c in text
+func longestRun(text string, c byte) int {
+    longest := 0
+    for i := 0; i < len(text); i++ {
+        if text[i] == c {
+            n := runLength(text, i)
+            if n > longest {
+                longest = n
+            }
+            i += n - 1
+        }
+    }
+    return longest
+}
@@ -0,0 +1,162 @@
+package markdown
+
+import (
+    "reflect"
+    "regexp"
+    "testing"
+)
+
+func TestParseInline(t *testing.T) {
+    text := func(s string) Span { return Span{Kind: TextSpan, Text: s} }
+
+    tests := []struct {
+        name string
+        text string
+        want []Span
+    }{
+        {
+            name: "strong and emphasis",
+            text: "**bold** and *it*",
+            want: []Span{
+                {Kind: StrongSpan, Children: []Span{text("bold")}},
+                text(" and "),
+                {Kind: EmphasisSpan, Children: []Span{text("it")}},
+            },
+        },
+        {
+            name: "emphasis inside strong",
+            text: "**a *b* c**",
+            want: []Span{{Kind: StrongSpan, Children: []Span{
+                text("a "),
+                {Kind: EmphasisSpan, Children: []Span{text("b")}},
+                text(" c"),
+            }}},
+        },
+        {
+            name: "escaped asterisks stay text",
+            text: `5\*3\*2`,
+            want: []Span{text("5*3*2")},
+        },
+        {
+            name: "underscores inside words stay text",
+            text: "snake_case_name",
+            want: []Span{text("snake_case_name")},
+        },
+        {
+            name: "unclosed delimiter",
+            text: "a * b",
+            want: []Span{text("a * b")},
+        },
+        {
+            name: "code span hides markup",
+            text: "run `*x*` now",
+            want: []Span{text("run "), {Kind: CodeSpan, Text: "*x*"}, text(" now")},
+        },
+        {
+            name: "link with emphasis",
+            text: "[*docs*](https://example.com)",
+            want: []Span{{Kind: LinkSpan, URL: "https://example.com", Children: []Span{
+                {Kind: EmphasisSpan, Children: []Span{text("docs")}},
+            }}},
+        },
+        {
+            name: "image",
+            text: "![diagram](flow.png)",
+            want: []Span{{Kind: ImageSpan, Text: "diagram", URL: "flow.png"}},
+        },
+        {
+            name: "line break",
+            text: "one\ntwo",
+            want: []Span{text("one"), {Kind: BreakSpan}, text("two")},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ParseInline(tt.text); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("ParseInline(%q) = %+v, want %+v", tt.text, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParseInlineRaw(t *testing.T) {
+    parser := Parser{RawInline: regexp.MustCompile(`:status\[[^\]]*\]`)}
+    got := parser.ParseInline("state :status[*Done*] `:status[x]`")
+    want := []Span{
+        {Kind: TextSpan, Text: "state "},
+        {Kind: RawSpan, Text: ":status[*Done*]"},
+        {Kind: TextSpan, Text: " "},
+        {Kind: CodeSpan, Text: ":status[x]"},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ParseInline() = %+v, want %+v", got, want)
+    }
+}
+
+func TestEscape(t *testing.T) {
+    tests := []string{
+        "5*3*2",
+        "snake_case and _leading",
+        `C:\path\to`,
+        "[not](a link)",
+        "use `code` here",
+        "**not bold**",
+        "a ] b",
+    }
+
+    for _, plain := range tests {
+        t.Run(plain, func(t *testing.T) {
+            if got := PlainText(ParseInline(Escape(plain))); got != plain {
+                t.Errorf("PlainText(ParseInline(Escape(%q))) = %q", plain, got)
+            }
+        })
+    }
+}
+
+func TestInlineCode(t *testing.T) {
+    tests := []struct {
+        code string
+        want string
+    }{
+        {code: "x", want: "`x`"},
+        {code: "a`b", want: "``a`b``"},
+        {code: "`x", want: "`` `x ``"},
+        {code: " padded ", want: "`  padded  `"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.code, func(t *testing.T) {
+            got := InlineCode(tt.code)
+            if got != tt.want {
+                t.Errorf("InlineCode(%q) = %q, want %q", tt.code, got, tt.want)
+            }
+            if spans := ParseInline(got); len(spans) != 1 || spans[0].Text != tt.code {
+                t.Errorf("ParseInline(%q) = %+v", got, spans)
+            }
+        })
+    }
+}
+
+func TestDestination(t *testing.T) {
+    tests := []struct {
+        url  string
+        want string
+    }{
+        {url: "https://example.com", want: "https://example.com"},
+        {url: "https://example.com/a_(b)", want: "<https://example.com/a_(b)>"},
+        {url: "file name.png", want: "<file name.png>"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.url, func(t *testing.T) {
+            got := Destination(tt.url)
+            if got != tt.want {
+                t.Errorf("Destination(%q) = %q, want %q", tt.url, got, tt.want)
+            }
+            if spans := ParseInline("[x](" + got + ") after"); len(spans) != 2 || spans[0].URL != tt.url {
+                t.Errorf("ParseInline() = %+v, want a link to %q", spans, tt.url)
+            }
+        })
+    }
+}
@@ -0,0 +1,340 @@
+package markdown
+
+import (
+    "regexp"
+    "strings"
+)
+
+// BlockKind identifies the kind of a parsed block
+type BlockKind int
+
+// Kinds of block produced by Parse
+const (
+    ParagraphBlock BlockKind = iota
+    HeadingBlock
+    ListBlock
+    ItemBlock
+    CodeBlock
+    QuoteBlock
+    RuleBlock
+    TableBlock
+    DetailsBlock
+    RawBlock
+)
+
+// Block is a block-level element of a Markdown document. Text holds inline Markdown,
+// which ParseInline turns into spans, except in code and raw blocks where it is kept
+// as written
+type Block struct {
+    Kind     BlockKind
+    Level    int        // Heading level, 1 to 6
+    Text     string     // Paragraph, heading and item text; code body; details summary; alert title; raw line
+    Language string     // Code block language, "" when not given
+    Ordered  bool       // Numbered list
+    Task     bool       // List item starting with a "[ ]" or "[x]" checkbox
+    Checked  bool       // Ticked checkbox
+    Alert    string     // Upper-cased kind of a "> [!NOTE] Title" quote, "" for plain quotes
+    Rows     [][]string // Table cells as inline Markdown, header row first
+    Children []Block    // Items of a list; blocks nested in an item, quote or details block
+}
+
+// Box returns the checkbox of a task list item as written in Markdown, followed by a
+// space, or "" for other blocks
+func (b Block) Box() string {
+    switch {
+    case !b.Task:
+        return ""
+    case b.Checked:
+        return "[x] "
+    }
+    return "[ ] "
+}
+
+// Parser parses Markdown documents. The zero value parses plain Markdown; the
+// fields enable constructs that only some targets can represent
+type Parser struct {
+    Details   bool                   // Parse <details> blocks with an optional <summary> into DetailsBlock
+    Alerts    []string               // Alert kinds recognised in "> [!KIND] Title" quotes, such as "NOTE"
+    RawLine   func(line string) bool // Reports lines kept verbatim as RawBlock, such as macros on a line of their own
+    RawInline *regexp.Regexp         // Matches inline text kept verbatim as RawSpan
+}
+
+var (
+    headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+    listPattern      = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+(.*)$`)
+    taskPattern      = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+    alertPattern     = regexp.MustCompile(`^\[!(\w+)\]\s*(.*)$`)
+    summaryPattern   = regexp.MustCompile(`^<summary>(.*)</summary>$`)
+    tableRulePattern = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// Parse parses a plain Markdown document into blocks
+func Parse(content string) []Block {
+    return Parser{}.Parse(content)
+}
+
+// Parse parses a Markdown document into blocks
+func (p Parser) Parse(content string) []Block {
+    return p.blocks(strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n"))
+}
+
+// blocks parses a run of lines
+func (p Parser) blocks(lines []string) []Block {
+    var blocks []Block
+    var paragraph []string
+
+    flush := func() {
+        if len(paragraph) > 0 {
+            blocks = append(blocks, Block{Kind: ParagraphBlock, Text: strings.Join(paragraph, "\n")})
+            paragraph = nil
+        }
+    }
+
+    for i := 0; i < len(lines); i++ {
+        line := lines[i]
+        trimmed := strings.TrimSpace(line)
+
+        switch {
+        case trimmed == "":
+            flush()
+
+        case strings.HasPrefix(trimmed, "```"):
+            flush()
+            fence := trimmed[:runLength(trimmed, 0)]
+            code := Block{Kind: CodeBlock, Language: strings.TrimSpace(strings.TrimPrefix(trimmed, fence))}
+            var body []string
+            for i++; i < len(lines) && !closesFence(lines[i], fence); i++ {
+                body = append(body, lines[i])
+            }
+            code.Text = strings.Join(body, "\n")
+            blocks = append(blocks, code)
+
+        case p.RawLine != nil && p.RawLine(trimmed):
+            flush()
+            blocks = append(blocks, Block{Kind: RawBlock, Text: trimmed})
+
+        case p.Details && trimmed == "<details>":
+            flush()
+            var details Block
+            details, i = p.details(lines, i)
+            blocks = append(blocks, details)
+
+        case strings.HasPrefix(trimmed, ">"):
+            flush()
+            var quoted []string
+            for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+                q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+                quoted = append(quoted, strings.TrimPrefix(q, " "))
+            }
+            i--
+            blocks = append(blocks, p.quote(quoted))
+
+        case trimmed == "---" || trimmed == "***" || trimmed == "___":
+            flush()
+            blocks = append(blocks, Block{Kind: RuleBlock})
+
+        case headingPattern.MatchString(trimmed):
+            flush()
+            m := headingPattern.FindStringSubmatch(trimmed)
+            blocks = append(blocks, Block{Kind: HeadingBlock, Level: len(m[1]), Text: strings.TrimSpace(m[2])})
+
+        case listPattern.MatchString(line):
+            flush()
+            var list Block
+            list, i = p.list(lines, i)
+            blocks = append(blocks, list)
+            i--
+
+        case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableRulePattern.MatchString(strings.TrimSpace(lines[i+1])):
+            flush()
+            table := Block{Kind: TableBlock}
+            for row := i; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+                if i != row+1 {
+                    table.Rows = append(table.Rows, SplitRow(lines[i]))
+                }
+            }
+            i--
+            blocks = append(blocks, table)
+
+        default:
+            paragraph = append(paragraph, trimmed)
+        }
+    }
+    flush()
+
+    return blocks
+}
+
+// closesFence reports whether line closes a code block opened by fence: a run of at
+// least as many backticks and nothing else
+func closesFence(line, fence string) bool {
+    trimmed := strings.TrimSpace(line)
+    return len(trimmed) >= len(fence) && strings.Trim(trimmed, "`") == ""
+}
+
+// Fence returns the backtick run that opens and closes a code block holding code:
+// three backticks, or one more than the longest run inside the code
+func Fence(code string) string {
+    n := longestRun(code, '`') + 1
+    if n < 3 {
+        n = 3
+    }
+    return strings.Repeat("`", n)
+}
+
+// EscapeLines backslash-escapes the start of every line of paragraph text that Parse
+// would otherwise read as a block, such as a heading, list item, quote or rule.
+// Leading spaces, which Parse drops, are removed
+func EscapeLines(text string) string {
+    lines := strings.Split(text, "\n")
+    for i, line := range lines {
+        line = strings.TrimLeft(line, " \t")
+        switch {
+        case line == "":
+        case strings.HasPrefix(line, ">"), strings.HasPrefix(line, "|"), line == "---", headingPattern.MatchString(line),
+            line == "<details>", line == "</details>", summaryPattern.MatchString(line):
+            line = "\\" + line
+        case listPattern.MatchString(line):
+            // "1. text" keeps its number, so the dot is escaped instead
+            if m := listPattern.FindStringSubmatch(line); strings.HasSuffix(m[2], ".") {
+                line = strings.Replace(line, ".", "\\.", 1)
+            } else {
+                line = "\\" + line
+            }
+        }
+        lines[i] = line
+    }
+    return strings.Join(lines, "\n")
+}
+
+// details parses a <details> block starting at lines[i] and returns the index of
+// its closing line. A <summary> line directly after the opening tag becomes the text
+func (p Parser) details(lines []string, i int) (Block, int) {
+    details := Block{Kind: DetailsBlock}
+    var body []string
+    depth := 1
+    for i++; i < len(lines); i++ {
+        inner := strings.TrimSpace(lines[i])
+        if inner == "<details>" {
+            depth++
+        } else if inner == "</details>" {
+            depth--
+            if depth == 0 {
+                break
+            }
+        }
+        if m := summaryPattern.FindStringSubmatch(inner); m != nil && depth == 1 && details.Text == "" && len(body) == 0 {
+            details.Text = m[1]
+            continue
+        }
+        body = append(body, lines[i])
+    }
+    details.Children = p.blocks(body)
+    return details, i
+}
+
+// quote parses the lines of a blockquote with their markers removed, turning a first
+// line such as "[!NOTE] Title" into an alert when its kind is enabled
+func (p Parser) quote(lines []string) Block {
+    if m := alertPattern.FindStringSubmatch(lines[0]); m != nil {
+        for _, kind := range p.Alerts {
+            if strings.EqualFold(kind, m[1]) {
+                return Block{Kind: QuoteBlock, Alert: strings.ToUpper(m[1]), Text: m[2], Children: p.blocks(lines[1:])}
+            }
+        }
+    }
+    return Block{Kind: QuoteBlock, Children: p.blocks(lines)}
+}
+
+// list parses a list starting at lines[i] and returns the index of the first line
+// after it. Items indented further than the list become nested lists of the item
+// above them; a blank line only ends the list when no item follows it
+func (p Parser) list(lines []string, i int) (Block, int) {
+    first := listPattern.FindStringSubmatch(lines[i])
+    indent := indentWidth(first[1])
+    list := Block{Kind: ListBlock, Ordered: strings.HasSuffix(first[2], ".")}
+
+    for i < len(lines) {
+        m := listPattern.FindStringSubmatch(lines[i])
+        if m == nil {
+            if strings.TrimSpace(lines[i]) == "" && i+1 < len(lines) && listPattern.MatchString(lines[i+1]) {
+                i++
+                continue
+            }
+            break
+        }
+
+        width := indentWidth(m[1])
+        switch {
+        case width > indent && len(list.Children) > 0:
+            var nested Block
+            nested, i = p.list(lines, i)
+            last := &list.Children[len(list.Children)-1]
+            last.Children = append(last.Children, nested)
+            continue
+        case width < indent:
+            return list, i
+        case strings.HasSuffix(m[2], ".") != list.Ordered:
+            return list, i
+        }
+
+        item := Block{Kind: ItemBlock, Text: strings.TrimSpace(m[3])}
+        if t := taskPattern.FindStringSubmatch(item.Text); t != nil {
+            item.Task, item.Checked, item.Text = true, t[1] != " ", t[2]
+        }
+        list.Children = append(list.Children, item)
+        i++
+    }
+    return list, i
+}
+
+// indentWidth measures leading whitespace, counting a tab as four spaces
+func indentWidth(space string) int {
+    return len(strings.ReplaceAll(space, "\t", "    "))
+}
+
+// SplitRow splits a table row into its cells. A pipe escaped as "\|" stays in its
+// cell as a plain pipe
+func SplitRow(row string) []string {
+    row = strings.TrimSpace(row)
+    row = strings.TrimPrefix(row, "|")
+    if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+        row = row[:len(row)-1]
+    }
+
+    var cells []string
+    var cell strings.Builder
+    for i := 0; i < len(row); i++ {
+        switch {
+        case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+            cell.WriteByte('|')
+            i++
+        case row[i] == '|':
+            cells = append(cells, strings.TrimSpace(cell.String()))
+            cell.Reset()
+        default:
+            cell.WriteByte(row[i])
+        }
+    }
+    return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// Table writes a pipe table with a rule under the header row, escaping pipes in cells
+func Table(rows [][]string) string {
+    var lines []string
+    for i, row := range rows {
+        cells := make([]string, len(row))
+        for j, cell := range row {
+            cells[j] = strings.ReplaceAll(cell, "|", `\|`)
+        }
+        lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+        if i == 0 {
+            rule := make([]string, len(row))
+            for j := range rule {
+                rule[j] = "---"
+            }
+            lines = append(lines, "| "+strings.Join(rule, " | ")+" |")
+        }
+    }
+    return strings.Join(lines, "\n")
+}
@@ -0,0 +1,180 @@
+package markdown
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+        want    []Block
+    }{
+        {
+            name:    "paragraph lines are joined",
+            content: "first line\nsecond line\n\nnext",
+            want: []Block{
+                {Kind: ParagraphBlock, Text: "first line\nsecond line"},
+                {Kind: ParagraphBlock, Text: "next"},
+            },
+        },
+        {
+            name:    "heading and rule",
+            content: "## Setup\n---",
+            want: []Block{
+                {Kind: HeadingBlock, Level: 2, Text: "Setup"},
+                {Kind: RuleBlock},
+            },
+        },
+        {
+            name:    "nested lists",
+            content: "- one\n  - one.a\n    1. deep\n- two",
+            want: []Block{{Kind: ListBlock, Children: []Block{
+                {Kind: ItemBlock, Text: "one", Children: []Block{{Kind: ListBlock, Children: []Block{
+                    {Kind: ItemBlock, Text: "one.a", Children: []Block{{Kind: ListBlock, Ordered: true, Children: []Block{
+                        {Kind: ItemBlock, Text: "deep"},
+                    }}}},
+                }}}},
+                {Kind: ItemBlock, Text: "two"},
+            }}},
+        },
+        {
+            name:    "list type change starts a new list",
+            content: "- a\n1. b",
+            want: []Block{
+                {Kind: ListBlock, Children: []Block{{Kind: ItemBlock, Text: "a"}}},
+                {Kind: ListBlock, Ordered: true, Children: []Block{{Kind: ItemBlock, Text: "b"}}},
+            },
+        },
+        {
+            name:    "task items",
+            content: "- [ ] open\n- [x] done",
+            want: []Block{{Kind: ListBlock, Children: []Block{
+                {Kind: ItemBlock, Text: "open", Task: true},
+                {Kind: ItemBlock, Text: "done", Task: true, Checked: true},
+            }}},
+        },
+        {
+            name:    "longer fence holds a shorter one",
+            content: "````\n```\nx\n```\n````",
+            want:    []Block{{Kind: CodeBlock, Text: "```\nx\n```"}},
+        },
+        {
+            name:    "code fence keeps its lines",
+            content: "```go\nx := 1\n\n# not a heading\n```",
+            want:    []Block{{Kind: CodeBlock, Language: "go", Text: "x := 1\n\n# not a heading"}},
+        },
+        {
+            name:    "quote holds blocks",
+            content: "> quoted\n>\n> - item",
+            want: []Block{{Kind: QuoteBlock, Children: []Block{
+                {Kind: ParagraphBlock, Text: "quoted"},
+                {Kind: ListBlock, Children: []Block{{Kind: ItemBlock, Text: "item"}}},
+            }}},
+        },
+        {
+            name:    "alerts are plain quotes unless enabled",
+            content: "> [!NOTE] Title\n> body",
+            want:    []Block{{Kind: QuoteBlock, Children: []Block{{Kind: ParagraphBlock, Text: "[!NOTE] Title\nbody"}}}},
+        },
+        {
+            name:    "table with escaped pipe",
+            content: "| a | b \\| c |\n| --- | --- |\n| 1 | 2 |",
+            want:    []Block{{Kind: TableBlock, Rows: [][]string{{"a", "b | c"}, {"1", "2"}}}},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParserExtensions(t *testing.T) {
+    parser := Parser{
+        Details: true,
+        Alerts:  []string{"NOTE"},
+        RawLine: func(line string) bool { return line == "[TOC]" },
+    }
+
+    tests := []struct {
+        name    string
+        content string
+        want    []Block
+    }{
+        {
+            name:    "alert",
+            content: "> [!note] Title\n> body",
+            want:    []Block{{Kind: QuoteBlock, Alert: "NOTE", Text: "Title", Children: []Block{{Kind: ParagraphBlock, Text: "body"}}}},
+        },
+        {
+            name:    "unknown alert kind",
+            content: "> [!TIP] Title",
+            want:    []Block{{Kind: QuoteBlock, Children: []Block{{Kind: ParagraphBlock, Text: "[!TIP] Title"}}}},
+        },
+        {
+            name:    "details with summary",
+            content: "<details>\n<summary>More</summary>\n\ninside\n\n</details>",
+            want:    []Block{{Kind: DetailsBlock, Text: "More", Children: []Block{{Kind: ParagraphBlock, Text: "inside"}}}},
+        },
+        {
+            name:    "raw line ends a paragraph",
+            content: "text\n[TOC]",
+            want: []Block{
+                {Kind: ParagraphBlock, Text: "text"},
+                {Kind: RawBlock, Text: "[TOC]"},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := parser.Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestTable(t *testing.T) {
+    rows := [][]string{{"a", "b | c"}, {"1", "2"}}
+    table := Table(rows)
+    if want := "| a | b \\| c |\n| --- | --- |\n| 1 | 2 |"; table != want {
+        t.Fatalf("Table() = %q, want %q", table, want)
+    }
+    if got := Parse(table)[0].Rows; !reflect.DeepEqual(got, rows) {
+        t.Errorf("Parse(Table()) rows = %q, want %q", got, rows)
+    }
+}
+
+func TestEscapeLines(t *testing.T) {
+    tests := []string{
+        "# not a heading",
+        "1. not a list",
+        "- not a list",
+        "+ not a list",
+        "> not a quote",
+        "| not | a table |",
+        "---",
+        "<details>",
+        "first\n## second",
+        "#hashtag and 1.5 stay as they are",
+    }
+
+    for _, text := range tests {
+        t.Run(text, func(t *testing.T) {
+            blocks := Parse(EscapeLines(text))
+            if len(blocks) != 1 || blocks[0].Kind != ParagraphBlock || PlainText(ParseInline(blocks[0].Text)) != text {
+                t.Errorf("Parse(EscapeLines(%q)) = %+v", text, blocks)
+            }
+        })
+    }
+
+    if got := EscapeLines("#hashtag"); got != "#hashtag" {
+        t.Errorf("EscapeLines() = %q, want the line unchanged", got)
+    }
+}
@@ -0,0 +1,184 @@
+package notion
+
+import (
+    "context"
+)
+
+// blockEdit is a single step needed to turn an existing list of blocks into the rendered one
+type blockEdit struct {
+    Op  string // "keep", "update", "delete" or "insert"
+    Old notionBlock
+    New notionBlock
+}
+
+// diffBlocks computes the edits that turn old into new while preserving as many
+// existing block IDs as possible. Unchanged blocks are found with a longest common
+// subsequence on block content; changed blocks in between are updated in place when
+// a block of the same type is available, and deleted or inserted otherwise
+func diffBlocks(old, new []notionBlock) []blockEdit {
+    var edits []blockEdit
+    i, j := 0, 0
+    for _, pair := range commonBlocks(old, new) {
+        edits = append(edits, diffGap(old[i:pair[0]], new[j:pair[1]])...)
+        edits = append(edits, blockEdit{Op: "keep", Old: old[pair[0]], New: new[pair[1]]})
+        i, j = pair[0]+1, pair[1]+1
+    }
+    edits = append(edits, diffGap(old[i:], new[j:])...)
+
+    // Notion can only insert after an existing block, so blocks that have to go
+    // in front of every surviving block force this level to be rewritten
+    for _, e := range edits {
+        if e.Op == "insert" {
+            return rewriteLevel(edits)
+        }
+        if e.Op == "keep" || e.Op == "update" {
+            break
+        }
+    }
+
+    return edits
+}
+
+// commonBlocks returns index pairs of the longest common subsequence of old and new
+func commonBlocks(old, new []notionBlock) [][2]int {
+    lengths := make([][]int, len(old)+1)
+    for i := range lengths {
+        lengths[i] = make([]int, len(new)+1)
+    }
+    for i := len(old) - 1; i >= 0; i-- {
+        for j := len(new) - 1; j >= 0; j-- {
+            if !isProtectedBlock(old[i]) && old[i].key() == new[j].key() {
+                lengths[i][j] = lengths[i+1][j+1] + 1
+            } else if lengths[i+1][j] >= lengths[i][j+1] {
+                lengths[i][j] = lengths[i+1][j]
+            } else {
+                lengths[i][j] = lengths[i][j+1]
+            }
+        }
+    }
+
+    var pairs [][2]int
+    i, j := 0, 0
+    for i < len(old) && j < len(new) {
+        switch {
+        case !isProtectedBlock(old[i]) && old[i].key() == new[j].key():
+            pairs = append(pairs, [2]int{i, j})
+            i++
+            j++
+        case lengths[i+1][j] >= lengths[i][j+1]:
+            i++
+        default:
+            j++
+        }
+    }
+    return pairs
+}
+
+// diffGap pairs up the changed blocks between two unchanged ones
+func diffGap(old, new []notionBlock) []blockEdit {
+    var edits []blockEdit
+    cursor := 0
+    for _, n := range new {
+        match := -1
+        for k := cursor; k < len(old); k++ {
+            if !isProtectedBlock(old[k]) && old[k].Type == n.Type {
+                match = k
+                break
+            }
+        }
+        if match < 0 {
+            edits = append(edits, blockEdit{Op: "insert", New: n})
+            continue
+        }
+        edits = append(edits, removeBlocks(old[cursor:match])...)
+        edits = append(edits, blockEdit{Op: "update", Old: old[match], New: n})
+        cursor = match + 1
+    }
+    return append(edits, removeBlocks(old[cursor:])...)
+}
+
+// removeBlocks deletes the given blocks, leaving protected blocks where they are
+func removeBlocks(old []notionBlock) []blockEdit {
+    edits := make([]blockEdit, 0, len(old))
+    for _, o := range old {
+        if isProtectedBlock(o) {
+            edits = append(edits, blockEdit{Op: "keep", Old: o})
+        } else {
+            edits = append(edits, blockEdit{Op: "delete", Old: o})
+        }
+    }
+    return edits
+}
+
+// rewriteLevel replaces every kept or updated block with a delete and a fresh insert
+func rewriteLevel(edits []blockEdit) []blockEdit {
+    var deletes, inserts []blockEdit
+    for _, e := range edits {
+        switch {
+        case e.Op == "keep" && isProtectedBlock(e.Old):
+            deletes = append(deletes, e)
+        case e.Op == "keep" || e.Op == "update":
+            deletes = append(deletes, blockEdit{Op: "delete", Old: e.Old})
+            inserts = append(inserts, blockEdit{Op: "insert", New: e.New})
+        case e.Op == "delete":
+            deletes = append(deletes, e)
+        default:
+            inserts = append(inserts, e)
+        }
+    }
+    return append(deletes, inserts...)
+}
+
+// applyBlockDiff brings the children of parentID from old to new with the minimal
+// set of delete, update and append requests, recursing into nested blocks
+func (s *NotionServiceImpl) applyBlockDiff(ctx context.Context, parentID string, old, new []notionBlock) error {
+    prevID := ""
+    var pending []notionBlock
+
+    flush := func() error {
+        if len(pending) == 0 {
+            return nil
+        }
+        lastID, err := s.appendBlocks(ctx, parentID, prevID, pending)
+        if err != nil {
+            return err
+        }
+        prevID = lastID
+        pending = nil
+        return nil
+    }
+
+    for _, e := range diffBlocks(old, new) {
+        if e.Op == "insert" {
+            pending = append(pending, e.New)
+            continue
+        }
+        if err := flush(); err != nil {
+            return err
+        }
+
+        switch e.Op {
+        case "delete":
+            if err := s.deleteBlock(ctx, e.Old.ID); err != nil {
+                return err
+            }
+        case "update":
+            updated := e.New
+            updated.ID = e.Old.ID
+            if err := s.updateBlock(ctx, updated); err != nil {
+                return err
+            }
+            fallthrough
+        case "keep":
+            prevID = e.Old.ID
+            if isProtectedBlock(e.Old) {
+                continue
+            }
+            if err := s.applyBlockDiff(ctx, e.Old.ID, e.Old.Children, e.New.Children); err != nil {
+                return err
+            }
+        }
+    }
+
+    return flush()
+}
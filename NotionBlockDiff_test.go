@@ -0,0 +1,98 @@
+package notion
+
+import (
+    "reflect"
+    "testing"
+)
+
+// describeEdits summarises edits as "op old>new", naming old blocks by ID and new
+// blocks by text
+func describeEdits(edits []blockEdit) []string {
+    var out []string
+    for _, e := range edits {
+        switch e.Op {
+        case "insert":
+            out = append(out, "insert >"+e.New.Text)
+        case "delete":
+            out = append(out, "delete "+e.Old.ID)
+        default:
+            out = append(out, e.Op+" "+e.Old.ID+">"+e.New.Text)
+        }
+    }
+    return out
+}
+
+func TestDiffBlocks(t *testing.T) {
+    paragraph := func(id, text string) notionBlock { return notionBlock{ID: id, Type: "paragraph", Text: text} }
+    heading := func(id, text string) notionBlock { return notionBlock{ID: id, Type: "heading_1", Text: text} }
+
+    tests := []struct {
+        name string
+        old  []notionBlock
+        new  []notionBlock
+        want []string
+    }{
+        {
+            name: "unchanged",
+            old:  []notionBlock{heading("h", "Title"), paragraph("a", "one")},
+            new:  []notionBlock{heading("", "Title"), paragraph("", "one")},
+            want: []string{"keep h>Title", "keep a>one"},
+        },
+        {
+            name: "changed block is updated in place",
+            old:  []notionBlock{heading("h", "Title"), paragraph("a", "one"), paragraph("b", "two")},
+            new:  []notionBlock{heading("", "Title"), paragraph("", "uno"), paragraph("", "two")},
+            want: []string{"keep h>Title", "update a>uno", "keep b>two"},
+        },
+        {
+            name: "appended at the end",
+            old:  []notionBlock{paragraph("a", "one")},
+            new:  []notionBlock{paragraph("", "one"), paragraph("", "two")},
+            want: []string{"keep a>one", "insert >two"},
+        },
+        {
+            name: "removed from the middle",
+            old:  []notionBlock{paragraph("a", "one"), paragraph("b", "two"), paragraph("c", "three")},
+            new:  []notionBlock{paragraph("", "one"), paragraph("", "three")},
+            want: []string{"keep a>one", "delete b", "keep c>three"},
+        },
+        {
+            name: "type change replaces the block",
+            old:  []notionBlock{paragraph("a", "one"), paragraph("b", "two")},
+            new:  []notionBlock{paragraph("", "one"), heading("", "two")},
+            want: []string{"keep a>one", "insert >two", "delete b"},
+        },
+        {
+            name: "moved blocks keep the longest common run",
+            old:  []notionBlock{paragraph("a", "one"), paragraph("b", "two"), paragraph("c", "three")},
+            new:  []notionBlock{paragraph("", "two"), paragraph("", "three"), paragraph("", "one")},
+            want: []string{"delete a", "keep b>two", "keep c>three", "insert >one"},
+        },
+        {
+            name: "insert before every block rewrites the level",
+            old:  []notionBlock{paragraph("a", "one")},
+            new:  []notionBlock{heading("", "Title"), paragraph("", "one")},
+            want: []string{"delete a", "insert >Title", "insert >one"},
+        },
+        {
+            name: "protected blocks are never matched or deleted",
+            old:  []notionBlock{{ID: "p", Type: "child_page"}, paragraph("a", "one")},
+            new:  []notionBlock{paragraph("", "one")},
+            want: []string{"keep p>", "keep a>one"},
+        },
+        {
+            name: "empty page",
+            old:  nil,
+            new:  []notionBlock{paragraph("", "one")},
+            want: []string{"insert >one"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := describeEdits(diffBlocks(tt.old, tt.new)); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("diffBlocks() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
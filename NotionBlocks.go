@@ -0,0 +1,471 @@
+package notion
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strings"
+
+    "Support_Site_Sync/markdown"
+)
+
+// notionVersion is the API version sent with every block request
+const notionVersion = "2022-06-28"
+
+// maxRichTextLength is the longest text Notion accepts in a single rich text object
+const maxRichTextLength = 2000
+
+// maxAppendChildren is the most blocks Notion accepts in one append request
+const maxAppendChildren = 100
+
+// maxNestingDepth is the most levels of blocks Notion accepts in one request; deeper
+// children are appended to the IDs of their parents once those exist
+const maxNestingDepth = 2
+
+// notionBlock is a simplified view of a Notion block, used both for blocks
+// rendered from page content and for blocks read back from a page
+type notionBlock struct {
+    ID       string
+    Type     string
+    Text     string
+    Language string // code blocks only
+    Checked  bool   // to_do blocks only
+    Children []notionBlock
+}
+
+// key identifies a block's own content, ignoring its ID and children
+func (b notionBlock) key() string {
+    return fmt.Sprintf("%s|%s|%t|%s", b.Type, b.Language, b.Checked, b.Text)
+}
+
+// value builds the type-specific body of the block, as used for both append and update
+func (b notionBlock) value() map[string]interface{} {
+    if b.Type == "divider" {
+        return map[string]interface{}{}
+    }
+    value := map[string]interface{}{"rich_text": richText(b.Text)}
+    switch b.Type {
+    case "code":
+        language := b.Language
+        if language == "" {
+            language = "plain text"
+        }
+        value["language"] = language
+    case "to_do":
+        value["checked"] = b.Checked
+    }
+    return value
+}
+
+// payload builds the JSON body used to append the block and its children, down to
+// levels levels of blocks including the block itself
+func (b notionBlock) payload(levels int) map[string]interface{} {
+    value := b.value()
+    if len(b.Children) > 0 && levels > 1 {
+        children := make([]map[string]interface{}, 0, len(b.Children))
+        for _, child := range b.Children {
+            children = append(children, child.payload(levels-1))
+        }
+        value["children"] = children
+    }
+    return map[string]interface{}{
+        "object": "block",
+        "type":   b.Type,
+        b.Type:   value,
+    }
+}
+
+// richText splits text into rich text objects that respect Notion's length limit
+func richText(text string) []map[string]interface{} {
+    parts := []map[string]interface{}{}
+    runes := []rune(text)
+    for len(runes) > 0 {
+        n := len(runes)
+        if n > maxRichTextLength {
+            n = maxRichTextLength
+        }
+        parts = append(parts, map[string]interface{}{
+            "type": "text",
+            "text": map[string]interface{}{"content": string(runes[:n])},
+        })
+        runes = runes[n:]
+    }
+    return parts
+}
+
+// renderBlocks converts Markdown page content into Notion blocks. Headings, lists,
+// to-dos, quotes, code fences and dividers map to their Notion equivalents; list
+// items indented under another item become its children. Tables have no block
+// Notion can edit in place and are kept as paragraphs of Markdown
+func renderBlocks(content string) []notionBlock {
+    return convertBlocks(markdown.Parse(content))
+}
+
+// convertBlocks converts parsed Markdown blocks into Notion blocks
+func convertBlocks(blocks []markdown.Block) []notionBlock {
+    var converted []notionBlock
+    for _, block := range blocks {
+        switch block.Kind {
+        case markdown.ParagraphBlock:
+            converted = append(converted, notionBlock{Type: "paragraph", Text: block.Text})
+        case markdown.HeadingBlock:
+            level := block.Level
+            if level > 3 {
+                level = 3
+            }
+            converted = append(converted, notionBlock{Type: fmt.Sprintf("heading_%d", level), Text: block.Text})
+        case markdown.CodeBlock:
+            converted = append(converted, notionBlock{Type: "code", Language: block.Language, Text: block.Text})
+        case markdown.RuleBlock:
+            converted = append(converted, notionBlock{Type: "divider"})
+        case markdown.TableBlock:
+            converted = append(converted, notionBlock{Type: "paragraph", Text: markdown.Table(block.Rows)})
+        case markdown.QuoteBlock:
+            quote := notionBlock{Type: "quote"}
+            children := block.Children
+            if len(children) > 0 && children[0].Kind == markdown.ParagraphBlock {
+                quote.Text = children[0].Text
+                children = children[1:]
+            }
+            quote.Children = convertBlocks(children)
+            converted = append(converted, quote)
+        case markdown.ListBlock:
+            for _, item := range block.Children {
+                converted = append(converted, listItem(block, item))
+            }
+        }
+    }
+    return converted
+}
+
+// listItem converts an item of a list, with the blocks nested under it as children
+func listItem(list, item markdown.Block) notionBlock {
+    block := notionBlock{Type: "bulleted_list_item", Text: item.Text, Children: convertBlocks(item.Children)}
+    switch {
+    case item.Task:
+        block.Type, block.Checked = "to_do", item.Checked
+    case list.Ordered:
+        block.Type = "numbered_list_item"
+    }
+    return block
+}
+
+// blocksMarkdown converts blocks read from a page back into Markdown, the reverse of
+// renderBlocks. Nested list items are indented two spaces per level; blocks with no
+// Markdown equivalent, such as sub-pages, are left out
+func blocksMarkdown(blocks []notionBlock) string {
+    var out []string
+    writeBlocks(&out, blocks, "", "")
+    return strings.Join(out, "\n")
+}
+
+// writeBlocks appends the Markdown lines of blocks after indent, which holds the
+// indentation of nested list items and the markers of quotes, separating blocks with
+// blank lines except between list items. previous is the type of the block written
+// just before, the parent item for nested blocks
+func writeBlocks(out *[]string, blocks []notionBlock, indent, previous string) {
+    number := 0
+    for _, block := range blocks {
+        var lines []string
+        switch block.Type {
+        case "paragraph":
+            lines = strings.Split(block.Text, "\n")
+        case "heading_1":
+            lines = []string{"# " + block.Text}
+        case "heading_2":
+            lines = []string{"## " + block.Text}
+        case "heading_3":
+            lines = []string{"### " + block.Text}
+        case "quote":
+            lines = strings.Split(block.Text, "\n")
+            for i, line := range lines {
+                lines[i] = "> " + line
+            }
+        case "divider":
+            lines = []string{"---"}
+        case "code":
+            lines = append([]string{"```" + block.Language}, strings.Split(block.Text, "\n")...)
+            lines = append(lines, "```")
+        case "bulleted_list_item":
+            lines = []string{"- " + block.Text}
+        case "numbered_list_item":
+            if previous != block.Type {
+                number = 0
+            }
+            number++
+            lines = []string{fmt.Sprintf("%d. %s", number, block.Text)}
+        case "to_do":
+            box := "[ ]"
+            if block.Checked {
+                box = "[x]"
+            }
+            lines = []string{"- " + box + " " + block.Text}
+        default:
+            continue
+        }
+
+        if len(*out) > 0 && !(isListBlock(block.Type) && isListBlock(previous)) {
+            *out = append(*out, strings.TrimRight(indent, " "))
+        }
+        for _, line := range lines {
+            *out = append(*out, indent+line)
+        }
+        switch {
+        case isListBlock(block.Type) && len(block.Children) > 0:
+            writeBlocks(out, block.Children, indent+"  ", block.Type)
+        case block.Type == "quote" && len(block.Children) > 0:
+            writeBlocks(out, block.Children, indent+"> ", "")
+        }
+        previous = block.Type
+    }
+}
+
+// isListBlock reports whether blocks of this type can hold nested list items
+func isListBlock(blockType string) bool {
+    return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do"
+}
+
+// blockFromResult converts a block object returned by the Notion API
+func blockFromResult(result map[string]interface{}) notionBlock {
+    block := notionBlock{}
+    block.ID, _ = result["id"].(string)
+    block.Type, _ = result["type"].(string)
+
+    value, _ := result[block.Type].(map[string]interface{})
+    if texts, ok := value["rich_text"].([]interface{}); ok {
+        var text strings.Builder
+        for _, t := range texts {
+            if part, ok := t.(map[string]interface{}); ok {
+                plain, _ := part["plain_text"].(string)
+                text.WriteString(plain)
+            }
+        }
+        block.Text = text.String()
+    }
+    block.Language, _ = value["language"].(string)
+    if block.Type == "code" && block.Language == "plain text" {
+        block.Language = ""
+    }
+    block.Checked, _ = value["checked"].(bool)
+
+    return block
+}
+
+// isProtectedBlock reports whether a block must never be touched by content sync,
+// such as sub-pages and inline databases which would be archived along with the block
+func isProtectedBlock(block notionBlock) bool {
+    switch block.Type {
+    case "child_page", "child_database", "synced_block", "unsupported":
+        return true
+    }
+    return false
+}
+
+// listBlocks fetches all children of a block or page, following pagination and
+// descending into nested blocks
+func (s *NotionServiceImpl) listBlocks(ctx context.Context, parentID string) ([]notionBlock, error) {
+    var blocks []notionBlock
+    cursor := ""
+
+    for {
+        url := fmt.Sprintf("%s/blocks/%s/children?page_size=100", s.baseURL, parentID)
+        if cursor != "" {
+            url += "&start_cursor=" + cursor
+        }
+
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "list blocks", "GET", url, nil, &result); err != nil {
+            return nil, err
+        }
+
+        items, _ := result["results"].([]interface{})
+        for _, item := range items {
+            raw, ok := item.(map[string]interface{})
+            if !ok {
+                continue
+            }
+            block := blockFromResult(raw)
+            if hasChildren, _ := raw["has_children"].(bool); hasChildren && !isProtectedBlock(block) {
+                children, err := s.listBlocks(ctx, block.ID)
+                if err != nil {
+                    return nil, err
+                }
+                block.Children = children
+            }
+            blocks = append(blocks, block)
+        }
+
+        hasMore, _ := result["has_more"].(bool)
+        cursor, _ = result["next_cursor"].(string)
+        if !hasMore || cursor == "" {
+            break
+        }
+    }
+
+    return blocks, nil
+}
+
+// appendBlocks appends blocks under parentID after the block with ID after, or at
+// the end when after is empty. It returns the ID of the last block appended
+func (s *NotionServiceImpl) appendBlocks(ctx context.Context, parentID, after string, blocks []notionBlock) (string, error) {
+    url := fmt.Sprintf("%s/blocks/%s/children", s.baseURL, parentID)
+
+    for start := 0; start < len(blocks); start += maxAppendChildren {
+        end := start + maxAppendChildren
+        if end > len(blocks) {
+            end = len(blocks)
+        }
+
+        children := make([]map[string]interface{}, 0, end-start)
+        for _, block := range blocks[start:end] {
+            children = append(children, block.payload(maxNestingDepth))
+        }
+        reqBody := map[string]interface{}{"children": children}
+        if after != "" {
+            reqBody["after"] = after
+        }
+
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "append blocks", "PATCH", url, reqBody, &result); err != nil {
+            return "", err
+        }
+
+        // The response lists the newly created blocks in order
+        items, _ := result["results"].([]interface{})
+        ids := make([]string, 0, len(items))
+        for _, item := range items {
+            created, _ := item.(map[string]interface{})
+            id, _ := created["id"].(string)
+            ids = append(ids, id)
+        }
+        if len(ids) > 0 {
+            after = ids[len(ids)-1]
+        }
+        if err := s.appendDeeper(ctx, ids, blocks[start:end], maxNestingDepth); err != nil {
+            return "", err
+        }
+    }
+
+    return after, nil
+}
+
+// appendDeeper appends the blocks that were left out of a request for being nested
+// more than levels deep. ids are the IDs of the created blocks, in the same order
+func (s *NotionServiceImpl) appendDeeper(ctx context.Context, ids []string, blocks []notionBlock, levels int) error {
+    for i, block := range blocks {
+        if len(block.Children) == 0 || i >= len(ids) {
+            continue
+        }
+        if levels <= 1 {
+            if _, err := s.appendBlocks(ctx, ids[i], "", block.Children); err != nil {
+                return err
+            }
+            continue
+        }
+        if !hasChildren(block.Children) {
+            continue
+        }
+        childIDs, err := s.childIDs(ctx, ids[i])
+        if err != nil {
+            return err
+        }
+        if err := s.appendDeeper(ctx, childIDs, block.Children, levels-1); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// hasChildren reports whether any of blocks has children of its own
+func hasChildren(blocks []notionBlock) bool {
+    for _, block := range blocks {
+        if len(block.Children) > 0 {
+            return true
+        }
+    }
+    return false
+}
+
+// childIDs returns the IDs of the direct children of a block or page, in order
+func (s *NotionServiceImpl) childIDs(ctx context.Context, parentID string) ([]string, error) {
+    var ids []string
+    cursor := ""
+    for {
+        url := fmt.Sprintf("%s/blocks/%s/children?page_size=100", s.baseURL, parentID)
+        if cursor != "" {
+            url += "&start_cursor=" + cursor
+        }
+
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "list blocks", "GET", url, nil, &result); err != nil {
+            return nil, err
+        }
+        items, _ := result["results"].([]interface{})
+        for _, item := range items {
+            block, _ := item.(map[string]interface{})
+            id, _ := block["id"].(string)
+            ids = append(ids, id)
+        }
+
+        hasMore, _ := result["has_more"].(bool)
+        cursor, _ = result["next_cursor"].(string)
+        if !hasMore || cursor == "" {
+            return ids, nil
+        }
+    }
+}
+
+// updateBlock replaces the content of an existing block, keeping its ID
+func (s *NotionServiceImpl) updateBlock(ctx context.Context, block notionBlock) error {
+    url := fmt.Sprintf("%s/blocks/%s", s.baseURL, block.ID)
+    reqBody := map[string]interface{}{block.Type: block.value()}
+    return s.doRequest(ctx, "update block", "PATCH", url, reqBody, nil)
+}
+
+// deleteBlock archives a block
+func (s *NotionServiceImpl) deleteBlock(ctx context.Context, id string) error {
+    url := fmt.Sprintf("%s/blocks/%s", s.baseURL, id)
+    return s.doRequest(ctx, "delete block", "DELETE", url, nil, nil)
+}
+
+// doRequest sends an authenticated request to the Notion API and decodes the
+// response into out when it is not nil
+func (s *NotionServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    var reqBody *bytes.Buffer
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    } else {
+        reqBody = &bytes.Buffer{}
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+    req.Header.Set("Notion-Version", notionVersion)
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
@@ -0,0 +1,164 @@
+package notion
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestRenderBlocks(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+        want    []notionBlock
+    }{
+        {
+            name:    "headings deeper than three",
+            content: "#### Detail",
+            want:    []notionBlock{{Type: "heading_3", Text: "Detail"}},
+        },
+        {
+            name:    "nested list items become children",
+            content: "- one\n  1. first\n  - [x] done",
+            want: []notionBlock{{Type: "bulleted_list_item", Text: "one", Children: []notionBlock{
+                {Type: "numbered_list_item", Text: "first"},
+                {Type: "to_do", Text: "done", Checked: true},
+            }}},
+        },
+        {
+            name:    "quote with a list",
+            content: "> note\n>\n> - item",
+            want: []notionBlock{{Type: "quote", Text: "note", Children: []notionBlock{
+                {Type: "bulleted_list_item", Text: "item"},
+            }}},
+        },
+        {
+            name:    "tables stay Markdown",
+            content: "| a | b |\n|---|---|\n| 1 | 2 |",
+            want:    []notionBlock{{Type: "paragraph", Text: "| a | b |\n| --- | --- |\n| 1 | 2 |"}},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := renderBlocks(tt.content); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("renderBlocks(%q) = %+v, want %+v", tt.content, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBlocksMarkdownRoundTrip(t *testing.T) {
+    contents := []string{
+        "# Title\n\nSome text\nacross lines",
+        "- a\n  - b\n    1. c\n    2. d\n- [x] e\n- [ ] f",
+        "```go\nx := 1\n\ny := 2\n```\n\n---",
+        "> quoted\n> more\n>\n> - item\n>   - nested",
+        "| a | b \\| c |\n| --- | --- |\n| 1 | **2** |",
+    }
+
+    for _, content := range contents {
+        t.Run(strings.SplitN(content, "\n", 2)[0], func(t *testing.T) {
+            if got := blocksMarkdown(renderBlocks(content)); got != content {
+                t.Errorf("blocksMarkdown(renderBlocks(%q)) = %q", content, got)
+            }
+        })
+    }
+}
+
+func TestBlocksMarkdownSkipsSubpages(t *testing.T) {
+    blocks := []notionBlock{
+        {Type: "paragraph", Text: "before"},
+        {Type: "child_page", Text: "Sub-page"},
+        {Type: "paragraph", Text: "after"},
+    }
+    if got, want := blocksMarkdown(blocks), "before\n\nafter"; got != want {
+        t.Errorf("blocksMarkdown() = %q, want %q", got, want)
+    }
+}
+
+// fakeNotion stores appended blocks by parent and, like Notion, rejects requests that
+// nest blocks more than maxNestingDepth levels deep
+type fakeNotion struct {
+    children map[string][]string // Parent ID to child IDs
+    types    map[string]string   // Block ID to its type
+    next     int
+}
+
+func (f *fakeNotion) store(parent string, payloads []interface{}, level int) ([]interface{}, bool) {
+    var created []interface{}
+    for _, item := range payloads {
+        payload := item.(map[string]interface{})
+        if level > maxNestingDepth {
+            return nil, false
+        }
+        f.next++
+        id := fmt.Sprintf("b%d", f.next)
+        f.children[parent] = append(f.children[parent], id)
+        f.types[id] = payload["type"].(string)
+        value, _ := payload[f.types[id]].(map[string]interface{})
+        if nested, ok := value["children"].([]interface{}); ok {
+            if _, ok := f.store(id, nested, level+1); !ok {
+                return nil, false
+            }
+        }
+        created = append(created, map[string]interface{}{"id": id})
+    }
+    return created, true
+}
+
+func (f *fakeNotion) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    parent := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/blocks/"), "/children")
+    if r.Method == "GET" {
+        var results []interface{}
+        for _, id := range f.children[parent] {
+            results = append(results, map[string]interface{}{"id": id})
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+        return
+    }
+
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    created, ok := f.store(parent, body["children"].([]interface{}), 1)
+    if !ok {
+        http.Error(w, "too deeply nested", http.StatusBadRequest)
+        return
+    }
+    json.NewEncoder(w).Encode(map[string]interface{}{"results": created})
+}
+
+// tree describes the stored blocks under parent as "type(children)" strings
+func (f *fakeNotion) tree(parent string) string {
+    var parts []string
+    for _, id := range f.children[parent] {
+        part := f.types[id]
+        if nested := f.tree(id); nested != "" {
+            part += "(" + nested + ")"
+        }
+        parts = append(parts, part)
+    }
+    return strings.Join(parts, " ")
+}
+
+func TestAppendBlocksDeepNesting(t *testing.T) {
+    fake := &fakeNotion{children: map[string][]string{}, types: map[string]string{}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewNotionService(server.URL, "key")
+    blocks := renderBlocks("- a\n  - b\n    - c\n      1. d\n- e")
+    if _, err := s.appendBlocks(context.Background(), "page", "", blocks); err != nil {
+        t.Fatal(err)
+    }
+
+    want := "bulleted_list_item(bulleted_list_item(bulleted_list_item(numbered_list_item))) bulleted_list_item"
+    if got := fake.tree("page"); got != want {
+        t.Errorf("stored blocks = %s, want %s", got, want)
+    }
+}
@@ -0,0 +1,120 @@
+package notion
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// ListPages lists the pages shared with the integration through the search endpoint,
+// paging with start_cursor. Pages under another page report it as their parent
+func (s *NotionServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.searchPages)
+}
+
+// searchPages fetches one page of search results restricted to pages
+func (s *NotionServiceImpl) searchPages(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    pages, next, err := s.search(ctx, cursor)
+    if err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    for _, page := range pages {
+        summary := listing.Summary{Title: pageTitle(page), ParentID: parentPageID(page)}
+        summary.ID, _ = page["id"].(string)
+        if edited, ok := page["last_edited_time"].(string); ok {
+            summary.Updated, _ = time.Parse(time.RFC3339, edited)
+        }
+        summaries = append(summaries, summary)
+    }
+    return summaries, next, nil
+}
+
+// RootPages returns the pages the integration is shared on, which synced pages live
+// under: shared pages at the top of the workspace or under a page that is not shared
+func (s *NotionServiceImpl) RootPages(ctx context.Context) (map[string]bool, error) {
+    roots := map[string]bool{}
+    parents := map[string]string{}
+    for cursor := ""; ; {
+        pages, next, err := s.search(ctx, cursor)
+        if err != nil {
+            return nil, err
+        }
+        for _, page := range pages {
+            id, _ := page["id"].(string)
+            parent, _ := page["parent"].(map[string]interface{})
+            switch kind, _ := parent["type"].(string); kind {
+            case "workspace":
+                roots[id] = true
+            case "page_id":
+                parents[id] = parentPageID(page)
+            }
+        }
+        if next == "" {
+            break
+        }
+        cursor = next
+    }
+
+    for id, parent := range parents {
+        if _, shared := parents[parent]; !shared && !roots[parent] {
+            roots[id] = true
+        }
+    }
+    return roots, nil
+}
+
+// search fetches one page of raw search results restricted to pages, with the cursor
+// of the next one
+func (s *NotionServiceImpl) search(ctx context.Context, cursor string) ([]map[string]interface{}, string, error) {
+    body := map[string]interface{}{
+        "filter":    map[string]interface{}{"property": "object", "value": "page"},
+        "page_size": 100,
+    }
+    if cursor != "" {
+        body["start_cursor"] = cursor
+    }
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "search pages", "POST", fmt.Sprintf("%s/search", s.baseURL), body, &result); err != nil {
+        return nil, "", err
+    }
+
+    var pages []map[string]interface{}
+    items, _ := result["results"].([]interface{})
+    for _, item := range items {
+        if page, ok := item.(map[string]interface{}); ok {
+            pages = append(pages, page)
+        }
+    }
+
+    next := ""
+    if hasMore, _ := result["has_more"].(bool); hasMore {
+        next, _ = result["next_cursor"].(string)
+    }
+    return pages, next, nil
+}
+
+// pageTitle returns the plain text of a page's title property, whatever its name
+func pageTitle(page map[string]interface{}) string {
+    properties, _ := page["properties"].(map[string]interface{})
+    for _, value := range properties {
+        property, _ := value.(map[string]interface{})
+        if kind, _ := property["type"].(string); kind != "title" {
+            continue
+        }
+        var title strings.Builder
+        parts, _ := property["title"].([]interface{})
+        for _, part := range parts {
+            text, _ := part.(map[string]interface{})
+            plain, _ := text["plain_text"].(string)
+            title.WriteString(plain)
+        }
+        return title.String()
+    }
+    return ""
+}
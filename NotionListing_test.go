@@ -0,0 +1,119 @@
+package notion
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakeSearch serves the search endpoint two pages at a time, with the index of the
+// next result as cursor
+type fakeSearch struct {
+    pages   []map[string]interface{}
+    cursors []string // start_cursor of each search, "" for the first
+}
+
+// searchPage builds a search result for a page under parent, which is a page ID or
+// "workspace"
+func searchPage(id, title, parent string) map[string]interface{} {
+    page := map[string]interface{}{
+        "id":               id,
+        "last_edited_time": "2024-03-01T12:00:00Z",
+        "properties": map[string]interface{}{
+            "Name": map[string]interface{}{
+                "type":  "title",
+                "title": []interface{}{map[string]interface{}{"plain_text": title}},
+            },
+        },
+    }
+    if parent == "workspace" {
+        page["parent"] = map[string]interface{}{"type": "workspace", "workspace": true}
+    } else {
+        page["parent"] = map[string]interface{}{"type": "page_id", "page_id": parent}
+    }
+    return page
+}
+
+func (f *fakeSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    var body struct {
+        Filter      map[string]string `json:"filter"`
+        StartCursor string            `json:"start_cursor"`
+    }
+    json.NewDecoder(r.Body).Decode(&body)
+    if r.Method != "POST" || r.URL.Path != "/search" || body.Filter["value"] != "page" {
+        http.NotFound(w, r)
+        return
+    }
+    f.cursors = append(f.cursors, body.StartCursor)
+
+    start, _ := strconv.Atoi(body.StartCursor)
+    end := start + 2
+    if end > len(f.pages) {
+        end = len(f.pages)
+    }
+    result := map[string]interface{}{"results": f.pages[start:end], "has_more": end < len(f.pages)}
+    if end < len(f.pages) {
+        result["next_cursor"] = strconv.Itoa(end)
+    }
+    json.NewEncoder(w).Encode(result)
+}
+
+func TestListPages(t *testing.T) {
+    fake := &fakeSearch{pages: []map[string]interface{}{
+        searchPage("p1", "Home", "workspace"),
+        searchPage("p2", "Guide", "p1"),
+        searchPage("p3", "Install", "p2"),
+    }}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewNotionService(server.URL, "key")
+    got, err := s.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    want := []listing.Summary{
+        {ID: "p1", Title: "Home", Updated: updated},
+        {ID: "p2", Title: "Guide", Updated: updated, ParentID: "p1"},
+        {ID: "p3", Title: "Install", Updated: updated, ParentID: "p2"},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListPages() = %v, want %v", got, want)
+    }
+    if want := []string{"", "2"}; !reflect.DeepEqual(fake.cursors, want) {
+        t.Errorf("start cursors = %q, want %q", fake.cursors, want)
+    }
+}
+
+func TestRootPages(t *testing.T) {
+    fake := &fakeSearch{pages: []map[string]interface{}{
+        searchPage("p1", "Home", "workspace"),
+        searchPage("p2", "Guide", "p1"),
+        searchPage("p3", "Install", "p2"),
+        searchPage("p4", "Shared subpage", "private"),
+        searchPage("p5", "Below it", "p4"),
+    }}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewNotionService(server.URL, "key")
+    got, err := s.RootPages(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := map[string]bool{"p1": true, "p4": true}; !reflect.DeepEqual(got, want) {
+        t.Errorf("RootPages() = %v, want %v", got, want)
+    }
+    if len(fake.cursors) != 3 {
+        t.Errorf("searched %d times, want every page of results", len(fake.cursors))
+    }
+}
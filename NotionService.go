@@ -1,6 +1,10 @@
 package notion
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // NotionService defines the methods for interacting with Notion
 type NotionService interface {
@@ -8,4 +12,5 @@ type NotionService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
@@ -7,6 +7,7 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "strings"
 )
 
 // NotionServiceImpl is the implementation of the NotionService interface
@@ -20,13 +21,23 @@ func NewNotionService(baseURL, apiKey string) *NotionServiceImpl {
     return &NotionServiceImpl{baseURL: baseURL, apiKey: apiKey}
 }
 
-// CreatePage creates a new page in Notion
+// CreatePage creates a new page in Notion, under its parent page when it has one
 func (s *NotionServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
     url := fmt.Sprintf("%s/pages", s.baseURL)
+
+    // Notion accepts a limited number of children on create; the rest are appended afterwards
+    blocks := renderBlocks(page.Content)
+    first := blocks
+    if len(first) > maxAppendChildren {
+        first = first[:maxAppendChildren]
+    }
+    children := make([]map[string]interface{}, 0, len(first))
+    for _, block := range first {
+        children = append(children, block.payload(maxNestingDepth))
+    }
+
     reqBody, _ := json.Marshal(map[string]interface{}{
-        "parent": map[string]interface{}{
-            "database_id": "YOUR_DATABASE_ID", // Replace with your actual database ID
-        },
+        "parent": parentPayload(page),
         "properties": map[string]interface{}{
             "title": map[string]interface{}{
                 "title": []map[string]interface{}{
@@ -38,22 +49,7 @@ func (s *NotionServiceImpl) CreatePage(ctx context.Context, page Page) (string,
                 },
             },
         },
-        "children": []map[string]interface{}{
-            {
-                "object": "block",
-                "type":   "paragraph",
-                "paragraph": map[string]interface{}{
-                    "text": []map[string]interface{}{
-                        {
-                            "type": "text",
-                            "text": map[string]interface{}{
-                                "content": page.Content,
-                            },
-                        },
-                    },
-                },
-            },
-        },
+        "children": children,
     })
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
@@ -80,10 +76,31 @@ func (s *NotionServiceImpl) CreatePage(ctx context.Context, page Page) (string,
         return "", fmt.Errorf("failed to parse page ID")
     }
 
+    // The response does not list the created blocks, so their IDs are read back to
+    // append children nested too deep for the create request
+    if hasChildren(first) {
+        ids, err := s.childIDs(ctx, pageID)
+        if err != nil {
+            return pageID, err
+        }
+        if err := s.appendDeeper(ctx, ids, first, maxNestingDepth); err != nil {
+            return pageID, err
+        }
+    }
+    if len(blocks) > len(first) {
+        if _, err := s.appendBlocks(ctx, pageID, "", blocks[len(first):]); err != nil {
+            return pageID, err
+        }
+    }
+
     return pageID, nil
 }
 
-// UpdatePage updates an existing page in Notion
+// UpdatePage updates an existing page in Notion. The title is patched on the page
+// itself, while the body is synced by diffing the page's existing blocks against the
+// rendered content so unchanged blocks keep their IDs, comments and backlinks. The
+// API cannot move pages, so a page whose parent changed is updated in place and an
+// error reports that it has to be moved by hand
 func (s *NotionServiceImpl) UpdatePage(ctx context.Context, page Page) error {
     url := fmt.Sprintf("%s/pages/%s", s.baseURL, page.ID)
 
@@ -99,22 +116,6 @@ func (s *NotionServiceImpl) UpdatePage(ctx context.Context, page Page) error {
                 },
             },
         },
-        "children": []map[string]interface{}{
-            {
-                "object": "block",
-                "type":   "paragraph",
-                "paragraph": map[string]interface{}{
-                    "text": []map[string]interface{}{
-                        {
-                            "type": "text",
-                            "text": map[string]interface{}{
-                                "content": page.Content,
-                            },
-                        },
-                    },
-                },
-            },
-        },
     })
 
     req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(reqBody))
@@ -132,6 +133,21 @@ func (s *NotionServiceImpl) UpdatePage(ctx context.Context, page Page) error {
         return fmt.Errorf("failed to update page: %s - %s", resp.Status, body)
     }
 
+    var result map[string]interface{}
+    json.NewDecoder(resp.Body).Decode(&result)
+
+    existing, err := s.listBlocks(ctx, page.ID)
+    if err != nil {
+        return err
+    }
+
+    if err := s.applyBlockDiff(ctx, page.ID, existing, renderBlocks(page.Content)); err != nil {
+        return err
+    }
+
+    if current := parentPageID(result); page.ParentID != "" && !sameNotionID(current, page.ParentID) {
+        return fmt.Errorf("page %s is under %q in Notion but belongs under %s; the Notion API cannot move pages, so move it in Notion", page.ID, current, page.ParentID)
+    }
     return nil
 }
 
@@ -156,34 +172,53 @@ func (s *NotionServiceImpl) DeletePage(ctx context.Context, id string) error {
     return nil
 }
 
-// GetPage retrieves a page from Notion
-func (s *NotionServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
+// ArchivePage archives a page, which moves it to the trash where it can be restored
+func (s *NotionServiceImpl) ArchivePage(ctx context.Context, id string) error {
     url := fmt.Sprintf("%s/pages/%s", s.baseURL, id)
+    return s.doRequest(ctx, "archive page", "PATCH", url, map[string]interface{}{"archived": true}, nil)
+}
 
-    req, _ := http.NewRequest("GET", url, nil)
-    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+// GetPage retrieves a page from Notion, converting its blocks back into Markdown
+func (s *NotionServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
+    var result map[string]interface{}
+    url := fmt.Sprintf("%s/pages/%s", s.baseURL, id)
+    if err := s.doRequest(ctx, "get page", "GET", url, nil, &result); err != nil {
+        return Page{}, err
+    }
 
-    resp, err := http.DefaultClient.Do(req)
+    blocks, err := s.listBlocks(ctx, id)
     if err != nil {
         return Page{}, err
     }
-    defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        body, _ := ioutil.ReadAll(resp.Body)
-        return Page{}, fmt.Errorf("failed to get page: %s - %s", resp.Status, body)
-    }
+    return Page{
+        ID:       id,
+        Title:    pageTitle(result),
+        Content:  blocksMarkdown(blocks),
+        ParentID: parentPageID(result),
+    }, nil
+}
 
-    var result map[string]interface{}
-    json.NewDecoder(resp.Body).Decode(&result)
+// parentPayload returns the parent a page is created under: its parent page when it
+// has one and the sync database otherwise
+func parentPayload(page Page) map[string]interface{} {
+    if page.ParentID != "" {
+        return map[string]interface{}{"page_id": page.ParentID}
+    }
+    return map[string]interface{}{
+        "database_id": "YOUR_DATABASE_ID", // Replace with your actual database ID
+    }
+}
 
-    properties := result["properties"].(map[string]interface{})
-    title := properties["title"].(map[string]interface{})["title"].([]interface{})[0].(map[string]interface{})["text"].(map[string]interface{})["content"].(string)
-    content := result["children"].([]interface{})[0].(map[string]interface{})["paragraph"].(map[string]interface{})["text"].([]interface{})[0].(map[string]interface{})["text"].(map[string]interface{})["content"].(string)
+// parentPageID returns the ID of the page a page object sits under, or "" when its
+// parent is a database or the workspace
+func parentPageID(page map[string]interface{}) string {
+    parent, _ := page["parent"].(map[string]interface{})
+    id, _ := parent["page_id"].(string)
+    return id
+}
 
-    return Page{
-        ID:      id,
-        Title:   title,
-        Content: content,
-    }, nil
+// sameNotionID compares two Notion IDs, which are accepted with or without dashes
+func sameNotionID(a, b string) bool {
+    return strings.EqualFold(strings.ReplaceAll(a, "-", ""), strings.ReplaceAll(b, "-", ""))
 }
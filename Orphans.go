@@ -0,0 +1,240 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// Kinds of orphan found by the orphan report
+const (
+    OrphanUnknown = "unknown" // On the service but not in the ID mapping, such as a page created by hand
+    OrphanRemoved = "removed" // Mapped on the service, but its canonical page no longer exists
+    OrphanMissing = "missing" // Mapped for an existing canonical page, but no longer on the service
+)
+
+// Orphan is a page that exists on one side of the sync only
+type Orphan struct {
+    Service string
+    Kind    string          // OrphanUnknown, OrphanRemoved or OrphanMissing
+    PageID  string          // Canonical page, empty for unknown pages
+    Remote  listing.Summary // Page on the service; only the ID is known for missing pages
+}
+
+// PruneOptions controls the orphan report and whether orphans are removed
+type PruneOptions struct {
+    Prune           bool      // Archive or delete unknown and removed pages instead of only reporting them
+    Delete          bool      // Delete pages rather than archive them, needed on services that cannot archive
+    MaxDeletions    int       // Most pages pruned in one run, defaults to 10
+    ProtectedLabels []string  // Pages carrying any of these labels are never pruned; when set, services that cannot report labels are not pruned
+    Out             io.Writer // Report and pruning results
+}
+
+// Orphans reports the pages each service has that the ID mapping does not know of,
+// the mapped pages whose canonical page was removed, and the mapped pages that are
+// gone from the service. With Prune set, unknown and removed pages are then archived,
+// or deleted, up to MaxDeletions per run and skipping protected pages. Root pages such
+// as configured parents are left out. A service that cannot be listed is reported and
+// skipped
+func Orphans(ctx context.Context, services []ServiceInterface, pages []Page, mapping *IDMapping, options PruneOptions) error {
+    var orphans []Orphan
+    for _, svc := range services {
+        found, err := findOrphans(ctx, svc, pages, mapping)
+        if err != nil {
+            log.Printf("Service %s could not be listed: %v", svcName(svc), err)
+            continue
+        }
+        orphans = append(orphans, found...)
+    }
+
+    if len(orphans) == 0 {
+        fmt.Fprintln(options.Out, "No orphaned pages found.")
+        return nil
+    }
+    printOrphans(options.Out, orphans)
+
+    if !options.Prune {
+        return nil
+    }
+    return pruneOrphans(ctx, services, orphans, mapping, options)
+}
+
+// findOrphans compares the pages listed by a service with the ID mapping
+func findOrphans(ctx context.Context, svc ServiceInterface, pages []Page, mapping *IDMapping) ([]Orphan, error) {
+    service := svcName(svc)
+    summaries, err := svc.ListPages(ctx).All()
+    if err != nil {
+        return nil, err
+    }
+
+    canonical := make(map[string]bool, len(pages))
+    for _, page := range pages {
+        canonical[page.ID] = true
+    }
+    mapped := listedRemoteIDs(svc, mapping)
+    roots := map[string]bool{}
+    if rooted, ok := svc.(RootPages); ok {
+        if roots, err = rooted.RootPages(ctx); err != nil {
+            return nil, err
+        }
+    }
+
+    var orphans []Orphan
+    listed := make(map[string]bool, len(summaries))
+    for _, summary := range summaries {
+        listed[summary.ID] = true
+        if roots[summary.ID] {
+            continue
+        }
+        pageID, ok := mapped[summary.ID]
+        switch {
+        case !ok:
+            orphans = append(orphans, Orphan{Service: service, Kind: OrphanUnknown, Remote: summary})
+        case !canonical[pageID]:
+            orphans = append(orphans, Orphan{Service: service, Kind: OrphanRemoved, PageID: pageID, Remote: summary})
+        }
+    }
+    for remoteID, pageID := range mapped {
+        if !listed[remoteID] && canonical[pageID] {
+            orphans = append(orphans, Orphan{Service: service, Kind: OrphanMissing, PageID: pageID, Remote: listing.Summary{ID: remoteID}})
+        }
+    }
+    return orphans, nil
+}
+
+// printOrphans writes the orphan report
+func printOrphans(out io.Writer, orphans []Orphan) {
+    table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(table, "SERVICE\tKIND\tPAGE\tREMOTE ID\tREMOTE TITLE")
+    for _, orphan := range orphans {
+        fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", orphan.Service, orphan.Kind, orphan.PageID, orphan.Remote.ID, orphan.Remote.Title)
+    }
+    table.Flush()
+}
+
+// pruneOrphans archives or deletes the unknown and removed orphans, stopping once
+// MaxDeletions pages have been pruned. Each page is read first so that protected
+// pages, and pages that cannot be read, are left alone. Pruned removed pages are
+// dropped from the ID mapping
+func pruneOrphans(ctx context.Context, services []ServiceInterface, orphans []Orphan, mapping *IDMapping, options PruneOptions) error {
+    limit := options.MaxDeletions
+    if limit == 0 {
+        limit = 10
+    }
+    protected := make(map[string]bool, len(options.ProtectedLabels))
+    for _, label := range options.ProtectedLabels {
+        protected[strings.ToLower(strings.TrimSpace(label))] = true
+    }
+    byName := make(map[string]ServiceInterface, len(services))
+    for _, svc := range services {
+        byName[svcName(svc)] = svc
+    }
+
+    // Group the changes of each service like a sync run
+    runID := time.Now().UTC().Format("20060102T150405Z")
+    for _, svc := range services {
+        if participant, ok := svc.(RunParticipant); ok {
+            if err := participant.BeginRun(ctx, runID); err != nil {
+                return fmt.Errorf("failed to start prune run in %s: %v", svcName(svc), err)
+            }
+            defer func(svc ServiceInterface) {
+                if err := participant.EndRun(ctx); err != nil {
+                    log.Printf("Error ending prune run in %s: %v", svcName(svc), err)
+                }
+            }(svc)
+        }
+    }
+
+    pruned, remaining := 0, 0
+    for _, orphan := range orphans {
+        if orphan.Kind == OrphanMissing {
+            continue
+        }
+        if pruned >= limit {
+            remaining++
+            continue
+        }
+
+        svc := byName[orphan.Service]
+        if reader, ok := svc.(LabelReader); len(protected) > 0 && (!ok || !reader.ReadsLabels()) {
+            fmt.Fprintf(options.Out, "Skipped %s %s: the service cannot report labels to check for protected ones\n", orphan.Service, orphan.Remote.ID)
+            continue
+        }
+        archiver, canArchive := svc.(Archiver)
+        if !options.Delete && !canArchive {
+            fmt.Fprintf(options.Out, "Skipped %s %s: the service cannot archive pages\n", orphan.Service, orphan.Remote.ID)
+            continue
+        }
+        existing, err := svc.GetPage(ctx, orphan.Remote.ID)
+        if err != nil {
+            fmt.Fprintf(options.Out, "Skipped %s %s: %v\n", orphan.Service, orphan.Remote.ID, err)
+            continue
+        }
+        if label, ok := protectedLabel(existing.Labels, protected); ok {
+            fmt.Fprintf(options.Out, "Skipped %s %s: protected by label %q\n", orphan.Service, orphan.Remote.ID, label)
+            continue
+        }
+
+        action := "Archived"
+        if options.Delete {
+            action = "Deleted"
+            err = svc.DeletePage(ctx, orphan.Remote.ID)
+        } else {
+            err = archiver.ArchivePage(ctx, orphan.Remote.ID)
+        }
+        if err != nil {
+            fmt.Fprintf(options.Out, "Failed to prune %s %s: %v\n", orphan.Service, orphan.Remote.ID, err)
+            continue
+        }
+        if orphan.Kind == OrphanRemoved {
+            forgetRemote(svc, mapping, orphan.PageID, orphan.Remote.ID)
+        }
+        pruned++
+        fmt.Fprintf(options.Out, "%s %s %s %q\n", action, orphan.Service, orphan.Remote.ID, orphan.Remote.Title)
+    }
+
+    fmt.Fprintf(options.Out, "Pruned %d pages.\n", pruned)
+    if remaining > 0 {
+        fmt.Fprintf(options.Out, "Stopped at the limit of %d pages per run, %d orphans left.\n", limit, remaining)
+    }
+    return nil
+}
+
+// forgetRemote removes a pruned page from the mapping entry of its canonical page.
+// On services with composite IDs the entry keeps the IDs of the other targets
+func forgetRemote(svc ServiceInterface, mapping *IDMapping, pageID, remoteID string) {
+    service := svcName(svc)
+    entry, ok := mapping.Get(pageID, service)
+    if !ok {
+        return
+    }
+    var rest []string
+    for _, id := range remoteTargets(svc, entry.RemoteID) {
+        if id != remoteID {
+            rest = append(rest, id)
+        }
+    }
+    composite, ok := svc.(CompositeIDs)
+    if !ok || len(rest) == 0 {
+        mapping.Delete(pageID, service)
+        return
+    }
+    entry.RemoteID = composite.JoinIDs(rest...)
+    mapping.Set(pageID, service, entry)
+}
+
+// protectedLabel returns the first of labels that is protected
+func protectedLabel(labels []string, protected map[string]bool) (string, bool) {
+    for _, label := range labels {
+        if protected[strings.ToLower(strings.TrimSpace(label))] {
+            return label, true
+        }
+    }
+    return "", false
+}
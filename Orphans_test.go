@@ -0,0 +1,230 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakeSite is a service holding pages by remote ID that can neither archive pages nor
+// report their labels. Prunes are recorded as "delete <id>" and "archive <id>"
+type fakeSite struct {
+    pages  map[string]Page
+    writes []string
+}
+
+func newFakeSite() *fakeSite {
+    return &fakeSite{pages: map[string]Page{
+        "u1": {ID: "u1", Title: "Draft"},
+        "u2": {ID: "u2", Title: "Policy", Labels: []string{"Keep"}},
+        "u3": {ID: "u3", Title: "Old FAQ"},
+    }}
+}
+
+func (f *fakeSite) CreatePage(ctx context.Context, page Page) (string, error) {
+    return "", fmt.Errorf("not supported")
+}
+
+func (f *fakeSite) UpdatePage(ctx context.Context, page Page) error {
+    return fmt.Errorf("not supported")
+}
+
+func (f *fakeSite) DeletePage(ctx context.Context, id string) error {
+    f.writes = append(f.writes, "delete "+id)
+    return nil
+}
+
+func (f *fakeSite) GetPage(ctx context.Context, id string) (Page, error) {
+    page, ok := f.pages[id]
+    if !ok {
+        return Page{}, fmt.Errorf("page %s not found", id)
+    }
+    return page, nil
+}
+
+// ListPages is not used by pruning, which is handed the orphans
+func (f *fakeSite) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.Failed(fmt.Errorf("not supported"))
+}
+
+// archivingSite is a fakeSite that archives pages and, when labels is set, reports
+// their labels
+type archivingSite struct {
+    *fakeSite
+    labels bool
+}
+
+func (s archivingSite) ArchivePage(ctx context.Context, id string) error {
+    s.writes = append(s.writes, "archive "+id)
+    return nil
+}
+
+func (s archivingSite) ReadsLabels() bool {
+    return s.labels
+}
+
+// brandSite is an archivingSite whose page IDs combine per-brand IDs such as "a=1"
+// into "a=1,b=2"
+type brandSite struct {
+    archivingSite
+}
+
+func (s brandSite) SplitID(id string) map[string]string {
+    ids := map[string]string{}
+    for _, part := range strings.Split(id, ",") {
+        ids[strings.SplitN(part, "=", 2)[0]] = part
+    }
+    return ids
+}
+
+func (s brandSite) JoinIDs(ids ...string) string {
+    parts := append([]string(nil), ids...)
+    sort.Strings(parts)
+    return strings.Join(parts, ",")
+}
+
+func TestPruneOrphans(t *testing.T) {
+    tests := []struct {
+        name    string
+        archive bool // Whether the service can archive and report labels
+        labels  bool
+        options PruneOptions
+        writes  []string
+        output  string // Expected part of the output
+    }{
+        {
+            name:    "archives by default",
+            archive: true,
+            writes:  []string{"archive u1", "archive u2", "archive u3"},
+            output:  "Pruned 3 pages.",
+        },
+        {
+            name:    "deletes when asked",
+            archive: true,
+            options: PruneOptions{Delete: true},
+            writes:  []string{"delete u1", "delete u2", "delete u3"},
+            output:  `Deleted Unknown Service u1 "Draft"`,
+        },
+        {
+            name:    "stops at the limit",
+            archive: true,
+            options: PruneOptions{MaxDeletions: 2},
+            writes:  []string{"archive u1", "archive u2"},
+            output:  "Stopped at the limit of 2 pages per run, 2 orphans left.",
+        },
+        {
+            name:    "protected labels",
+            archive: true,
+            labels:  true,
+            options: PruneOptions{ProtectedLabels: []string{" keep "}},
+            writes:  []string{"archive u1", "archive u3"},
+            output:  `Skipped Unknown Service u2: protected by label "Keep"`,
+        },
+        {
+            name:    "service not reading labels",
+            archive: true,
+            options: PruneOptions{ProtectedLabels: []string{"keep"}},
+            output:  "Skipped Unknown Service u1: the service cannot report labels",
+        },
+        {
+            name:    "service without label support",
+            options: PruneOptions{Delete: true, ProtectedLabels: []string{"keep"}},
+            output:  "Skipped Unknown Service u1: the service cannot report labels",
+        },
+        {
+            name:    "unreadable pages skipped",
+            options: PruneOptions{Delete: true},
+            writes:  []string{"delete u1", "delete u2", "delete u3"},
+            output:  "Skipped Unknown Service u4: page u4 not found",
+        },
+        {
+            name:   "service that cannot archive",
+            output: "Skipped Unknown Service u1: the service cannot archive pages",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            site := newFakeSite()
+            var svc ServiceInterface = site
+            if tt.archive {
+                svc = archivingSite{fakeSite: site, labels: tt.labels}
+            }
+            service := svcName(svc)
+            // u4 cannot be read and missing pages are never pruned
+            orphans := []Orphan{
+                {Service: service, Kind: OrphanUnknown, Remote: listing.Summary{ID: "u1", Title: "Draft"}},
+                {Service: service, Kind: OrphanMissing, PageID: "p1", Remote: listing.Summary{ID: "m1"}},
+                {Service: service, Kind: OrphanUnknown, Remote: listing.Summary{ID: "u2", Title: "Policy"}},
+                {Service: service, Kind: OrphanUnknown, Remote: listing.Summary{ID: "u4"}},
+                {Service: service, Kind: OrphanUnknown, Remote: listing.Summary{ID: "u3", Title: "Old FAQ"}},
+            }
+
+            var out bytes.Buffer
+            options := tt.options
+            options.Out = &out
+            if err := pruneOrphans(context.Background(), []ServiceInterface{svc}, orphans, &IDMapping{Pages: map[string]map[string]MappingEntry{}}, options); err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(site.writes, tt.writes) {
+                t.Errorf("writes = %q, want %q", site.writes, tt.writes)
+            }
+            if !strings.Contains(out.String(), tt.output) {
+                t.Errorf("output = %q, want it to contain %q", out.String(), tt.output)
+            }
+        })
+    }
+}
+
+func TestPruneForgetsRemovedPages(t *testing.T) {
+    site := newFakeSite()
+    single := archivingSite{fakeSite: site}
+    brands := brandSite{archivingSite{fakeSite: site}}
+    site.pages["a=1"] = Page{ID: "a=1"}
+    site.pages["b=2"] = Page{ID: "b=2"}
+
+    tests := []struct {
+        name    string
+        svc     ServiceInterface
+        remote  string   // Mapped remote ID of the removed page
+        pruned  []string // Listed IDs pruned
+        want    string   // Remote ID left in the mapping, "" when the entry is dropped
+        present bool
+    }{
+        {name: "single ID", svc: single, remote: "u1", pruned: []string{"u1"}},
+        {name: "one brand of a composite ID", svc: brands, remote: "a=1,b=2", pruned: []string{"a=1"}, want: "b=2", present: true},
+        {name: "every brand of a composite ID", svc: brands, remote: "a=1,b=2", pruned: []string{"a=1", "b=2"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            service := svcName(tt.svc)
+            mapping := &IDMapping{Pages: map[string]map[string]MappingEntry{}}
+            mapping.Set("gone", service, MappingEntry{RemoteID: tt.remote})
+            mapping.Set("kept", service, MappingEntry{RemoteID: "u3"})
+
+            var orphans []Orphan
+            for _, id := range tt.pruned {
+                orphans = append(orphans, Orphan{Service: service, Kind: OrphanRemoved, PageID: "gone", Remote: listing.Summary{ID: id}})
+            }
+            options := PruneOptions{Out: &bytes.Buffer{}}
+            if err := pruneOrphans(context.Background(), []ServiceInterface{tt.svc}, orphans, mapping, options); err != nil {
+                t.Fatal(err)
+            }
+
+            entry, ok := mapping.Get("gone", service)
+            if ok != tt.present || entry.RemoteID != tt.want {
+                t.Errorf("mapping entry = %q, %v, want %q, %v", entry.RemoteID, ok, tt.want, tt.present)
+            }
+            if got := mapping.RemoteID("kept", service); got != "u3" {
+                t.Errorf("unrelated entry = %q, want it kept", got)
+            }
+        })
+    }
+}
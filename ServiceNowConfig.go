@@ -0,0 +1,43 @@
+package servicenow
+
+// Publish modes supported by ServiceNowConfig.PublishMode
+const (
+    PublishDirect = "direct" // Articles are written straight to the published state
+    PublishReview = "review" // Articles are written as drafts and submitted to the approval workflow
+)
+
+// ServiceNowConfig holds the knowledge base placement and lifecycle settings used
+// when syncing pages to ServiceNow Knowledge Management
+type ServiceNowConfig struct {
+    KnowledgeBase string                          `yaml:"knowledge_base"` // sys_id of the kb_knowledge_base
+    Category      string                          `yaml:"category"`       // sys_id of the kb_category, optional
+    PublishMode   string                          `yaml:"publish_mode"`   // PublishDirect (default) or PublishReview
+    HardDelete    bool                            `yaml:"hard_delete"`    // Delete records instead of retiring them
+    Versioning    string                          `yaml:"versioning"`     // "auto" (default) follows the instance setting, "on" or "off"
+    Pages         map[string]ServiceNowPageConfig `yaml:"pages"`          // Per-page overrides keyed by page ID
+}
+
+// ServiceNowPageConfig overrides the default knowledge base and category for a single page
+type ServiceNowPageConfig struct {
+    KnowledgeBase string `yaml:"knowledge_base"`
+    Category      string `yaml:"category"`
+}
+
+// placement returns the knowledge base and category sys_ids configured for a page
+func (c ServiceNowConfig) placement(pageID string) (string, string) {
+    knowledgeBase, category := c.KnowledgeBase, c.Category
+    if override, ok := c.Pages[pageID]; ok {
+        if override.KnowledgeBase != "" {
+            knowledgeBase = override.KnowledgeBase
+        }
+        if override.Category != "" {
+            category = override.Category
+        }
+    }
+    return knowledgeBase, category
+}
+
+// review reports whether articles go through the approval workflow
+func (c ServiceNowConfig) review() bool {
+    return c.PublishMode == PublishReview
+}
@@ -0,0 +1,71 @@
+package servicenow
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// listPageSize is the number of records requested per page of a listing
+const listPageSize = 100
+
+// ListPages lists the articles of the configured knowledge base that are not retired,
+// paging with sysparm_offset. On versioning instances only the latest version of each
+// article is listed. Each article's category is reported as its parent
+func (s *ServiceNowServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.listArticles)
+}
+
+// listArticles fetches the page of articles starting at the offset held in cursor
+func (s *ServiceNowServiceImpl) listArticles(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    offset := 0
+    if cursor != "" {
+        offset, _ = strconv.Atoi(cursor)
+    }
+
+    conditions := []string{"workflow_state!=retired"}
+    if s.config.KnowledgeBase != "" {
+        conditions = append(conditions, "kb_knowledge_base="+s.config.KnowledgeBase)
+    }
+    versioned, err := s.versioningEnabled(ctx)
+    if err != nil {
+        return nil, "", err
+    }
+    if versioned {
+        conditions = append(conditions, "latest=true")
+    }
+    // A fixed order keeps offsets stable between requests
+    query := strings.Join(conditions, "^") + "^ORDERBYsys_id"
+
+    endpoint := fmt.Sprintf("%s/api/now/table/kb_knowledge?sysparm_query=%s&sysparm_fields=sys_id,short_description,sys_updated_on,kb_category&sysparm_limit=%d&sysparm_offset=%d",
+        s.baseURL, url.QueryEscape(query), listPageSize, offset)
+
+    var result struct {
+        Result []map[string]interface{} `json:"result"`
+    }
+    if err := s.doRequest(ctx, "list articles", "GET", endpoint, nil, &result); err != nil {
+        return nil, "", err
+    }
+
+    summaries := make([]listing.Summary, 0, len(result.Result))
+    for _, record := range result.Result {
+        summary := listing.Summary{
+            ID:       rawValue(record, "sys_id"),
+            Title:    rawValue(record, "short_description"),
+            ParentID: rawValue(record, "kb_category"),
+        }
+        // The Table API reports times in UTC unless display values are requested
+        summary.Updated, _ = time.Parse("2006-01-02 15:04:05", rawValue(record, "sys_updated_on"))
+        summaries = append(summaries, summary)
+    }
+
+    if len(result.Result) < listPageSize {
+        return summaries, "", nil
+    }
+    return summaries, strconv.Itoa(offset + listPageSize), nil
+}
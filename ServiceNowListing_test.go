@@ -0,0 +1,108 @@
+package servicenow
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+)
+
+// fakeArticleTable serves pages of a kb_knowledge listing by sysparm_limit and
+// sysparm_offset, and the versioning property
+type fakeArticleTable struct {
+    count      int    // Number of articles listed
+    versioning string // Value of glide.knowman.versioning.enabled
+    queries    []string
+    offsets    []string
+}
+
+func (f *fakeArticleTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    switch r.URL.Path {
+    case "/api/now/table/sys_properties":
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": []map[string]string{{"value": f.versioning}}})
+
+    case "/api/now/table/kb_knowledge":
+        f.queries = append(f.queries, query.Get("sysparm_query"))
+        f.offsets = append(f.offsets, query.Get("sysparm_offset"))
+        offset, _ := strconv.Atoi(query.Get("sysparm_offset"))
+        limit, _ := strconv.Atoi(query.Get("sysparm_limit"))
+        rows := []map[string]interface{}{}
+        for i := offset; i < f.count && i < offset+limit; i++ {
+            rows = append(rows, map[string]interface{}{
+                "sys_id":            fmt.Sprintf("kb%03d", i),
+                "short_description": fmt.Sprintf("Article %d", i),
+                "sys_updated_on":    "2024-03-01 12:00:00",
+                "kb_category":       map[string]string{"value": "cat", "link": "https://example.service-now.com/cat"},
+            })
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": rows})
+
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func TestListPages(t *testing.T) {
+    tests := []struct {
+        name       string
+        count      int
+        versioning string
+        query      string
+        offsets    []string
+    }{
+        {
+            name:    "partial last page",
+            count:   250,
+            query:   "workflow_state!=retired^kb_knowledge_base=kb^ORDERBYsys_id",
+            offsets: []string{"0", "100", "200"},
+        },
+        {
+            name:    "full last page",
+            count:   200,
+            query:   "workflow_state!=retired^kb_knowledge_base=kb^ORDERBYsys_id",
+            offsets: []string{"0", "100", "200"},
+        },
+        {
+            name:       "latest versions only",
+            count:      3,
+            versioning: "true",
+            query:      "workflow_state!=retired^kb_knowledge_base=kb^latest=true^ORDERBYsys_id",
+            offsets:    []string{"0"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := &fakeArticleTable{count: tt.count, versioning: tt.versioning}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", ServiceNowConfig{KnowledgeBase: "kb"})
+            got, err := s.ListPages(context.Background()).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if len(got) != tt.count {
+                t.Fatalf("ListPages() listed %d articles, want %d", len(got), tt.count)
+            }
+            last := got[len(got)-1]
+            if want := fmt.Sprintf("kb%03d", tt.count-1); last.ID != want || last.ParentID != "cat" || !last.Updated.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+                t.Errorf("last article = %+v, want %s in category cat", last, want)
+            }
+            if !reflect.DeepEqual(fake.offsets, tt.offsets) {
+                t.Errorf("offsets = %q, want %q", fake.offsets, tt.offsets)
+            }
+            for _, query := range fake.queries {
+                if query != tt.query {
+                    t.Errorf("sysparm_query = %q, want %q", query, tt.query)
+                }
+            }
+        })
+    }
+}
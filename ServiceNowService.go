@@ -1,6 +1,10 @@
 package servicenow
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // ServiceNowService defines the methods for interacting with ServiceNow
 type ServiceNowService interface {
@@ -8,4 +12,5 @@ type ServiceNowService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
@@ -7,6 +7,9 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "sync"
+
+    "Support_Site_Sync/auth"
 )
 
 // ServiceNowServiceImpl is the implementation of the ServiceNowService interface
@@ -14,6 +17,11 @@ type ServiceNowServiceImpl struct {
     baseURL   string
     username   string
     password   string
+    config     ServiceNowConfig
+    tokens     auth.TokenSource // OAuth tokens; basic auth is used when nil
+
+    mu         sync.Mutex
+    versioning *bool // Cached instance versioning setting
 }
 
 // NewServiceNowService creates a new instance of ServiceNowService
@@ -21,21 +29,28 @@ func NewServiceNowService(baseURL, username, password string) *ServiceNowService
     return &ServiceNowServiceImpl{baseURL: baseURL, username: username, password: password}
 }
 
-// CreatePage creates a new page in ServiceNow
+// NewServiceNowServiceWithConfig creates a new instance of ServiceNowService that writes
+// articles to the configured knowledge base and category using the configured workflow
+func NewServiceNowServiceWithConfig(baseURL, username, password string, config ServiceNowConfig) *ServiceNowServiceImpl {
+    return &ServiceNowServiceImpl{baseURL: baseURL, username: username, password: password, config: config}
+}
+
+// NewServiceNowServiceWithOAuth creates a new instance of ServiceNowService that
+// authenticates with OAuth tokens, such as from an application registry client
+func NewServiceNowServiceWithOAuth(baseURL string, tokens auth.TokenSource, config ServiceNowConfig) *ServiceNowServiceImpl {
+    return &ServiceNowServiceImpl{baseURL: baseURL, tokens: tokens, config: config}
+}
+
+// CreatePage creates a new article in the configured ServiceNow knowledge base. In
+// review mode the article is created as a draft and submitted for approval
 func (s *ServiceNowServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
     url := fmt.Sprintf("%s/api/now/table/kb_knowledge", s.baseURL)
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "short_description": page.Title,
-        "text":              page.Content,
-        "workflow_state":    "published",
-    })
+    reqBody, _ := json.Marshal(s.articleFields(page, true))
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-    req.SetBasicAuth(s.username, s.password)
     req.Header.Set("Content-Type", "application/json")
 
-    client := &http.Client{}
-    resp, err := client.Do(req)
+    resp, err := s.send(req)
     if err != nil {
         return "", err
     }
@@ -54,24 +69,36 @@ func (s *ServiceNowServiceImpl) CreatePage(ctx context.Context, page Page) (stri
         return "", fmt.Errorf("failed to parse sys_id")
     }
 
+    if s.config.review() {
+        if err := s.setWorkflowState(ctx, sysID, "review"); err != nil {
+            return sysID, err
+        }
+    }
+
     return sysID, nil
 }
 
-// UpdatePage updates an existing page in ServiceNow
+// UpdatePage updates an existing article in ServiceNow. When the instance versions
+// articles the update becomes a new version; otherwise the record is edited in place.
+// In review mode the changed article is resubmitted to the approval workflow instead
+// of being published directly
 func (s *ServiceNowServiceImpl) UpdatePage(ctx context.Context, page Page) error {
+    versioned, err := s.versioningEnabled(ctx)
+    if err != nil {
+        return err
+    }
+    if versioned {
+        return s.updateVersioned(ctx, page)
+    }
+
     url := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s", s.baseURL, page.ID)
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "short_description": page.Title,
-        "text":              page.Content,
-        "workflow_state":    "published",
-    })
+    reqBody, _ := json.Marshal(s.articleFields(page, false))
 
-    req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
-    req.SetBasicAuth(s.username, s.password)
+    req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Content-Type", "application/json")
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := s.send(req)
     if err != nil {
         return err
     }
@@ -82,17 +109,33 @@ func (s *ServiceNowServiceImpl) UpdatePage(ctx context.Context, page Page) error
         return fmt.Errorf("failed to update page: %s - %s", resp.Status, body)
     }
 
+    if s.config.review() {
+        return s.setWorkflowState(ctx, page.ID, "review")
+    }
+
     return nil
 }
 
-// DeletePage deletes a page in ServiceNow
+// DeletePage retires the newest version of an article in ServiceNow so it stays
+// available for audit and can be restored. In review mode retirement goes through approval; the record is
+// only removed outright when HardDelete is configured
 func (s *ServiceNowServiceImpl) DeletePage(ctx context.Context, id string) error {
+    id, err := s.CurrentID(ctx, id)
+    if err != nil {
+        return err
+    }
+    if !s.config.HardDelete {
+        if s.config.review() {
+            return s.setWorkflowState(ctx, id, "pending_retirement")
+        }
+        return s.setWorkflowState(ctx, id, "retired")
+    }
+
     url := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s", s.baseURL, id)
 
     req, _ := http.NewRequest("DELETE", url, nil)
-    req.SetBasicAuth(s.username, s.password)
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := s.send(req)
     if err != nil {
         return err
     }
@@ -106,14 +149,27 @@ func (s *ServiceNowServiceImpl) DeletePage(ctx context.Context, id string) error
     return nil
 }
 
-// GetPage retrieves a page from ServiceNow
+// ArchivePage retires the newest version of an article, even when deletes are
+// configured to remove it
+func (s *ServiceNowServiceImpl) ArchivePage(ctx context.Context, id string) error {
+    id, err := s.CurrentID(ctx, id)
+    if err != nil {
+        return err
+    }
+    return s.setWorkflowState(ctx, id, "retired")
+}
+
+// GetPage retrieves the newest version of a page from ServiceNow
 func (s *ServiceNowServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
-    url := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s", s.baseURL, id)
+    id, err := s.CurrentID(ctx, id)
+    if err != nil {
+        return Page{}, err
+    }
+    url := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s?sysparm_display_value=all", s.baseURL, id)
 
     req, _ := http.NewRequest("GET", url, nil)
-    req.SetBasicAuth(s.username, s.password)
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := s.send(req)
     if err != nil {
         return Page{}, err
     }
@@ -128,13 +184,101 @@ func (s *ServiceNowServiceImpl) GetPage(ctx context.Context, id string) (Page, e
     json.NewDecoder(resp.Body).Decode(&result)
 
     kbArticle := result["result"].(map[string]interface{})
-    pageID := kbArticle["sys_id"].(string)
-    title := kbArticle["short_description"].(string)
-    content := kbArticle["text"].(string)
+    pageID := rawValue(kbArticle, "sys_id")
+    title := rawValue(kbArticle, "short_description")
+    content := rawValue(kbArticle, "text")
+    status := rawValue(kbArticle, "workflow_state")
+    version := displayValue(kbArticle, "version")
 
     return Page{
         ID:      pageID,
         Title:   title,
         Content: content,
+        Status:  status,
+        Version: version,
     }, nil
 }
+
+// articleFields builds the kb_knowledge fields written for a page. New articles are
+// published directly or start as drafts in review mode; updates leave the state alone
+// so the workflow decides it
+func (s *ServiceNowServiceImpl) articleFields(page Page, create bool) map[string]interface{} {
+    fields := map[string]interface{}{
+        "short_description": page.Title,
+        "text":              page.Content,
+    }
+
+    if page.ChangeSummary != "" && !create {
+        fields["work_notes"] = page.ChangeSummary
+    }
+
+    knowledgeBase, category := s.config.placement(page.CanonicalID())
+    if knowledgeBase != "" {
+        fields["kb_knowledge_base"] = knowledgeBase
+    }
+    if category != "" {
+        fields["kb_category"] = category
+    }
+
+    switch {
+    case s.config.review() && create:
+        fields["workflow_state"] = "draft"
+    case !s.config.review():
+        fields["workflow_state"] = "published"
+    }
+
+    return fields
+}
+
+// setWorkflowState moves an article to another workflow state, which starts the
+// approval or retirement workflows configured on the instance
+func (s *ServiceNowServiceImpl) setWorkflowState(ctx context.Context, id, state string) error {
+    url := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s", s.baseURL, id)
+    return s.doRequest(ctx, "set workflow state to "+state, "PATCH", url, map[string]interface{}{"workflow_state": state}, nil)
+}
+
+// doRequest sends an authenticated JSON request to the ServiceNow Table API and
+// decodes the response into out when it is not nil
+func (s *ServiceNowServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := s.send(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
+
+// send authenticates and sends a request, with an OAuth bearer token when a token
+// source is configured and basic auth otherwise
+func (s *ServiceNowServiceImpl) send(req *http.Request) (*http.Response, error) {
+    if s.tokens != nil {
+        return auth.Do(http.DefaultClient, s.tokens, req)
+    }
+    req.SetBasicAuth(s.username, s.password)
+    return http.DefaultClient.Do(req)
+}
@@ -0,0 +1,189 @@
+package servicenow
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeKnowledge is a kb_knowledge table. Writes are recorded as "METHOD sys_id
+// workflow_state", with the state left out when the write does not set one
+type fakeKnowledge struct {
+    versioning string                            // Value of glide.knowman.versioning.enabled
+    order      []string                          // sys_ids in creation order
+    articles   map[string]map[string]interface{} // Articles by sys_id
+    writes     []string
+    lookups    int // Reads of the versioning property
+}
+
+func newFakeKnowledge(versioning string) *fakeKnowledge {
+    return &fakeKnowledge{versioning: versioning, articles: map[string]map[string]interface{}{}}
+}
+
+// add stores an article and returns its sys_id
+func (f *fakeKnowledge) add(fields map[string]interface{}) string {
+    id := fmt.Sprintf("kb%d", len(f.order)+1)
+    fields["sys_id"] = id
+    f.order = append(f.order, id)
+    f.articles[id] = fields
+    return id
+}
+
+func (f *fakeKnowledge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/now/table/kb_knowledge"), "/")
+    var fields map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&fields)
+    if r.Method != "GET" {
+        state, _ := fields["workflow_state"].(string)
+        f.writes = append(f.writes, strings.Join(strings.Fields(r.Method+" "+id+" "+state), " "))
+    }
+
+    switch {
+    case r.URL.Path == "/api/now/table/sys_properties":
+        f.lookups++
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": []map[string]string{{"value": f.versioning}}})
+    case r.Method == "GET" && id == "":
+        // Newest version of an article number
+        query := r.URL.Query().Get("sysparm_query")
+        var rows []interface{}
+        for i := len(f.order) - 1; i >= 0; i-- {
+            if article := f.articles[f.order[i]]; strings.HasPrefix(query, fmt.Sprintf("number=%v^", article["number"])) {
+                rows = append(rows, article)
+                break
+            }
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": rows})
+    case r.Method == "GET":
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": f.articles[id]})
+    case r.Method == "POST":
+        created := f.add(fields)
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]string{"sys_id": created}})
+    case r.Method == "PATCH":
+        for k, v := range fields {
+            f.articles[id][k] = v
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"result": f.articles[id]})
+    case r.Method == "DELETE":
+        delete(f.articles, id)
+        w.WriteHeader(http.StatusNoContent)
+    }
+}
+
+func TestArticleFields(t *testing.T) {
+    config := ServiceNowConfig{
+        KnowledgeBase: "kb",
+        Category:      "cat",
+        Pages:         map[string]ServiceNowPageConfig{"special": {Category: "other"}},
+    }
+    review := config
+    review.PublishMode = PublishReview
+
+    tests := []struct {
+        name   string
+        config ServiceNowConfig
+        page   Page
+        create bool
+        want   map[string]interface{}
+    }{
+        {
+            name:   "direct create is published",
+            config: config,
+            page:   Page{ID: "p", Title: "T", Content: "C"},
+            create: true,
+            want:   map[string]interface{}{"short_description": "T", "text": "C", "kb_knowledge_base": "kb", "kb_category": "cat", "workflow_state": "published"},
+        },
+        {
+            name:   "review create starts as a draft",
+            config: review,
+            page:   Page{ID: "special", Title: "T", Content: "C"},
+            create: true,
+            want:   map[string]interface{}{"short_description": "T", "text": "C", "kb_knowledge_base": "kb", "kb_category": "other", "workflow_state": "draft"},
+        },
+        {
+            name:   "review update leaves the state to the workflow",
+            config: review,
+            page:   Page{ID: "p", Title: "T", Content: "C", ChangeSummary: "Fixed a typo"},
+            want:   map[string]interface{}{"short_description": "T", "text": "C", "kb_knowledge_base": "kb", "kb_category": "cat", "work_notes": "Fixed a typo"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s := NewServiceNowServiceWithConfig("", "", "", tt.config)
+            if got := s.articleFields(tt.page, tt.create); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("articleFields() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestCreatePageInReview(t *testing.T) {
+    fake := newFakeKnowledge("false")
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", ServiceNowConfig{PublishMode: PublishReview})
+    id, err := s.CreatePage(context.Background(), Page{ID: "p", Title: "T"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := []string{"POST draft", "PATCH " + id + " review"}; !reflect.DeepEqual(fake.writes, want) {
+        t.Errorf("writes = %q, want %q", fake.writes, want)
+    }
+}
+
+func TestDeletePageRetires(t *testing.T) {
+    tests := []struct {
+        name   string
+        config ServiceNowConfig
+        want   string
+    }{
+        {name: "retired directly", want: "PATCH kb1 retired"},
+        {name: "retirement reviewed", config: ServiceNowConfig{PublishMode: PublishReview}, want: "PATCH kb1 pending_retirement"},
+        {name: "hard delete", config: ServiceNowConfig{HardDelete: true}, want: "DELETE kb1"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeKnowledge("false")
+            fake.add(map[string]interface{}{"workflow_state": "published"})
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", tt.config)
+            if err := s.DeletePage(context.Background(), "kb1"); err != nil {
+                t.Fatal(err)
+            }
+            if want := []string{tt.want}; !reflect.DeepEqual(fake.writes, want) {
+                t.Errorf("writes = %q, want %q", fake.writes, want)
+            }
+        })
+    }
+}
+
+func TestGetPageReadsWorkflowState(t *testing.T) {
+    fake := newFakeKnowledge("false")
+    fake.add(map[string]interface{}{
+        "short_description": "T",
+        "text":              "C",
+        "workflow_state":    map[string]string{"value": "review", "display_value": "Review"},
+        "version":           map[string]string{"value": "abc", "display_value": "2.0"},
+    })
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", ServiceNowConfig{})
+    page, err := s.GetPage(context.Background(), "kb1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if page.Status != "review" || page.Version != "2.0" || page.Title != "T" {
+        t.Errorf("GetPage() = %+v, want status review and version 2.0", page)
+    }
+}
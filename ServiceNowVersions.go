@@ -0,0 +1,180 @@
+package servicenow
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+)
+
+// articleFieldList is the set of kb_knowledge fields read when resolving versions
+const articleFieldList = "sys_id,number,workflow_state,latest,kb_knowledge_base,kb_category"
+
+// versioningEnabled reports whether updates should create new article versions,
+// reading glide.knowman.versioning.enabled from the instance when set to auto
+func (s *ServiceNowServiceImpl) versioningEnabled(ctx context.Context) (bool, error) {
+    switch s.config.Versioning {
+    case "on":
+        return true, nil
+    case "off":
+        return false, nil
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.versioning != nil {
+        return *s.versioning, nil
+    }
+
+    var result map[string]interface{}
+    endpoint := fmt.Sprintf("%s/api/now/table/sys_properties?sysparm_query=%s&sysparm_fields=value",
+        s.baseURL, url.QueryEscape("name=glide.knowman.versioning.enabled"))
+    if err := s.doRequest(ctx, "read versioning property", "GET", endpoint, nil, &result); err != nil {
+        return false, err
+    }
+
+    enabled := false
+    if rows, _ := result["result"].([]interface{}); len(rows) > 0 {
+        row, _ := rows[0].(map[string]interface{})
+        enabled = row["value"] == "true"
+    }
+    s.versioning = &enabled
+
+    return enabled, nil
+}
+
+// latestVersion returns the newest version of the article that id belongs to. Older
+// versions keep their sys_id after a new version is published, so callers holding
+// an outdated ID are redirected to the current record by article number
+func (s *ServiceNowServiceImpl) latestVersion(ctx context.Context, id string) (map[string]interface{}, error) {
+    var result map[string]interface{}
+    endpoint := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s?sysparm_fields=%s", s.baseURL, id, articleFieldList)
+    if err := s.doRequest(ctx, "get article", "GET", endpoint, nil, &result); err != nil {
+        return nil, err
+    }
+    article, _ := result["result"].(map[string]interface{})
+    number, _ := article["number"].(string)
+    if number == "" {
+        return article, nil
+    }
+
+    var latest map[string]interface{}
+    query := url.QueryEscape("number=" + number + "^ORDERBYDESCsys_created_on")
+    endpoint = fmt.Sprintf("%s/api/now/table/kb_knowledge?sysparm_query=%s&sysparm_limit=1&sysparm_fields=%s", s.baseURL, query, articleFieldList)
+    if err := s.doRequest(ctx, "find latest version", "GET", endpoint, nil, &latest); err != nil {
+        return nil, err
+    }
+    if rows, _ := latest["result"].([]interface{}); len(rows) > 0 {
+        if row, ok := rows[0].(map[string]interface{}); ok {
+            return row, nil
+        }
+    }
+    return article, nil
+}
+
+// CurrentID returns the sys_id of the newest version of an article. Every published
+// update creates a new version with its own sys_id, so an ID recorded earlier may
+// name an outdated version; without versioning the ID is returned unchanged
+func (s *ServiceNowServiceImpl) CurrentID(ctx context.Context, id string) (string, error) {
+    versioned, err := s.versioningEnabled(ctx)
+    if err != nil || !versioned {
+        return id, err
+    }
+    latest, err := s.latestVersion(ctx, id)
+    if err != nil {
+        return "", err
+    }
+    if current, _ := latest["sys_id"].(string); current != "" {
+        return current, nil
+    }
+    return id, nil
+}
+
+// updateVersioned writes an update as a new article version. A published article is
+// checked out into a new draft version carrying the same article number; a draft or
+// in-review version is edited in place. The version is then published directly or
+// submitted for review, and the change summary is kept as a work note
+func (s *ServiceNowServiceImpl) updateVersioned(ctx context.Context, page Page) error {
+    current, err := s.latestVersion(ctx, page.ID)
+    if err != nil {
+        return err
+    }
+    currentID, _ := current["sys_id"].(string)
+    state, _ := current["workflow_state"].(string)
+
+    fields := s.articleFields(page, false)
+    delete(fields, "workflow_state")
+    if _, ok := fields["kb_knowledge_base"]; !ok {
+        fields["kb_knowledge_base"] = referenceValue(current["kb_knowledge_base"])
+    }
+    if _, ok := fields["kb_category"]; !ok {
+        fields["kb_category"] = referenceValue(current["kb_category"])
+    }
+
+    target := currentID
+    if state == "published" {
+        fields["number"] = current["number"]
+        fields["workflow_state"] = "draft"
+
+        var result map[string]interface{}
+        endpoint := fmt.Sprintf("%s/api/now/table/kb_knowledge", s.baseURL)
+        if err := s.doRequest(ctx, "check out new version", "POST", endpoint, fields, &result); err != nil {
+            return err
+        }
+        created, _ := result["result"].(map[string]interface{})
+        target, _ = created["sys_id"].(string)
+        if target == "" {
+            return fmt.Errorf("failed to parse sys_id of new version")
+        }
+    } else {
+        endpoint := fmt.Sprintf("%s/api/now/table/kb_knowledge/%s", s.baseURL, target)
+        if err := s.doRequest(ctx, "update draft version", "PATCH", endpoint, fields, nil); err != nil {
+            return err
+        }
+    }
+
+    if s.config.review() {
+        return s.setWorkflowState(ctx, target, "review")
+    }
+    return s.setWorkflowState(ctx, target, "published")
+}
+
+// referenceValue extracts the sys_id from a reference field, which the Table API
+// returns either as a plain string or as an object with a value
+func referenceValue(field interface{}) string {
+    switch v := field.(type) {
+    case string:
+        return v
+    case map[string]interface{}:
+        value, _ := v["value"].(string)
+        return value
+    }
+    return ""
+}
+
+// displayValue reads a field fetched with sysparm_display_value=all, returning the
+// display value when there is one and the raw value otherwise
+func displayValue(record map[string]interface{}, name string) string {
+    switch v := record[name].(type) {
+    case string:
+        return v
+    case map[string]interface{}:
+        if display, ok := v["display_value"].(string); ok && display != "" {
+            return display
+        }
+        value, _ := v["value"].(string)
+        return value
+    }
+    return ""
+}
+
+// rawValue reads the stored value of a field fetched with sysparm_display_value=all
+func rawValue(record map[string]interface{}, name string) string {
+    switch v := record[name].(type) {
+    case string:
+        return v
+    case map[string]interface{}:
+        value, _ := v["value"].(string)
+        return value
+    }
+    return ""
+}
@@ -0,0 +1,113 @@
+package servicenow
+
+import (
+    "context"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+)
+
+func TestUpdateVersioned(t *testing.T) {
+    tests := []struct {
+        name       string
+        state      string
+        config     ServiceNowConfig
+        wantWrites []string
+    }{
+        {
+            name:       "published article is checked out",
+            state:      "published",
+            wantWrites: []string{"POST draft", "PATCH kb2 published"},
+        },
+        {
+            name:       "new version is submitted for review",
+            state:      "published",
+            config:     ServiceNowConfig{PublishMode: PublishReview},
+            wantWrites: []string{"POST draft", "PATCH kb2 review"},
+        },
+        {
+            name:       "draft is edited in place",
+            state:      "draft",
+            wantWrites: []string{"PATCH kb1", "PATCH kb1 published"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeKnowledge("true")
+            fake.add(map[string]interface{}{
+                "number":            "KB0010001",
+                "workflow_state":    tt.state,
+                "kb_knowledge_base": map[string]string{"value": "kb", "link": "https://example.com"},
+            })
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", tt.config)
+            page := Page{ID: "kb1", Title: "New title", ChangeSummary: "Clarified step 2"}
+            if err := s.UpdatePage(context.Background(), page); err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(fake.writes, tt.wantWrites) {
+                t.Errorf("writes = %q, want %q", fake.writes, tt.wantWrites)
+            }
+
+            latest := fake.articles[fake.order[len(fake.order)-1]]
+            if latest["number"] != "KB0010001" || latest["work_notes"] != "Clarified step 2" || latest["kb_knowledge_base"] != "kb" {
+                t.Errorf("latest version = %v, want the same number, a work note and the knowledge base", latest)
+            }
+        })
+    }
+}
+
+func TestCurrentIDFollowsVersions(t *testing.T) {
+    fake := newFakeKnowledge("true")
+    fake.add(map[string]interface{}{"number": "KB1", "workflow_state": "outdated"})
+    fake.add(map[string]interface{}{"number": "KB2", "workflow_state": "published"})
+    fake.add(map[string]interface{}{"number": "KB1", "workflow_state": "published"})
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", ServiceNowConfig{})
+    for _, tt := range []struct{ id, want string }{{"kb1", "kb3"}, {"kb2", "kb2"}} {
+        got, err := s.CurrentID(context.Background(), tt.id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got != tt.want {
+            t.Errorf("CurrentID(%q) = %q, want %q", tt.id, got, tt.want)
+        }
+    }
+    if fake.lookups != 1 {
+        t.Errorf("versioning property read %d times, want once", fake.lookups)
+    }
+}
+
+func TestVersioningSetting(t *testing.T) {
+    tests := []struct {
+        setting  string
+        property string
+        want     bool
+    }{
+        {setting: "on", property: "false", want: true},
+        {setting: "off", property: "true", want: false},
+        {setting: "", property: "true", want: true},
+        {setting: "auto", property: "false", want: false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.setting+"/"+tt.property, func(t *testing.T) {
+            server := httptest.NewServer(newFakeKnowledge(tt.property))
+            defer server.Close()
+
+            s := NewServiceNowServiceWithConfig(server.URL, "user", "pass", ServiceNowConfig{Versioning: tt.setting})
+            got, err := s.versioningEnabled(context.Background())
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got != tt.want {
+                t.Errorf("versioningEnabled() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
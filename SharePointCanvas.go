@@ -0,0 +1,115 @@
+package sharepoint
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    "Support_Site_Sync/markdown"
+)
+
+// renderSections converts Markdown page content into HTML, split into one section
+// per second-level heading so each becomes its own text web part. Content that is
+// already HTML is kept as a single section
+func renderSections(content string) []string {
+    if strings.HasPrefix(strings.TrimSpace(content), "<") {
+        return []string{content}
+    }
+
+    var sections []string
+    var section []markdown.Block
+    for _, block := range markdown.Parse(content) {
+        if block.Kind == markdown.HeadingBlock && block.Level == 2 && len(section) > 0 {
+            sections = append(sections, markdown.HTML(section))
+            section = nil
+        }
+        section = append(section, block)
+    }
+    if len(section) > 0 {
+        sections = append(sections, markdown.HTML(section))
+    }
+
+    return sections
+}
+
+// canvasContent builds the CanvasContent1 JSON of a modern page with one full-width
+// section per rendered section, each holding a single text web part
+func canvasContent(content string) string {
+    var controls []map[string]interface{}
+    for i, section := range renderSections(content) {
+        controls = append(controls, map[string]interface{}{
+            "controlType": 4, // Text web part
+            "id":          fmt.Sprintf("00000000-0000-4000-8000-%012d", i+1),
+            "position": map[string]interface{}{
+                "zoneIndex":     i + 1,
+                "sectionIndex":  1,
+                "controlIndex":  1,
+                "sectionFactor": 12,
+                "layoutIndex":   1,
+            },
+            "emphasis":               map[string]interface{}{},
+            "addedFromPersistedData": true,
+            "innerHTML":              section,
+        })
+    }
+    data, _ := json.Marshal(controls)
+    return string(data)
+}
+
+// canvasHTML extracts the HTML of the text web parts from CanvasContent1 JSON
+func canvasHTML(canvas string) string {
+    var controls []map[string]interface{}
+    if err := json.Unmarshal([]byte(canvas), &controls); err != nil {
+        return ""
+    }
+    var parts []string
+    for _, control := range controls {
+        if inner, ok := control["innerHTML"].(string); ok && inner != "" {
+            parts = append(parts, inner)
+        }
+    }
+    return strings.Join(parts, "\n")
+}
+
+// graphCanvasLayout builds the Graph canvasLayout of a modern page with one
+// one-column section per rendered section
+func graphCanvasLayout(content string) map[string]interface{} {
+    var sections []map[string]interface{}
+    for i, section := range renderSections(content) {
+        sections = append(sections, map[string]interface{}{
+            "layout":   "oneColumn",
+            "id":       fmt.Sprintf("%d", i+1),
+            "emphasis": "none",
+            "columns": []map[string]interface{}{{
+                "id":    "1",
+                "width": 12,
+                "webparts": []map[string]interface{}{{
+                    "@odata.type": "#microsoft.graph.textWebPart",
+                    "innerHtml":   section,
+                }},
+            }},
+        })
+    }
+    return map[string]interface{}{"horizontalSections": sections}
+}
+
+// graphCanvasHTML extracts the HTML of the text web parts from a Graph canvasLayout
+func graphCanvasHTML(layout map[string]interface{}) string {
+    var parts []string
+    sections, _ := layout["horizontalSections"].([]interface{})
+    for _, s := range sections {
+        section, _ := s.(map[string]interface{})
+        columns, _ := section["columns"].([]interface{})
+        for _, c := range columns {
+            column, _ := c.(map[string]interface{})
+            webparts, _ := column["webparts"].([]interface{})
+            for _, w := range webparts {
+                webpart, _ := w.(map[string]interface{})
+                if inner, ok := webpart["innerHtml"].(string); ok && inner != "" {
+                    parts = append(parts, inner)
+                }
+            }
+        }
+    }
+    return strings.Join(parts, "\n")
+}
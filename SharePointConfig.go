@@ -0,0 +1,49 @@
+package sharepoint
+
+import "Support_Site_Sync/slug"
+
+// APIs supported by SharePointConfig.API
+const (
+    APISitePages = "sitepages" // SharePoint REST /_api/sitepages (default)
+    APIGraph     = "graph"     // Microsoft Graph sites/{id}/pages
+    APIListItems = "listitems" // Classic list items in 'Site Pages', kept for legacy sites
+)
+
+// defaultGraphURL is the Microsoft Graph endpoint used when none is configured
+const defaultGraphURL = "https://graph.microsoft.com/v1.0"
+
+// SharePointConfig selects how pages are written to SharePoint
+type SharePointConfig struct {
+    API         string `yaml:"api"`          // APISitePages, APIGraph or APIListItems
+    SiteID      string `yaml:"site_id"`      // Graph site ID, e.g. "contoso.sharepoint.com,{site-guid},{web-guid}"
+    GraphURL    string `yaml:"graph_url"`    // Graph endpoint, defaults to https://graph.microsoft.com/v1.0
+    LeaveDrafts bool   `yaml:"leave_drafts"` // Save changes without publishing them
+
+    // FileNames generates the .aspx file names of pages created through Graph. Titles
+    // keep letters of any script unless configured otherwise, and section paths are
+    // not used since pages live in the Site Pages library root
+    FileNames slug.Config `yaml:"file_names"`
+}
+
+// graphURL returns the configured Graph endpoint
+func (c SharePointConfig) graphURL() string {
+    if c.GraphURL == "" {
+        return defaultGraphURL
+    }
+    return c.GraphURL
+}
+
+// fileNames returns the file name settings with the SharePoint defaults filled in
+func (c SharePointConfig) fileNames() slug.Config {
+    names := c.FileNames
+    if names.Pattern == "" {
+        names.Pattern = "{{.Title}}"
+    }
+    if names.Unicode == "" {
+        names.Unicode = slug.UnicodeKeep
+    }
+    if names.Extension == "" {
+        names.Extension = ".aspx"
+    }
+    return names
+}
@@ -0,0 +1,97 @@
+package sharepoint
+
+import (
+    "context"
+    "fmt"
+)
+
+// graphPagesURL returns the Graph pages collection of the configured site
+func (s *SharePointService) graphPagesURL() string {
+    return fmt.Sprintf("%s/sites/%s/pages", s.config.graphURL(), s.config.SiteID)
+}
+
+// createGraphPage creates a modern page through Microsoft Graph and publishes it
+// unless drafts are configured
+func (s *SharePointService) createGraphPage(ctx context.Context, page Page) (string, error) {
+    if s.config.SiteID == "" {
+        return "", fmt.Errorf("no SharePoint site ID configured for Microsoft Graph")
+    }
+
+    name, err := s.pageFileName(ctx, page)
+    if err != nil {
+        return "", err
+    }
+
+    body := map[string]interface{}{
+        "@odata.type":  "#microsoft.graph.sitePage",
+        "name":         name,
+        "title":        page.Title,
+        "pageLayout":   "article",
+        "canvasLayout": graphCanvasLayout(page.Content),
+    }
+
+    var created map[string]interface{}
+    if err := s.doRequest(ctx, "create page", "POST", s.graphPagesURL(), body, &created); err != nil {
+        return "", err
+    }
+
+    id, ok := created["id"].(string)
+    if !ok {
+        return "", fmt.Errorf("failed to parse page ID")
+    }
+
+    if !s.config.LeaveDrafts {
+        if err := s.publishGraphPage(ctx, id); err != nil {
+            return id, err
+        }
+    }
+    return id, nil
+}
+
+// updateGraphPage replaces the title and canvas of a modern page through Microsoft
+// Graph and publishes it unless drafts are configured
+func (s *SharePointService) updateGraphPage(ctx context.Context, page Page) error {
+    url := fmt.Sprintf("%s/%s/microsoft.graph.sitePage", s.graphPagesURL(), page.ID)
+    body := map[string]interface{}{
+        "@odata.type":  "#microsoft.graph.sitePage",
+        "title":        page.Title,
+        "canvasLayout": graphCanvasLayout(page.Content),
+    }
+    if err := s.doRequest(ctx, "update page", "PATCH", url, body, nil); err != nil {
+        return err
+    }
+
+    if s.config.LeaveDrafts {
+        return nil
+    }
+    return s.publishGraphPage(ctx, page.ID)
+}
+
+// publishGraphPage publishes the latest version of a modern page
+func (s *SharePointService) publishGraphPage(ctx context.Context, id string) error {
+    url := fmt.Sprintf("%s/%s/microsoft.graph.sitePage/publish", s.graphPagesURL(), id)
+    return s.doRequest(ctx, "publish page", "POST", url, nil, nil)
+}
+
+// deleteGraphPage deletes a modern page through Microsoft Graph
+func (s *SharePointService) deleteGraphPage(ctx context.Context, id string) error {
+    return s.doRequest(ctx, "delete page", "DELETE", fmt.Sprintf("%s/%s", s.graphPagesURL(), id), nil, nil)
+}
+
+// getGraphPage reads a modern page through Microsoft Graph, returning the HTML of its text web parts
+func (s *SharePointService) getGraphPage(ctx context.Context, id string) (Page, error) {
+    var result map[string]interface{}
+    url := fmt.Sprintf("%s/%s/microsoft.graph.sitePage?$expand=canvasLayout", s.graphPagesURL(), id)
+    if err := s.doRequest(ctx, "get page", "GET", url, nil, &result); err != nil {
+        return Page{}, err
+    }
+
+    title, _ := result["title"].(string)
+    layout, _ := result["canvasLayout"].(map[string]interface{})
+
+    return Page{
+        ID:      id,
+        Title:   title,
+        Content: graphCanvasHTML(layout),
+    }, nil
+}
@@ -0,0 +1,64 @@
+package sharepoint
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// ListPages lists the pages of the site, through Microsoft Graph or the 'Site Pages'
+// library depending on the configured API. Both page with OData next links
+func (s *SharePointService) ListPages(ctx context.Context) *listing.Iterator {
+    if s.config.API == APIGraph && s.config.SiteID == "" {
+        return listing.Failed(fmt.Errorf("no SharePoint site ID configured for Microsoft Graph"))
+    }
+    return listing.New(ctx, s.listPages)
+}
+
+// listPages fetches one page of the site's pages
+func (s *SharePointService) listPages(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    endpoint := cursor
+    if endpoint == "" {
+        if s.config.API == APIGraph {
+            endpoint = s.graphPagesURL() + "/microsoft.graph.sitePage?$select=id,title,lastModifiedDateTime&$top=100"
+        } else {
+            // Site Pages API page IDs are the item IDs of the library
+            endpoint = fmt.Sprintf("%s/_api/web/lists/getbytitle('Site Pages')/items?$select=Id,Title,Modified&$top=100", s.baseURL)
+        }
+    }
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "list pages", "GET", endpoint, nil, &result); err != nil {
+        return nil, "", err
+    }
+
+    var summaries []listing.Summary
+    items, _ := result["value"].([]interface{})
+    for _, item := range items {
+        page, _ := item.(map[string]interface{})
+        var summary listing.Summary
+        var modified string
+        if s.config.API == APIGraph {
+            summary.ID, _ = page["id"].(string)
+            summary.Title, _ = page["title"].(string)
+            modified, _ = page["lastModifiedDateTime"].(string)
+        } else {
+            id, _ := page["Id"].(float64)
+            summary.ID = strconv.FormatFloat(id, 'f', 0, 64)
+            summary.Title, _ = page["Title"].(string)
+            modified, _ = page["Modified"].(string)
+        }
+        summary.Updated, _ = time.Parse(time.RFC3339, modified)
+        summaries = append(summaries, summary)
+    }
+
+    // Graph and SharePoint name the next link differently
+    next, _ := result["@odata.nextLink"].(string)
+    if next == "" {
+        next, _ = result["odata.nextLink"].(string)
+    }
+    return summaries, next, nil
+}
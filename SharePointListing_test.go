@@ -0,0 +1,103 @@
+package sharepoint
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakePageLibrary serves the pages of a site two at a time, through the Graph pages
+// collection and the 'Site Pages' library, each with its own OData next link
+type fakePageLibrary struct {
+    pages    []listing.Summary
+    requests []string // "graph" or "items" with the $skiptoken of each request
+}
+
+func (f *fakePageLibrary) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    graph := r.URL.Path == "/sites/site/pages/microsoft.graph.sitePage"
+    if !graph && r.URL.Path != "/_api/web/lists/getbytitle('Site Pages')/items" {
+        http.NotFound(w, r)
+        return
+    }
+    skip := r.URL.Query().Get("$skiptoken")
+    start, _ := strconv.Atoi(skip)
+
+    var values []map[string]interface{}
+    for i := start; i < len(f.pages) && i < start+2; i++ {
+        page, modified := f.pages[i], f.pages[i].Updated.Format(time.RFC3339)
+        if graph {
+            values = append(values, map[string]interface{}{"id": page.ID, "title": page.Title, "lastModifiedDateTime": modified})
+        } else {
+            id, _ := strconv.Atoi(page.ID)
+            values = append(values, map[string]interface{}{"Id": id, "Title": page.Title, "Modified": modified})
+        }
+    }
+    result := map[string]interface{}{"value": values}
+    if start+2 < len(f.pages) {
+        next := fmt.Sprintf("http://%s%s?$skiptoken=%d", r.Host, r.URL.EscapedPath(), start+2)
+        if graph {
+            result["@odata.nextLink"] = next
+        } else {
+            result["odata.nextLink"] = next
+        }
+    }
+    if graph {
+        f.requests = append(f.requests, strings.TrimSpace("graph "+skip))
+    } else {
+        f.requests = append(f.requests, strings.TrimSpace("items "+skip))
+    }
+    json.NewEncoder(w).Encode(result)
+}
+
+func TestListPages(t *testing.T) {
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    pages := []listing.Summary{
+        {ID: "1", Title: "Home", Updated: updated},
+        {ID: "2", Title: "Guide", Updated: updated},
+        {ID: "3", Title: "FAQ", Updated: updated},
+    }
+
+    tests := []struct {
+        api      string
+        requests []string
+    }{
+        {APIGraph, []string{"graph", "graph 2"}},
+        {APISitePages, []string{"items", "items 2"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.api, func(t *testing.T) {
+            fake := &fakePageLibrary{pages: pages}
+            server := httptest.NewServer(fake)
+            defer server.Close()
+
+            config := SharePointConfig{API: tt.api, SiteID: "site", GraphURL: server.URL}
+            got, err := NewSharePointServiceWithConfig(server.URL, "token", config).ListPages(context.Background()).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(got, pages) {
+                t.Errorf("ListPages() = %v, want %v", got, pages)
+            }
+            if !reflect.DeepEqual(fake.requests, tt.requests) {
+                t.Errorf("requests = %q, want %q", fake.requests, tt.requests)
+            }
+        })
+    }
+}
+
+func TestListPagesGraphWithoutSite(t *testing.T) {
+    s := NewSharePointServiceWithConfig("https://example.sharepoint.com", "token", SharePointConfig{API: APIGraph})
+    if _, err := s.ListPages(context.Background()).All(); err == nil {
+        t.Error("ListPages() without a site ID succeeded, want an error")
+    }
+}
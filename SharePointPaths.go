@@ -0,0 +1,66 @@
+package sharepoint
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "strings"
+
+    "Support_Site_Sync/slug"
+)
+
+// fileNames returns the file name generator, creating it on first use
+func (s *SharePointService) fileNames() (*slug.Generator, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.names == nil {
+        names, err := slug.New(s.config.fileNames())
+        if err != nil {
+            return nil, err
+        }
+        s.names = names
+    }
+    return s.names, nil
+}
+
+// ReservePath records the file name a page was given in an earlier run, as kept in
+// the ID mapping, so other pages do not take it
+func (s *SharePointService) ReservePath(pageID, path string) {
+    if names, err := s.fileNames(); err == nil {
+        names.Reserve(pageID, path)
+    }
+}
+
+// PagePath returns the file name of a page created through Graph by its canonical ID
+func (s *SharePointService) PagePath(pageID string) (string, bool) {
+    names, err := s.fileNames()
+    if err != nil {
+        return "", false
+    }
+    return names.Assigned(pageID)
+}
+
+// pageFileName returns the .aspx file name of a new page, generated from its title
+// and numbered when another page of the site already has it
+func (s *SharePointService) pageFileName(ctx context.Context, page Page) (string, error) {
+    names, err := s.fileNames()
+    if err != nil {
+        return "", err
+    }
+    return names.Path(page.CanonicalID(), page.Title, nil, func(name string) (bool, error) {
+        return s.graphPageExists(ctx, name)
+    })
+}
+
+// graphPageExists reports whether the site already has a page with the file name
+func (s *SharePointService) graphPageExists(ctx context.Context, name string) (bool, error) {
+    filter := url.QueryEscape(fmt.Sprintf("name eq '%s'", strings.ReplaceAll(name, "'", "''")))
+    endpoint := fmt.Sprintf("%s?$filter=%s&$select=id", s.graphPagesURL(), filter)
+
+    var result map[string]interface{}
+    if err := s.doRequest(ctx, "find page", "GET", endpoint, nil, &result); err != nil {
+        return false, err
+    }
+    items, _ := result["value"].([]interface{})
+    return len(items) > 0, nil
+}
@@ -0,0 +1,75 @@
+package sharepoint
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// fakeGraphPages is the pages collection of a Graph site, holding page file names by ID
+type fakeGraphPages struct {
+    names map[string]string
+}
+
+func (f *fakeGraphPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case "GET":
+        // Only the "name eq '...'" filter is supported
+        filter := r.URL.Query().Get("$filter")
+        var found []map[string]string
+        for id, name := range f.names {
+            if filter == fmt.Sprintf("name eq '%s'", strings.ReplaceAll(name, "'", "''")) {
+                found = append(found, map[string]string{"id": id})
+            }
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"value": found})
+    case "POST":
+        var body map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&body)
+        id := fmt.Sprintf("page-%d", len(f.names)+1)
+        f.names[id], _ = body["name"].(string)
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]string{"id": id})
+    }
+}
+
+func TestGraphPageFileNames(t *testing.T) {
+    fake := &fakeGraphPages{names: map[string]string{"existing": "setup.aspx"}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    config := SharePointConfig{API: APIGraph, SiteID: "site", GraphURL: server.URL, LeaveDrafts: true}
+    service := NewSharePointServiceWithConfig(server.URL, "token", config)
+    service.ReservePath("reserved", "release-notes.aspx")
+
+    tests := []struct {
+        id    string
+        title string
+        want  string
+    }{
+        {"faq-1", "FAQ?", "faq.aspx"},
+        {"faq-2", "FAQ!", "faq-2.aspx"},
+        {"setup", "Setup", "setup-2.aspx"},
+        {"notes", "Release notes", "release-notes-2.aspx"},
+        {"ja", "よくある質問", "よくある質問.aspx"},
+        {"ru", "Вопросы", "вопросы.aspx"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.id, func(t *testing.T) {
+            id, err := service.CreatePage(context.Background(), Page{ID: tt.id, Title: tt.title})
+            if err != nil {
+                t.Fatalf("CreatePage() error = %v", err)
+            }
+            if got := fake.names[id]; got != tt.want {
+                t.Errorf("file name = %q, want %q", got, tt.want)
+            }
+            if got, ok := service.PagePath(tt.id); !ok || got != tt.want {
+                t.Errorf("PagePath() = %q, %v, want %q", got, ok, tt.want)
+            }
+        })
+    }
+}
@@ -1,6 +1,10 @@
 package sharepoint
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // SharePointService defines the methods for interacting with SharePoint
 type SharePointService interface {
@@ -8,4 +12,5 @@ type SharePointService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
@@ -0,0 +1,68 @@
+package sharepoint
+
+import (
+    "context"
+    "fmt"
+)
+
+// createSitePage creates a modern page through /_api/sitepages, saves its canvas and
+// publishes it unless drafts are configured
+func (s *SharePointService) createSitePage(ctx context.Context, page Page) (string, error) {
+    var created map[string]interface{}
+    url := fmt.Sprintf("%s/_api/sitepages/pages", s.baseURL)
+    if err := s.doRequest(ctx, "create page", "POST", url, map[string]interface{}{"PageLayoutType": "Article"}, &created); err != nil {
+        return "", err
+    }
+
+    id, ok := created["Id"].(float64)
+    if !ok {
+        return "", fmt.Errorf("failed to parse page ID")
+    }
+    pageID := fmt.Sprintf("%.0f", id)
+
+    if err := s.saveSitePage(ctx, pageID, page); err != nil {
+        return pageID, err
+    }
+    return pageID, nil
+}
+
+// saveSitePage checks a modern page out, saves its title and canvas as a draft and
+// publishes it unless drafts are configured
+func (s *SharePointService) saveSitePage(ctx context.Context, id string, page Page) error {
+    base := fmt.Sprintf("%s/_api/sitepages/pages(%s)", s.baseURL, id)
+
+    if err := s.doRequest(ctx, "check out page", "POST", base+"/checkoutpage", nil, nil); err != nil {
+        return err
+    }
+
+    draft := map[string]interface{}{
+        "Title":          page.Title,
+        "CanvasContent1": canvasContent(page.Content),
+    }
+    if err := s.doRequest(ctx, "save page", "POST", base+"/savepageasdraft", draft, nil); err != nil {
+        return err
+    }
+
+    if s.config.LeaveDrafts {
+        return nil
+    }
+    return s.doRequest(ctx, "publish page", "POST", base+"/publish", nil, nil)
+}
+
+// getSitePage reads a modern page through /_api/sitepages, returning the HTML of its text web parts
+func (s *SharePointService) getSitePage(ctx context.Context, id string) (Page, error) {
+    var result map[string]interface{}
+    url := fmt.Sprintf("%s/_api/sitepages/pages(%s)", s.baseURL, id)
+    if err := s.doRequest(ctx, "get page", "GET", url, nil, &result); err != nil {
+        return Page{}, err
+    }
+
+    title, _ := result["Title"].(string)
+    canvas, _ := result["CanvasContent1"].(string)
+
+    return Page{
+        ID:      id,
+        Title:   title,
+        Content: canvasHTML(canvas),
+    }, nil
+}
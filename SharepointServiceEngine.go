@@ -7,19 +7,49 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "sync"
+
+    "Support_Site_Sync/auth"
+    "Support_Site_Sync/slug"
 )
 
 type SharePointService struct {
     baseURL   string
-    accessToken string
+    tokens    auth.TokenSource
+    config    SharePointConfig
+
+    mu    sync.Mutex
+    names *slug.Generator // Page file names by canonical page ID
 }
 
 func NewSharePointService(baseURL, accessToken string) *SharePointService {
-    return &SharePointService{baseURL: baseURL, accessToken: accessToken}
+    return &SharePointService{baseURL: baseURL, tokens: auth.StaticToken(accessToken)}
+}
+
+// NewSharePointServiceWithConfig creates a SharePoint service that writes modern
+// pages through the API selected in config
+func NewSharePointServiceWithConfig(baseURL, accessToken string, config SharePointConfig) *SharePointService {
+    return &SharePointService{baseURL: baseURL, tokens: auth.StaticToken(accessToken), config: config}
 }
 
-// CreatePage creates a new page in SharePoint
+// NewSharePointServiceWithAuth creates a SharePoint service that gets its access
+// tokens from tokens, such as an Azure AD client credentials source. The token
+// audience must match the API: the SharePoint tenant for the Site Pages and list
+// item APIs, Microsoft Graph for APIGraph
+func NewSharePointServiceWithAuth(baseURL string, tokens auth.TokenSource, config SharePointConfig) *SharePointService {
+    return &SharePointService{baseURL: baseURL, tokens: tokens, config: config}
+}
+
+// CreatePage creates a new modern page in SharePoint. The classic list item
+// implementation below is only used with APIListItems
 func (s *SharePointService) CreatePage(ctx context.Context, page Page) (string, error) {
+    switch s.config.API {
+    case APIGraph:
+        return s.createGraphPage(ctx, page)
+    case APISitePages, "":
+        return s.createSitePage(ctx, page)
+    }
+
     url := fmt.Sprintf("%s/_api/web/lists/getbytitle('Site Pages')/items", s.baseURL)
     reqBody, _ := json.Marshal(map[string]interface{}{
         "__metadata": map[string]string{"type": "SP.Data.SitePagesItem"},
@@ -28,11 +58,9 @@ func (s *SharePointService) CreatePage(ctx context.Context, page Page) (string,
     })
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-    req.Header.Set("Authorization", "Bearer " + s.accessToken)
     req.Header.Set("Content-Type", "application/json;odata=verbose")
 
-    client := &http.Client{}
-    resp, err := client.Do(req)
+    resp, err := auth.Do(http.DefaultClient, s.tokens, req)
     if err != nil {
         return "", err
     }
@@ -56,6 +84,13 @@ func (s *SharePointService) CreatePage(ctx context.Context, page Page) (string,
 
 // UpdatePage updates an existing page in SharePoint
 func (s *SharePointService) UpdatePage(ctx context.Context, page Page) error {
+    switch s.config.API {
+    case APIGraph:
+        return s.updateGraphPage(ctx, page)
+    case APISitePages, "":
+        return s.saveSitePage(ctx, page.ID, page)
+    }
+
     url := fmt.Sprintf("%s/_api/web/lists/getbytitle('Site Pages')/items(%s)", s.baseURL, page.ID)
 
     reqBody, _ := json.Marshal(map[string]interface{}{
@@ -65,12 +100,11 @@ func (s *SharePointService) UpdatePage(ctx context.Context, page Page) error {
     })
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-    req.Header.Set("Authorization", "Bearer " + s.accessToken)
     req.Header.Set("Content-Type", "application/json;odata=verbose")
     req.Header.Set("X-HTTP-Method", "MERGE")
     req.Header.Set("If-Match", "*")
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := auth.Do(http.DefaultClient, s.tokens, req)
     if err != nil {
         return err
     }
@@ -86,13 +120,18 @@ func (s *SharePointService) UpdatePage(ctx context.Context, page Page) error {
 
 // DeletePage deletes a page in SharePoint
 func (s *SharePointService) DeletePage(ctx context.Context, id string) error {
+    // Modern pages created through /_api/sitepages are items of the Site Pages
+    // library, so they are deleted the same way as list items
+    if s.config.API == APIGraph {
+        return s.deleteGraphPage(ctx, id)
+    }
+
     url := fmt.Sprintf("%s/_api/web/lists/getbytitle('Site Pages')/items(%s)", s.baseURL, id)
 
     req, _ := http.NewRequest("DELETE", url, nil)
-    req.Header.Set("Authorization", "Bearer " + s.accessToken)
     req.Header.Set("If-Match", "*")
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := auth.Do(http.DefaultClient, s.tokens, req)
     if err != nil {
         return err
     }
@@ -108,13 +147,19 @@ func (s *SharePointService) DeletePage(ctx context.Context, id string) error {
 
 // GetPage retrieves a page from SharePoint
 func (s *SharePointService) GetPage(ctx context.Context, id string) (Page, error) {
+    switch s.config.API {
+    case APIGraph:
+        return s.getGraphPage(ctx, id)
+    case APISitePages, "":
+        return s.getSitePage(ctx, id)
+    }
+
     url := fmt.Sprintf("%s/_api/web/lists/getbytitle('Site Pages')/items(%s)", s.baseURL, id)
 
     req, _ := http.NewRequest("GET", url, nil)
-    req.Header.Set("Authorization", "Bearer " + s.accessToken)
     req.Header.Set("Accept", "application/json;odata=verbose")
 
-    resp, err := http.DefaultClient.Do(req)
+    resp, err := auth.Do(http.DefaultClient, s.tokens, req)
     if err != nil {
         return Page{}, err
     }
@@ -138,3 +183,39 @@ func (s *SharePointService) GetPage(ctx context.Context, id string) (Page, error
         Content: content,
     }, nil
 }
+
+// doRequest sends an authenticated JSON request to SharePoint or Microsoft Graph and
+// decodes the response into out when it is not nil
+func (s *SharePointService) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Accept", "application/json;odata=nometadata")
+    req.Header.Set("Content-Type", "application/json;odata=nometadata")
+
+    resp, err := auth.Do(http.DefaultClient, s.tokens, req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil && resp.StatusCode != http.StatusNoContent {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+    "math"
+    "regexp"
+    "strings"
+    "unicode"
+)
+
+// markupPattern matches HTML tags and Markdown punctuation, which platforms add or
+// drop when they store content, so they are ignored when comparing pages
+var markupPattern = regexp.MustCompile(`<[^>]*>|&[a-z]+;|[#*_>\[\]()|` + "`" + `]`)
+
+// normalizeTitle lower-cases a title and reduces it to words separated by single
+// spaces, so "Getting-Started" and "Getting started" compare equal
+func normalizeTitle(title string) string {
+    return strings.Join(words(title), " ")
+}
+
+// words splits text into lower-case words of letters and digits
+func words(text string) []string {
+    return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
+
+// titleSimilarity scores how alike two titles are between 0 and 1, from the edit
+// distance between their normalized forms
+func titleSimilarity(a, b string) float64 {
+    ra, rb := []rune(normalizeTitle(a)), []rune(normalizeTitle(b))
+    longest := len(ra)
+    if len(rb) > longest {
+        longest = len(rb)
+    }
+    if longest == 0 {
+        return 1
+    }
+    return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b []rune) int {
+    previous := make([]int, len(b)+1)
+    current := make([]int, len(b)+1)
+    for j := range previous {
+        previous[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        current[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+        }
+        previous, current = current, previous
+    }
+    return previous[len(b)]
+}
+
+// min3 returns the smallest of three integers
+func min3(a, b, c int) int {
+    if b < a {
+        a = b
+    }
+    if c < a {
+        a = c
+    }
+    return a
+}
+
+// contentSimilarity scores how alike two page bodies are between 0 and 1, as the
+// cosine similarity of their word counts with markup removed
+func contentSimilarity(a, b string) float64 {
+    countsA, countsB := wordCounts(a), wordCounts(b)
+    if len(countsA) == 0 || len(countsB) == 0 {
+        if len(countsA) == len(countsB) {
+            return 1
+        }
+        return 0
+    }
+
+    var dot, normA, normB float64
+    for word, n := range countsA {
+        dot += float64(n * countsB[word])
+        normA += float64(n * n)
+    }
+    for _, n := range countsB {
+        normB += float64(n * n)
+    }
+    return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// wordCounts counts the words of a page body once markup is removed
+func wordCounts(content string) map[string]int {
+    counts := map[string]int{}
+    for _, word := range words(markupPattern.ReplaceAllString(content, " ")) {
+        counts[word]++
+    }
+    return counts
+}
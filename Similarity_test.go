@@ -0,0 +1,77 @@
+package main
+
+import (
+    "math"
+    "testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+    tests := []struct {
+        title string
+        want  string
+    }{
+        {"Getting-Started", "getting started"},
+        {"  Getting   started!  ", "getting started"},
+        {"FAQ: Billing & Plans (2024)", "faq billing plans 2024"},
+        {"Über uns", "über uns"},
+        {"", ""},
+    }
+
+    for _, tt := range tests {
+        if got := normalizeTitle(tt.title); got != tt.want {
+            t.Errorf("normalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+        }
+    }
+}
+
+func TestTitleSimilarity(t *testing.T) {
+    tests := []struct {
+        name string
+        a, b string
+        want float64
+    }{
+        {name: "same after normalizing", a: "Getting-Started", b: "getting started", want: 1},
+        {name: "both empty", a: "", b: "!!", want: 1},
+        {name: "one empty", a: "Billing", b: "", want: 0},
+        {name: "one edit", a: "Colour", b: "Color", want: 1 - 1.0/6},
+        {name: "unrelated", a: "abc", b: "xyz", want: 0},
+        {name: "runes count once", a: "café", b: "cafe", want: 0.75},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := titleSimilarity(tt.a, tt.b)
+            if math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("titleSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+            }
+            if reverse := titleSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+                t.Errorf("titleSimilarity is not symmetric: %v and %v", got, reverse)
+            }
+        })
+    }
+}
+
+func TestContentSimilarity(t *testing.T) {
+    tests := []struct {
+        name string
+        a, b string
+        want float64
+    }{
+        {name: "markup is ignored", a: "# Install\n\nRun **setup** now", b: "<h1>Install</h1><p>Run <strong>setup</strong> now</p>", want: 1},
+        {name: "word order is ignored", a: "one two three", b: "three two one", want: 1},
+        {name: "both empty", a: "", b: "<p></p>", want: 1},
+        {name: "one empty", a: "text", b: "", want: 0},
+        {name: "no shared words", a: "alpha beta", b: "gamma delta", want: 0},
+        {name: "half shared", a: "alpha beta", b: "alpha gamma", want: 0.5},
+        {name: "counts matter", a: "alpha alpha", b: "alpha beta", want: 1 / math.Sqrt2},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := contentSimilarity(tt.a, tt.b)
+            if math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("contentSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+            }
+        })
+    }
+}
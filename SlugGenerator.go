@@ -0,0 +1,270 @@
+package slug
+
+import (
+    "fmt"
+    "path"
+    "strings"
+    "sync"
+    "text/template"
+    "unicode"
+)
+
+// Unicode handling modes supported by Config.Unicode
+const (
+    UnicodeTransliterate = "transliterate" // Spell letters in ASCII where known and drop the rest
+    UnicodeKeep          = "keep"          // Keep letters and digits of any script
+    UnicodeStrip         = "strip"         // Drop everything outside ASCII
+)
+
+// defaultPattern places pages in directories named after their section path
+const defaultPattern = "{{.Section}}/{{.Title}}"
+
+// Config holds the settings used to turn page titles into file paths
+type Config struct {
+    Pattern   string `yaml:"pattern"`    // text/template over the slugged .Title, .ID and .Section, defaults to "{{.Section}}/{{.Title}}"
+    Unicode   string `yaml:"unicode"`    // UnicodeTransliterate (default), UnicodeKeep or UnicodeStrip
+    Separator string `yaml:"separator"`  // Replaces spaces and punctuation, defaults to "-"
+    MaxLength int    `yaml:"max_length"` // Longest slug of a single path segment, defaults to 80
+    Extension string `yaml:"extension"`  // File extension, defaults to ".md"
+}
+
+// Generator assigns unique paths to pages. Assignments are kept per page key, so a
+// page keeps its path and other pages get a numbered variant when they collide
+type Generator struct {
+    config  Config
+    pattern *template.Template
+
+    mu     sync.Mutex
+    byPath map[string]string // Page key by assigned path
+    byKey  map[string]string // Assigned path by page key
+}
+
+// New creates a Generator from config
+func New(config Config) (*Generator, error) {
+    text := config.Pattern
+    if text == "" {
+        text = defaultPattern
+    }
+    pattern, err := template.New("slug").Parse(text)
+    if err != nil {
+        return nil, err
+    }
+    return &Generator{
+        config:  config,
+        pattern: pattern,
+        byPath:  make(map[string]string),
+        byKey:   make(map[string]string),
+    }, nil
+}
+
+// Reserve records an existing assignment, such as one loaded from the ID mapping
+func (g *Generator) Reserve(key, path string) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if old, ok := g.byKey[key]; ok {
+        delete(g.byPath, old)
+    }
+    g.byKey[key] = path
+    g.byPath[path] = key
+}
+
+// Release forgets the assignment of a page, freeing its path
+func (g *Generator) Release(key string) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if path, ok := g.byKey[key]; ok {
+        delete(g.byPath, path)
+        delete(g.byKey, key)
+    }
+}
+
+// ReleasePath frees a path, whichever page it is assigned to
+func (g *Generator) ReleasePath(path string) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if key, ok := g.byPath[path]; ok {
+        delete(g.byKey, key)
+        delete(g.byPath, path)
+    }
+}
+
+// Assigned returns the path assigned to a page, if any
+func (g *Generator) Assigned(key string) (string, bool) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    path, ok := g.byKey[key]
+    return path, ok
+}
+
+// Key returns the page a path is assigned to, if any
+func (g *Generator) Key(path string) (string, bool) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    key, ok := g.byPath[path]
+    return key, ok
+}
+
+// Move returns the path for a page whose section may have changed. A page whose
+// pattern now renders into another directory gets a new path there; otherwise it
+// keeps its assigned path, so retitling a page does not move it. The second result
+// reports whether the path changed
+func (g *Generator) Move(key, title string, section []string, exists func(path string) (bool, error)) (string, bool, error) {
+    current, ok := g.Assigned(key)
+    if !ok {
+        created, err := g.Path(key, title, section, exists)
+        return created, false, err
+    }
+
+    base, err := g.render(key, title, section)
+    if err != nil {
+        return "", false, err
+    }
+    if path.Dir(base) == path.Dir(current) {
+        return current, false, nil
+    }
+
+    g.Release(key)
+    moved, err := g.Path(key, title, section, exists)
+    if err != nil {
+        g.Reserve(key, current)
+        return "", false, err
+    }
+    return moved, moved != current, nil
+}
+
+// Path returns the path for a page, generating and reserving one when the page has
+// none yet. exists reports paths taken outside the generator, such as files already
+// in a repository; it may be nil
+func (g *Generator) Path(key, title string, section []string, exists func(path string) (bool, error)) (string, error) {
+    if path, ok := g.Assigned(key); ok {
+        return path, nil
+    }
+
+    base, err := g.render(key, title, section)
+    if err != nil {
+        return "", err
+    }
+    ext := g.extension()
+    stem := strings.TrimSuffix(base, ext)
+
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    for n := 1; ; n++ {
+        candidate := stem + ext
+        if n > 1 {
+            candidate = fmt.Sprintf("%s%s%d%s", stem, g.separator(), n, ext)
+        }
+        if owner, taken := g.byPath[candidate]; taken && owner != key {
+            continue
+        }
+        if exists != nil {
+            found, err := exists(candidate)
+            if err != nil {
+                return "", err
+            }
+            if found {
+                continue
+            }
+        }
+        g.byKey[key] = candidate
+        g.byPath[candidate] = key
+        return candidate, nil
+    }
+}
+
+// render expands the pattern for a page into a clean relative path with extension
+func (g *Generator) render(key, title string, section []string) (string, error) {
+    dirs := make([]string, 0, len(section))
+    for _, name := range section {
+        if slug := g.Slugify(name); slug != "" {
+            dirs = append(dirs, slug)
+        }
+    }
+    data := struct {
+        Title   string
+        ID      string
+        Section string
+    }{
+        Title:   g.Slugify(title),
+        ID:      g.Slugify(key),
+        Section: strings.Join(dirs, "/"),
+    }
+    if data.Title == "" {
+        data.Title = "page"
+    }
+
+    var out strings.Builder
+    if err := g.pattern.Execute(&out, data); err != nil {
+        return "", err
+    }
+
+    cleaned := strings.TrimPrefix(path.Clean("/"+out.String()), "/")
+    if cleaned == "" {
+        cleaned = data.Title
+    }
+    if !strings.HasSuffix(cleaned, g.extension()) {
+        cleaned += g.extension()
+    }
+    return cleaned, nil
+}
+
+// Slugify turns text into a lower-case path segment made of letters, digits and the
+// separator
+func (g *Generator) Slugify(text string) string {
+    separator := g.separator()
+    var out strings.Builder
+    pending := false
+
+    write := func(s string) {
+        if pending && out.Len() > 0 {
+            out.WriteString(separator)
+        }
+        pending = false
+        out.WriteString(s)
+    }
+
+    for _, r := range strings.ToLower(text) {
+        switch {
+        case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+            write(string(r))
+        case g.config.Unicode == UnicodeKeep && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+            write(string(r))
+        case g.config.Unicode != UnicodeKeep && g.config.Unicode != UnicodeStrip && transliterations[r] != "":
+            write(transliterations[r])
+        case r == '\'' || r == '’':
+            // Apostrophes join words rather than separate them
+        default:
+            pending = true
+        }
+    }
+
+    slug := out.String()
+    if max := g.maxLength(); len([]rune(slug)) > max {
+        slug = strings.TrimRight(string([]rune(slug)[:max]), separator)
+    }
+    return slug
+}
+
+// separator returns the word separator
+func (g *Generator) separator() string {
+    if g.config.Separator == "" {
+        return "-"
+    }
+    return g.config.Separator
+}
+
+// extension returns the file extension
+func (g *Generator) extension() string {
+    if g.config.Extension == "" {
+        return ".md"
+    }
+    return g.config.Extension
+}
+
+// maxLength returns the longest slug of a path segment
+func (g *Generator) maxLength() int {
+    if g.config.MaxLength <= 0 {
+        return 80
+    }
+    return g.config.MaxLength
+}
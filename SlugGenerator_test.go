@@ -0,0 +1,148 @@
+package slug
+
+import (
+    "testing"
+)
+
+func TestSlugify(t *testing.T) {
+    tests := []struct {
+        name   string
+        config Config
+        text   string
+        want   string
+    }{
+        {name: "words and punctuation", text: "Getting Started: Step 1!", want: "getting-started-step-1"},
+        {name: "apostrophes join words", text: "Don't panic", want: "dont-panic"},
+        {name: "transliterated by default", text: "Über Straße café", want: "ueber-strasse-cafe"},
+        {name: "unicode kept", config: Config{Unicode: UnicodeKeep}, text: "Über café", want: "über-café"},
+        {name: "unicode stripped", config: Config{Unicode: UnicodeStrip}, text: "Über café", want: "ber-caf"},
+        {name: "custom separator", config: Config{Separator: "_"}, text: "Read me first", want: "read_me_first"},
+        {name: "cut to the maximum length", config: Config{MaxLength: 8}, text: "release notes", want: "release"},
+        {name: "nothing left", text: "?!", want: ""},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            g, err := New(tt.config)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got := g.Slugify(tt.text); got != tt.want {
+                t.Errorf("Slugify(%q) = %q, want %q", tt.text, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestPath(t *testing.T) {
+    type request struct {
+        key     string
+        title   string
+        section []string
+        want    string
+    }
+
+    tests := []struct {
+        name     string
+        config   Config
+        existing map[string]bool // Paths taken outside the generator
+        requests []request
+    }{
+        {
+            name: "section directories",
+            requests: []request{
+                {key: "1", title: "Install", section: []string{"User Guide", "Setup"}, want: "user-guide/setup/install.md"},
+                {key: "2", title: "Home", want: "home.md"},
+            },
+        },
+        {
+            name: "collisions are numbered",
+            requests: []request{
+                {key: "1", title: "FAQ", want: "faq.md"},
+                {key: "2", title: "F.A.Q.", want: "f-a-q.md"},
+                {key: "3", title: "faq", want: "faq-2.md"},
+                {key: "4", title: "FAQ!", want: "faq-3.md"},
+            },
+        },
+        {
+            name:     "existing files are skipped",
+            existing: map[string]bool{"faq.md": true},
+            requests: []request{{key: "1", title: "FAQ", want: "faq-2.md"}},
+        },
+        {
+            name: "a page keeps its path",
+            requests: []request{
+                {key: "1", title: "FAQ", want: "faq.md"},
+                {key: "1", title: "Questions", want: "faq.md"},
+            },
+        },
+        {
+            name:   "pattern and extension",
+            config: Config{Pattern: "{{.ID}}-{{.Title}}", Extension: ".markdown"},
+            requests: []request{
+                {key: "A1", title: "Intro", want: "a1-intro.markdown"},
+            },
+        },
+        {
+            name: "empty title",
+            requests: []request{
+                {key: "1", title: "???", want: "page.md"},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            g, err := New(tt.config)
+            if err != nil {
+                t.Fatal(err)
+            }
+            exists := func(path string) (bool, error) { return tt.existing[path], nil }
+            for _, r := range tt.requests {
+                got, err := g.Path(r.key, r.title, r.section, exists)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if got != r.want {
+                    t.Errorf("Path(%q, %q) = %q, want %q", r.key, r.title, got, r.want)
+                }
+            }
+        })
+    }
+}
+
+func TestMove(t *testing.T) {
+    g, err := New(Config{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    g.Reserve("1", "guides/install.md")
+    g.Reserve("2", "reference/install.md")
+
+    tests := []struct {
+        name      string
+        title     string
+        section   []string
+        want      string
+        wantMoved bool
+    }{
+        {name: "retitled in place", title: "Installation", section: []string{"Guides"}, want: "guides/install.md"},
+        {name: "moved to a taken name", title: "Install", section: []string{"Reference"}, want: "reference/install-2.md", wantMoved: true},
+        {name: "moved back", title: "Install", section: []string{"Guides"}, want: "guides/install.md", wantMoved: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, moved, err := g.Move("1", tt.title, tt.section, nil)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got != tt.want || moved != tt.wantMoved {
+                t.Errorf("Move() = %q, %v, want %q, %v", got, moved, tt.want, tt.wantMoved)
+            }
+            if key, ok := g.Key(got); !ok || key != "1" {
+                t.Errorf("Key(%q) = %q, %v, want \"1\"", got, key, ok)
+            }
+        })
+    }
+}
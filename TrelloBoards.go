@@ -0,0 +1,223 @@
+package trello
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+// trelloItem is the id and name of a board, list or label
+type trelloItem struct {
+    ID   string `json:"id"`
+    Name string `json:"name"`
+}
+
+// resolveBoard returns the ID of the configured board, looking it up by name among
+// the boards of the token owner
+func (s *TrelloServiceImpl) resolveBoard(ctx context.Context) (string, error) {
+    if s.config.BoardID != "" {
+        return s.config.BoardID, nil
+    }
+
+    s.mu.Lock()
+    boardID := s.boardID
+    s.mu.Unlock()
+    if boardID != "" {
+        return boardID, nil
+    }
+
+    var boards []trelloItem
+    if err := s.doRequest(ctx, "list boards", "GET", s.baseURL+"/members/me/boards?fields=name&filter=open", nil, &boards); err != nil {
+        return "", err
+    }
+    for _, board := range boards {
+        if strings.EqualFold(board.Name, s.config.Board) {
+            s.mu.Lock()
+            s.boardID = board.ID
+            s.mu.Unlock()
+            return board.ID, nil
+        }
+    }
+
+    return "", fmt.Errorf("board %q not found in Trello", s.config.Board)
+}
+
+// loadLists fills the list caches with the open lists of the board
+func (s *TrelloServiceImpl) loadLists(ctx context.Context, boardID string) error {
+    s.mu.Lock()
+    loaded := s.lists != nil
+    s.mu.Unlock()
+    if loaded {
+        return nil
+    }
+
+    var lists []trelloItem
+    url := fmt.Sprintf("%s/boards/%s/lists?fields=name&filter=open", s.baseURL, boardID)
+    if err := s.doRequest(ctx, "list lists", "GET", url, nil, &lists); err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.lists = make(map[string]string)
+    s.listNames = make(map[string]string)
+    for _, list := range lists {
+        s.lists[strings.ToLower(list.Name)] = list.ID
+        s.listNames[list.ID] = list.Name
+    }
+    return nil
+}
+
+// resolveList returns the ID of the named list on the board, creating the list at the
+// end of the board when it is missing and CreateMissing is set
+func (s *TrelloServiceImpl) resolveList(ctx context.Context, name string) (string, error) {
+    boardID, err := s.resolveBoard(ctx)
+    if err != nil {
+        return "", err
+    }
+    if err := s.loadLists(ctx, boardID); err != nil {
+        return "", err
+    }
+
+    s.mu.Lock()
+    listID, ok := s.lists[strings.ToLower(name)]
+    s.mu.Unlock()
+    if ok {
+        return listID, nil
+    }
+    if !s.config.CreateMissing {
+        return "", fmt.Errorf("list %q not found on Trello board", name)
+    }
+
+    var created trelloItem
+    reqBody := map[string]interface{}{"name": name, "idBoard": boardID, "pos": "bottom"}
+    if err := s.doRequest(ctx, "create list", "POST", s.baseURL+"/lists", reqBody, &created); err != nil {
+        return "", err
+    }
+
+    s.mu.Lock()
+    s.lists[strings.ToLower(name)] = created.ID
+    s.listNames[created.ID] = name
+    s.mu.Unlock()
+
+    return created.ID, nil
+}
+
+// listNameByID returns the name of a list on the board, or "" when it is unknown
+func (s *TrelloServiceImpl) listNameByID(ctx context.Context, listID string) string {
+    boardID, err := s.resolveBoard(ctx)
+    if err != nil || s.loadLists(ctx, boardID) != nil {
+        return ""
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.listNames[listID]
+}
+
+// resolveLabels returns the IDs of the board labels named in names. Missing labels are
+// created when CreateMissing is set and skipped otherwise
+func (s *TrelloServiceImpl) resolveLabels(ctx context.Context, names []string) ([]string, error) {
+    if len(names) == 0 {
+        return []string{}, nil
+    }
+    boardID, err := s.resolveBoard(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    s.mu.Lock()
+    loaded := s.labels != nil
+    s.mu.Unlock()
+    if !loaded {
+        var labels []trelloItem
+        url := fmt.Sprintf("%s/boards/%s/labels?fields=name&limit=1000", s.baseURL, boardID)
+        if err := s.doRequest(ctx, "list labels", "GET", url, nil, &labels); err != nil {
+            return nil, err
+        }
+        s.mu.Lock()
+        s.labels = make(map[string]string)
+        for _, label := range labels {
+            if label.Name != "" {
+                s.labels[strings.ToLower(label.Name)] = label.ID
+            }
+        }
+        s.mu.Unlock()
+    }
+
+    var ids []string
+    for _, name := range names {
+        s.mu.Lock()
+        id, ok := s.labels[strings.ToLower(name)]
+        s.mu.Unlock()
+        if !ok {
+            if !s.config.CreateMissing {
+                continue
+            }
+            var created trelloItem
+            reqBody := map[string]interface{}{"name": name, "idBoard": boardID}
+            if s.config.LabelColor != "" {
+                reqBody["color"] = s.config.LabelColor
+            }
+            if err := s.doRequest(ctx, "create label", "POST", s.baseURL+"/labels", reqBody, &created); err != nil {
+                return nil, err
+            }
+            id = created.ID
+            s.mu.Lock()
+            s.labels[strings.ToLower(name)] = id
+            s.mu.Unlock()
+        }
+        ids = append(ids, id)
+    }
+
+    return ids, nil
+}
+
+// authURL adds the API key and token to a Trello API URL
+func (s *TrelloServiceImpl) authURL(rawURL string) string {
+    separator := "?"
+    if strings.Contains(rawURL, "?") {
+        separator = "&"
+    }
+    return rawURL + separator + "key=" + url.QueryEscape(s.apiKey) + "&token=" + url.QueryEscape(s.apiToken)
+}
+
+// doRequest sends an authenticated JSON request to the Trello API and decodes the
+// response into out when it is not nil
+func (s *TrelloServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, s.authURL(url), reqBody)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
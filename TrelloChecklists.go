@@ -0,0 +1,241 @@
+package trello
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// taskPattern matches a Markdown task list item
+var taskPattern = regexp.MustCompile(`^\s*[-*] \[([ xX])\] (.*)$`)
+
+// headingPattern matches a Markdown ATX heading
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+
+// repeatSuffix matches the " (2)" suffix added to repeated checklist names
+var repeatSuffix = regexp.MustCompile(` \(\d+\)$`)
+
+// checklist is a Trello checklist rendered from a Markdown task list
+type checklist struct {
+    Name  string
+    Items []checkItem
+}
+
+// checkItem is a single task of a checklist
+type checkItem struct {
+    Name    string
+    Checked bool
+}
+
+// equal reports whether two checklists have the same name and items
+func (c checklist) equal(other checklist) bool {
+    if c.Name != other.Name || len(c.Items) != len(other.Items) {
+        return false
+    }
+    for i := range c.Items {
+        if c.Items[i] != other.Items[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// markdown renders the checklist items as a Markdown task list
+func (c checklist) markdown() []string {
+    lines := make([]string, 0, len(c.Items))
+    for _, item := range c.Items {
+        mark := " "
+        if item.Checked {
+            mark = "x"
+        }
+        lines = append(lines, fmt.Sprintf("- [%s] %s", mark, item.Name))
+    }
+    return lines
+}
+
+// splitChecklists removes the task lists from Markdown content and returns them as
+// checklists named after the heading they appear under. Task lists inside code
+// fences are left alone
+func splitChecklists(content string) (string, []checklist) {
+    lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+    var kept []string
+    var lists []checklist
+    used := make(map[string]int)
+    heading := "Checklist"
+    inFence := false
+
+    for i := 0; i < len(lines); i++ {
+        line := lines[i]
+        if strings.HasPrefix(strings.TrimSpace(line), "```") {
+            inFence = !inFence
+        }
+        if inFence {
+            kept = append(kept, line)
+            continue
+        }
+        if match := headingPattern.FindStringSubmatch(line); match != nil {
+            heading = match[1]
+        }
+
+        if !taskPattern.MatchString(line) {
+            kept = append(kept, line)
+            continue
+        }
+
+        list := checklist{Name: heading}
+        if used[heading]++; used[heading] > 1 {
+            list.Name = fmt.Sprintf("%s (%d)", heading, used[heading])
+        }
+        for ; i < len(lines); i++ {
+            match := taskPattern.FindStringSubmatch(lines[i])
+            if match == nil {
+                break
+            }
+            list.Items = append(list.Items, checkItem{Name: match[2], Checked: match[1] != " "})
+        }
+        lists = append(lists, list)
+
+        // A task list ending the content takes the blank lines before it along
+        if i == len(lines) {
+            for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+                kept = kept[:len(kept)-1]
+            }
+            break
+        }
+
+        // Drop the blank line that separated the task list from what follows
+        if i < len(lines) && strings.TrimSpace(lines[i]) == "" && (len(kept) == 0 || strings.TrimSpace(kept[len(kept)-1]) == "") {
+            continue
+        }
+        i--
+    }
+
+    return strings.Join(kept, "\n"), lists
+}
+
+// mergeChecklists puts checklists back into Markdown content as task lists, at the
+// end of the section under the heading they are named after, or at the end of the
+// content when no such heading exists
+func mergeChecklists(content string, lists []checklist) string {
+    var lines []string
+    if content != "" {
+        lines = strings.Split(content, "\n")
+    }
+
+    for _, list := range lists {
+        name := repeatSuffix.ReplaceAllString(list.Name, "")
+        start, end := -1, len(lines)
+        for i, line := range lines {
+            match := headingPattern.FindStringSubmatch(line)
+            if match == nil {
+                continue
+            }
+            if start >= 0 {
+                end = i
+                break
+            }
+            if match[1] == name {
+                start = i
+            }
+        }
+
+        // Insert after the last non-blank line of the section
+        at := end
+        for at > start+1 && strings.TrimSpace(lines[at-1]) == "" {
+            at--
+        }
+        block := list.markdown()
+        if at > 0 {
+            block = append([]string{""}, block...)
+        }
+        if at < len(lines) && strings.TrimSpace(lines[at]) != "" {
+            block = append(block, "")
+        }
+
+        lines = append(lines[:at], append(block, lines[at:]...)...)
+    }
+
+    return strings.Join(lines, "\n")
+}
+
+// getChecklists returns the checklists of a card in board order
+func (s *TrelloServiceImpl) getChecklists(ctx context.Context, cardID string) ([]checklist, []string, error) {
+    var result []struct {
+        ID         string  `json:"id"`
+        Name       string  `json:"name"`
+        Pos        float64 `json:"pos"`
+        CheckItems []struct {
+            Name  string  `json:"name"`
+            State string  `json:"state"`
+            Pos   float64 `json:"pos"`
+        } `json:"checkItems"`
+    }
+    url := fmt.Sprintf("%s/cards/%s/checklists", s.baseURL, cardID)
+    if err := s.doRequest(ctx, "get checklists", "GET", url, nil, &result); err != nil {
+        return nil, nil, err
+    }
+
+    sort.SliceStable(result, func(i, j int) bool { return result[i].Pos < result[j].Pos })
+    lists := make([]checklist, 0, len(result))
+    ids := make([]string, 0, len(result))
+    for _, r := range result {
+        items := r.CheckItems
+        sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+        list := checklist{Name: r.Name}
+        for _, item := range items {
+            list.Items = append(list.Items, checkItem{Name: item.Name, Checked: item.State == "complete"})
+        }
+        lists = append(lists, list)
+        ids = append(ids, r.ID)
+    }
+
+    return lists, ids, nil
+}
+
+// syncChecklists makes the checklists of a card match want, keeping unchanged
+// checklists and replacing the others
+func (s *TrelloServiceImpl) syncChecklists(ctx context.Context, cardID string, want []checklist) error {
+    existing, ids, err := s.getChecklists(ctx, cardID)
+    if err != nil {
+        return err
+    }
+
+    matched := make([]bool, len(want))
+    for i, list := range existing {
+        keep := false
+        for j := range want {
+            if !matched[j] && list.equal(want[j]) {
+                matched[j], keep = true, true
+                break
+            }
+        }
+        if keep {
+            continue
+        }
+        if err := s.doRequest(ctx, "delete checklist", "DELETE", fmt.Sprintf("%s/checklists/%s", s.baseURL, ids[i]), nil, nil); err != nil {
+            return err
+        }
+    }
+
+    for j, list := range want {
+        if matched[j] {
+            continue
+        }
+        var created trelloItem
+        reqBody := map[string]interface{}{"idCard": cardID, "name": list.Name, "pos": "bottom"}
+        if err := s.doRequest(ctx, "create checklist", "POST", s.baseURL+"/checklists", reqBody, &created); err != nil {
+            return err
+        }
+        for _, item := range list.Items {
+            url := fmt.Sprintf("%s/checklists/%s/checkItems", s.baseURL, created.ID)
+            reqBody := map[string]interface{}{"name": item.Name, "checked": item.Checked, "pos": "bottom"}
+            if err := s.doRequest(ctx, "create checklist item", "POST", url, reqBody, nil); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
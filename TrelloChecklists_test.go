@@ -0,0 +1,122 @@
+package trello
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestSplitChecklists(t *testing.T) {
+    tests := []struct {
+        name        string
+        content     string
+        wantContent string
+        wantLists   []checklist
+    }{
+        {
+            name:        "no task lists",
+            content:     "# Setup\n\n- plain item",
+            wantContent: "# Setup\n\n- plain item",
+        },
+        {
+            name:        "task list before any heading",
+            content:     "- [ ] one\n- [x] two",
+            wantContent: "",
+            wantLists: []checklist{{Name: "Checklist", Items: []checkItem{
+                {Name: "one"},
+                {Name: "two", Checked: true},
+            }}},
+        },
+        {
+            name:        "named after the heading above",
+            content:     "# Setup\n\nIntro\n\n- [ ] install\n- [X] configure\n\n## Next\n\nMore",
+            wantContent: "# Setup\n\nIntro\n\n## Next\n\nMore",
+            wantLists: []checklist{{Name: "Setup", Items: []checkItem{
+                {Name: "install"},
+                {Name: "configure", Checked: true},
+            }}},
+        },
+        {
+            name:        "repeated headings are numbered",
+            content:     "# Steps\n\n- [ ] a\n\n# Steps\n\n- [ ] b",
+            wantContent: "# Steps\n\n# Steps",
+            wantLists: []checklist{
+                {Name: "Steps", Items: []checkItem{{Name: "a"}}},
+                {Name: "Steps (2)", Items: []checkItem{{Name: "b"}}},
+            },
+        },
+        {
+            name:        "task lists in code fences are kept",
+            content:     "```\n- [ ] not a task\n```",
+            wantContent: "```\n- [ ] not a task\n```",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            content, lists := splitChecklists(tt.content)
+            if content != tt.wantContent {
+                t.Errorf("content = %q, want %q", content, tt.wantContent)
+            }
+            if !reflect.DeepEqual(lists, tt.wantLists) {
+                t.Errorf("checklists = %+v, want %+v", lists, tt.wantLists)
+            }
+        })
+    }
+}
+
+func TestMergeChecklists(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+        lists   []checklist
+        want    string
+    }{
+        {
+            name:    "empty content",
+            content: "",
+            lists:   []checklist{{Name: "Checklist", Items: []checkItem{{Name: "one"}}}},
+            want:    "- [ ] one",
+        },
+        {
+            name:    "end of the named section",
+            content: "# Setup\n\nIntro\n\n## Next\n\nMore",
+            lists:   []checklist{{Name: "Setup", Items: []checkItem{{Name: "install"}, {Name: "configure", Checked: true}}}},
+            want:    "# Setup\n\nIntro\n\n- [ ] install\n- [x] configure\n\n## Next\n\nMore",
+        },
+        {
+            name:    "unknown heading goes to the end",
+            content: "# Setup\n\nIntro",
+            lists:   []checklist{{Name: "Elsewhere", Items: []checkItem{{Name: "x"}}}},
+            want:    "# Setup\n\nIntro\n\n- [ ] x",
+        },
+        {
+            name:    "numbered name matches its heading",
+            content: "# Steps\n\nFirst",
+            lists:   []checklist{{Name: "Steps (2)", Items: []checkItem{{Name: "b"}}}},
+            want:    "# Steps\n\nFirst\n\n- [ ] b",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := mergeChecklists(tt.content, tt.lists); got != tt.want {
+                t.Errorf("mergeChecklists() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestChecklistRoundTrip(t *testing.T) {
+    contents := []string{
+        "# Setup\n\nIntro\n\n- [ ] install\n- [x] configure\n\n## Next\n\nMore",
+        "Intro\n\n- [ ] one\n- [x] two",
+    }
+
+    for _, content := range contents {
+        t.Run(content, func(t *testing.T) {
+            if got := mergeChecklists(splitChecklists(content)); got != content {
+                t.Errorf("mergeChecklists(splitChecklists(%q)) = %q", content, got)
+            }
+        })
+    }
+}
@@ -0,0 +1,47 @@
+package trello
+
+import "strings"
+
+// TrelloConfig holds the board, list and label settings used when syncing pages to
+// Trello cards
+type TrelloConfig struct {
+    Board         string                      `yaml:"board"`          // Name of the board cards are placed on
+    BoardID       string                      `yaml:"board_id"`       // ID of the board, used instead of Board when set
+    List          string                      `yaml:"list"`           // Name of the list for pages without a status list
+    StatusLists   map[string]string           `yaml:"status_lists"`   // List names keyed by page status, e.g. draft: Draft
+    SectionLists  bool                        `yaml:"section_lists"`  // Use the last element of the section path as the list for pages without a status list
+    CreateMissing bool                        `yaml:"create_missing"` // Create lists and labels that do not exist yet
+    LabelColor    string                      `yaml:"label_color"`    // Colour of created labels, empty for no colour
+    Pages         map[string]TrelloPageConfig `yaml:"pages"`          // Per-page overrides keyed by page ID
+}
+
+// TrelloPageConfig overrides the list for a single page
+type TrelloPageConfig struct {
+    List string `yaml:"list"`
+}
+
+// listName returns the name of the list a page belongs in, from its override, its
+// status, its section or the default list
+func (c TrelloConfig) listName(page Page) string {
+    if override, ok := c.Pages[page.CanonicalID()]; ok && override.List != "" {
+        return override.List
+    }
+    if name, ok := c.StatusLists[strings.ToLower(page.Status)]; ok && page.Status != "" {
+        return name
+    }
+    if c.SectionLists && len(page.Section) > 0 {
+        return page.Section[len(page.Section)-1]
+    }
+    return c.List
+}
+
+// statusOf returns the page status mapped to a list name, or "" when the list is not
+// a status list
+func (c TrelloConfig) statusOf(listName string) string {
+    for status, name := range c.StatusLists {
+        if strings.EqualFold(name, listName) {
+            return status
+        }
+    }
+    return ""
+}
@@ -0,0 +1,68 @@
+package trello
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestListName(t *testing.T) {
+    config := TrelloConfig{
+        List:         "Backlog",
+        StatusLists:  map[string]string{"draft": "Drafts"},
+        SectionLists: true,
+        Pages:        map[string]TrelloPageConfig{"pinned": {List: "Pinned"}},
+    }
+
+    tests := []struct {
+        name string
+        page Page
+        want string
+    }{
+        {"default list", Page{ID: "p1"}, "Backlog"},
+        {"status list", Page{ID: "p1", Status: "Draft", Section: []string{"Guides"}}, "Drafts"},
+        {"last section", Page{ID: "p1", Section: []string{"Guides", "Install"}}, "Install"},
+        {"unmapped status", Page{ID: "p1", Status: "published", Section: []string{"Guides"}}, "Guides"},
+        {"override", Page{ID: "pinned", Status: "draft"}, "Pinned"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := config.listName(tt.page); got != tt.want {
+                t.Errorf("listName() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestCardPosition(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode([]trelloItem{{ID: "l1", Name: "Install"}})
+    }))
+    defer server.Close()
+
+    s := NewTrelloServiceWithConfig("key", "token", TrelloConfig{BoardID: "b1", SectionLists: true})
+    s.baseURL = server.URL
+
+    tests := []struct {
+        position int
+        want     interface{}
+    }{
+        {0, nil},
+        {1, cardPositionStep},
+        {3, 3 * cardPositionStep},
+    }
+    for _, tt := range tests {
+        fields, _, err := s.cardFields(context.Background(), Page{ID: "p1", Section: []string{"Install"}, Position: tt.position})
+        if err != nil {
+            t.Fatalf("cardFields() error = %v", err)
+        }
+        if fields["idList"] != "l1" {
+            t.Errorf("idList = %v, want l1", fields["idList"])
+        }
+        if got := fields["pos"]; got != tt.want {
+            t.Errorf("pos for position %d = %v, want %v", tt.position, got, tt.want)
+        }
+    }
+}
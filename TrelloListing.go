@@ -0,0 +1,58 @@
+package trello
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// cardsPerPage is the largest number of cards Trello returns per request
+const cardsPerPage = 1000
+
+// ListPages lists the open cards of the configured board. Trello returns cards newest
+// first and pages backwards with the before parameter, set to the ID of the oldest
+// card seen so far. Each card's list is reported as its parent
+func (s *TrelloServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.listCards)
+}
+
+// listCards fetches the page of cards created before the card ID held in cursor
+func (s *TrelloServiceImpl) listCards(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    boardID, err := s.resolveBoard(ctx)
+    if err != nil {
+        return nil, "", err
+    }
+
+    endpoint := fmt.Sprintf("%s/boards/%s/cards?filter=open&fields=name,dateLastActivity,idList&limit=%d", s.baseURL, boardID, cardsPerPage)
+    if cursor != "" {
+        endpoint += "&before=" + url.QueryEscape(cursor)
+    }
+
+    var cards []struct {
+        ID               string    `json:"id"`
+        Name             string    `json:"name"`
+        DateLastActivity time.Time `json:"dateLastActivity"`
+        IDList           string    `json:"idList"`
+    }
+    if err := s.doRequest(ctx, "list cards", "GET", endpoint, nil, &cards); err != nil {
+        return nil, "", err
+    }
+
+    summaries := make([]listing.Summary, 0, len(cards))
+    for _, card := range cards {
+        summaries = append(summaries, listing.Summary{
+            ID:       card.ID,
+            Title:    card.Name,
+            Updated:  card.DateLastActivity,
+            ParentID: card.IDList,
+        })
+    }
+
+    if len(cards) < cardsPerPage {
+        return summaries, "", nil
+    }
+    return summaries, cards[len(cards)-1].ID, nil
+}
@@ -0,0 +1,72 @@
+package trello
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestListPages(t *testing.T) {
+    tests := []struct {
+        name   string
+        count  int
+        before []string // before parameter of each request
+    }{
+        {"partial page", 3, []string{""}},
+        {"full pages", 2 * cardsPerPage, []string{"", fmt.Sprintf("c%05d", cardsPerPage), fmt.Sprintf("c%05d", 2*cardsPerPage)}},
+        {"more than a page", cardsPerPage + 1, []string{"", fmt.Sprintf("c%05d", cardsPerPage)}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            // Cards are served newest first, numbered from the newest, so "before" a
+            // card means after it in the listing
+            var before []string
+            server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if r.URL.Path != "/boards/b1/cards" {
+                    http.NotFound(w, r)
+                    return
+                }
+                query := r.URL.Query()
+                before = append(before, query.Get("before"))
+                start := 1
+                if cursor := query.Get("before"); cursor != "" {
+                    fmt.Sscanf(strings.TrimPrefix(cursor, "c"), "%d", &start)
+                    start++
+                }
+                cards := []map[string]string{}
+                for i := start; i <= tt.count && len(cards) < cardsPerPage; i++ {
+                    cards = append(cards, map[string]string{
+                        "id":               fmt.Sprintf("c%05d", i),
+                        "name":             fmt.Sprintf("Card %d", i),
+                        "dateLastActivity": "2024-03-01T12:00:00Z",
+                        "idList":           "l1",
+                    })
+                }
+                json.NewEncoder(w).Encode(cards)
+            }))
+            defer server.Close()
+
+            s := NewTrelloServiceWithConfig("key", "token", TrelloConfig{BoardID: "b1"})
+            s.baseURL = server.URL
+            got, err := s.ListPages(context.Background()).All()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if len(got) != tt.count {
+                t.Fatalf("ListPages() listed %d cards, want %d", len(got), tt.count)
+            }
+            if last := got[len(got)-1]; last.ID != fmt.Sprintf("c%05d", tt.count) || last.ParentID != "l1" {
+                t.Errorf("last card = %+v, want the oldest card in list l1", last)
+            }
+            if !reflect.DeepEqual(before, tt.before) {
+                t.Errorf("before = %q, want %q", before, tt.before)
+            }
+        })
+    }
+}
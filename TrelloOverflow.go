@@ -0,0 +1,171 @@
+package trello
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "mime/multipart"
+    "net/http"
+    "regexp"
+    "strings"
+)
+
+// maxDescriptionLength is the most characters Trello accepts in a card description
+const maxDescriptionLength = 16384
+
+// overflowFileName is the name of the attachment holding the full content of a card
+// whose description had to be truncated
+const overflowFileName = "content.md"
+
+// overflowNotice is appended to truncated descriptions and links to the attachment
+const overflowNotice = "\n\n---\n*Full article continues in the attached [" + overflowFileName + "](%s)*"
+
+// overflowPattern recognises the notice at the end of a truncated description
+var overflowPattern = regexp.MustCompile(`\n\n---\n\*Full article continues in the attached \[` + regexp.QuoteMeta(overflowFileName) + `\]\([^)]*\)\*$`)
+
+// overflows reports whether a description is too long for Trello
+func overflows(desc string) bool {
+    return len([]rune(desc)) > maxDescriptionLength
+}
+
+// truncatedDescription cuts desc to fit Trello's limit together with the notice
+// linking to the attachment, preferring to cut at a line break
+func truncatedDescription(desc, link string) string {
+    notice := fmt.Sprintf(overflowNotice, link)
+    runes := []rune(desc)
+    budget := maxDescriptionLength - len([]rune(notice))
+    if len(runes) <= budget {
+        return desc + notice
+    }
+
+    cut := string(runes[:budget])
+    if newline := strings.LastIndex(cut, "\n"); newline > len(cut)/2 {
+        cut = cut[:newline]
+    }
+    return strings.TrimRight(cut, " \n") + notice
+}
+
+// trelloAttachment is an attachment of a card
+type trelloAttachment struct {
+    ID   string `json:"id"`
+    Name string `json:"name"`
+    URL  string `json:"url"`
+}
+
+// overflowAttachments returns the overflow attachments of a card
+func (s *TrelloServiceImpl) overflowAttachments(ctx context.Context, cardID string) ([]trelloAttachment, error) {
+    var attachments []trelloAttachment
+    url := fmt.Sprintf("%s/cards/%s/attachments?fields=name,url", s.baseURL, cardID)
+    if err := s.doRequest(ctx, "list attachments", "GET", url, nil, &attachments); err != nil {
+        return nil, err
+    }
+
+    var overflow []trelloAttachment
+    for _, attachment := range attachments {
+        if attachment.Name == overflowFileName {
+            overflow = append(overflow, attachment)
+        }
+    }
+    return overflow, nil
+}
+
+// removeOverflow deletes the overflow attachments of a card
+func (s *TrelloServiceImpl) removeOverflow(ctx context.Context, cardID string) error {
+    attachments, err := s.overflowAttachments(ctx, cardID)
+    if err != nil {
+        return err
+    }
+    for _, attachment := range attachments {
+        url := fmt.Sprintf("%s/cards/%s/attachments/%s", s.baseURL, cardID, attachment.ID)
+        if err := s.doRequest(ctx, "delete attachment", "DELETE", url, nil, nil); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// uploadOverflow replaces the overflow attachment of a card with content and returns
+// the URL of the new attachment
+func (s *TrelloServiceImpl) uploadOverflow(ctx context.Context, cardID, content string) (string, error) {
+    if err := s.removeOverflow(ctx, cardID); err != nil {
+        return "", err
+    }
+
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+    writer.WriteField("name", overflowFileName)
+    writer.WriteField("mimeType", "text/markdown")
+    part, err := writer.CreateFormFile("file", overflowFileName)
+    if err != nil {
+        return "", err
+    }
+    part.Write([]byte(content))
+    if err := writer.Close(); err != nil {
+        return "", err
+    }
+
+    url := fmt.Sprintf("%s/cards/%s/attachments", s.baseURL, cardID)
+    req, err := http.NewRequestWithContext(ctx, "POST", s.authURL(url), body)
+    if err != nil {
+        return "", err
+    }
+    req.Header.Set("Content-Type", writer.FormDataContentType())
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return "", fmt.Errorf("failed to upload attachment: %s - %s", resp.Status, respBody)
+    }
+
+    var attachment trelloAttachment
+    if err := json.NewDecoder(resp.Body).Decode(&attachment); err != nil {
+        return "", err
+    }
+    return attachment.URL, nil
+}
+
+// readOverflow returns the full content of a card whose description was truncated,
+// or the description itself when it was not
+func (s *TrelloServiceImpl) readOverflow(ctx context.Context, cardID, desc string) (string, error) {
+    if !overflowPattern.MatchString(desc) {
+        return desc, nil
+    }
+
+    attachments, err := s.overflowAttachments(ctx, cardID)
+    if err != nil {
+        return "", err
+    }
+    if len(attachments) == 0 {
+        return "", fmt.Errorf("failed to find attachment %s on card %s", overflowFileName, cardID)
+    }
+
+    // Attachment downloads need the key and token in an OAuth header rather than the query
+    req, err := http.NewRequestWithContext(ctx, "GET", attachments[len(attachments)-1].URL, nil)
+    if err != nil {
+        return "", err
+    }
+    req.Header.Set("Authorization", fmt.Sprintf(`OAuth oauth_consumer_key="%s", oauth_token="%s"`, s.apiKey, s.apiToken))
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+
+    content, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return "", err
+    }
+    if resp.StatusCode != http.StatusOK {
+        return "", fmt.Errorf("failed to download attachment: %s - %s", resp.Status, content)
+    }
+
+    return string(content), nil
+}
@@ -0,0 +1,72 @@
+package trello
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+func TestTruncatedDescription(t *testing.T) {
+    const link = "https://trello.com/1/cards/abc/attachments/def/download/content.md"
+    notice := fmt.Sprintf(overflowNotice, link)
+    budget := maxDescriptionLength - len([]rune(notice))
+
+    tests := []struct {
+        name string
+        desc string
+        want string // Expected description without the notice
+    }{
+        {
+            name: "short description is kept whole",
+            desc: "short",
+            want: "short",
+        },
+        {
+            name: "cut at the last line break",
+            desc: strings.Repeat("a", budget-10) + "\nrest of the line that does not fit",
+            want: strings.Repeat("a", budget-10),
+        },
+        {
+            name: "cut mid-line without a late line break",
+            desc: "intro\n" + strings.Repeat("b", maxDescriptionLength),
+            want: "intro\n" + strings.Repeat("b", budget-len("intro\n")),
+        },
+        {
+            name: "multi-byte characters count once",
+            desc: strings.Repeat("é", maxDescriptionLength+1),
+            want: strings.Repeat("é", budget),
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := truncatedDescription(tt.desc, link)
+            if got != tt.want+notice {
+                t.Errorf("truncatedDescription() = %q, want %q", got, tt.want+notice)
+            }
+            if overflows(got) {
+                t.Errorf("truncatedDescription() is %d characters, over the limit", len([]rune(got)))
+            }
+            if !overflowPattern.MatchString(got) {
+                t.Errorf("truncatedDescription() notice is not recognised by overflowPattern")
+            }
+        })
+    }
+}
+
+func TestOverflows(t *testing.T) {
+    tests := []struct {
+        desc string
+        want bool
+    }{
+        {strings.Repeat("a", maxDescriptionLength), false},
+        {strings.Repeat("a", maxDescriptionLength+1), true},
+        {strings.Repeat("é", maxDescriptionLength), false},
+    }
+
+    for _, tt := range tests {
+        if got := overflows(tt.desc); got != tt.want {
+            t.Errorf("overflows(%d characters) = %v, want %v", len([]rune(tt.desc)), got, tt.want)
+        }
+    }
+}
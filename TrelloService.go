@@ -1,6 +1,10 @@
 package trello
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // TrelloService defines the methods for interacting with Trello
 type TrelloService interface {
@@ -8,4 +12,5 @@ type TrelloService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
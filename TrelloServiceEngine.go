@@ -7,6 +7,9 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "strings"
+    "sync"
+    "time"
 )
 
 // TrelloServiceImpl is the implementation of the TrelloService interface
@@ -14,6 +17,13 @@ type TrelloServiceImpl struct {
     apiKey    string
     apiToken  string
     baseURL   string
+    config    TrelloConfig
+
+    mu        sync.Mutex
+    boardID   string            // Resolved board ID
+    lists     map[string]string // List IDs by lower-cased name
+    listNames map[string]string // List names by ID
+    labels    map[string]string // Label IDs by lower-cased name
 }
 
 // NewTrelloService creates a new instance of TrelloService
@@ -25,15 +35,31 @@ func NewTrelloService(apiKey, apiToken string) *TrelloServiceImpl {
     }
 }
 
-// CreatePage creates a new card in Trello
+// NewTrelloServiceWithConfig creates a new instance of TrelloService that places cards
+// on the configured board and lists
+func NewTrelloServiceWithConfig(apiKey, apiToken string, config TrelloConfig) *TrelloServiceImpl {
+    service := NewTrelloService(apiKey, apiToken)
+    service.config = config
+    return service
+}
+
+// CreatePage creates a new card in the Trello list for the page's status. Page labels
+// become card labels and Markdown task lists become checklists. Content too long for
+// a card description is attached in full and the description truncated
 func (s *TrelloServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
+    fields, lists, err := s.cardFields(ctx, page)
+    if err != nil {
+        return "", err
+    }
+    fields["keepFromSource"] = "all"
+    desc := fields["desc"].(string)
+    if overflows(desc) {
+        // The link to the attachment is filled in once the card exists
+        fields["desc"] = truncatedDescription(desc, "")
+    }
+
     url := fmt.Sprintf("%s/cards?key=%s&token=%s", s.baseURL, s.apiKey, s.apiToken)
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "name":        page.Title,
-        "desc":        page.Content,
-        "idList":      "YOUR_LIST_ID", // Replace with your actual list ID
-        "keepFromSource": "all",
-    })
+    reqBody, _ := json.Marshal(fields)
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Content-Type", "application/json")
@@ -58,17 +84,45 @@ func (s *TrelloServiceImpl) CreatePage(ctx context.Context, page Page) (string,
         return "", fmt.Errorf("failed to parse card ID")
     }
 
+    if overflows(desc) {
+        link, err := s.uploadOverflow(ctx, cardID, desc)
+        if err != nil {
+            return cardID, err
+        }
+        url := fmt.Sprintf("%s/cards/%s", s.baseURL, cardID)
+        reqBody := map[string]interface{}{"desc": truncatedDescription(desc, link)}
+        if err := s.doRequest(ctx, "update card description", "PUT", url, reqBody, nil); err != nil {
+            return cardID, err
+        }
+    }
+
+    if err := s.syncChecklists(ctx, cardID, lists); err != nil {
+        return cardID, err
+    }
+
     return cardID, nil
 }
 
-// UpdatePage updates an existing card in Trello
+// UpdatePage updates an existing card in Trello, moving it to another list when the
+// page's status or section maps to a different list and to its position within the
+// list. The overflow attachment is replaced when the content is too long for the
+// description and removed when it fits again
 func (s *TrelloServiceImpl) UpdatePage(ctx context.Context, page Page) error {
-    url := fmt.Sprintf("%s/cards/%s?key=%s&token=%s", s.baseURL, page.ID, s.apiKey, s.apiToken)
+    fields, lists, err := s.cardFields(ctx, page)
+    if err != nil {
+        return err
+    }
+    desc := fields["desc"].(string)
+    if overflows(desc) {
+        link, err := s.uploadOverflow(ctx, page.ID, desc)
+        if err != nil {
+            return err
+        }
+        fields["desc"] = truncatedDescription(desc, link)
+    }
 
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "name":        page.Title,
-        "desc":        page.Content,
-    })
+    url := fmt.Sprintf("%s/cards/%s?key=%s&token=%s", s.baseURL, page.ID, s.apiKey, s.apiToken)
+    reqBody, _ := json.Marshal(fields)
 
     req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
     req.Header.Set("Content-Type", "application/json")
@@ -84,7 +138,13 @@ func (s *TrelloServiceImpl) UpdatePage(ctx context.Context, page Page) error {
         return fmt.Errorf("failed to update card: %s - %s", resp.Status, body)
     }
 
-    return nil
+    if !overflows(desc) {
+        if err := s.removeOverflow(ctx, page.ID); err != nil {
+            return err
+        }
+    }
+
+    return s.syncChecklists(ctx, page.ID, lists)
 }
 
 // DeletePage deletes a card from Trello
@@ -106,7 +166,20 @@ func (s *TrelloServiceImpl) DeletePage(ctx context.Context, id string) error {
     return nil
 }
 
-// GetPage retrieves a card from Trello
+// ArchivePage closes a card, which archives it on its board
+func (s *TrelloServiceImpl) ArchivePage(ctx context.Context, id string) error {
+    url := fmt.Sprintf("%s/cards/%s", s.baseURL, id)
+    return s.doRequest(ctx, "archive card", "PUT", url, map[string]interface{}{"closed": true}, nil)
+}
+
+// ReadsLabels reports that GetPage returns the labels of a card
+func (s *TrelloServiceImpl) ReadsLabels() bool {
+    return true
+}
+
+// GetPage retrieves a card from Trello, with truncated content read back from its
+// attachment, its checklists merged back into the content as task lists and its list
+// reported as the page status
 func (s *TrelloServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
     url := fmt.Sprintf("%s/cards/%s?key=%s&token=%s", s.baseURL, id, s.apiKey, s.apiToken)
 
@@ -122,12 +195,76 @@ func (s *TrelloServiceImpl) GetPage(ctx context.Context, id string) (Page, error
         return Page{}, fmt.Errorf("failed to get card: %s - %s", resp.Status, body)
     }
 
-    var result map[string]interface{}
-    json.NewDecoder(resp.Body).Decode(&result)
+    var result struct {
+        ID               string       `json:"id"`
+        Name             string       `json:"name"`
+        Desc             string       `json:"desc"`
+        IDList           string       `json:"idList"`
+        Labels           []trelloItem `json:"labels"`
+        DateLastActivity time.Time    `json:"dateLastActivity"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return Page{}, err
+    }
 
-    return Page{
-        ID:      result["id"].(string),
-        Title:   result["name"].(string),
-        Content: result["desc"].(string),
-    }, nil
+    content, err := s.readOverflow(ctx, id, result.Desc)
+    if err != nil {
+        return Page{}, err
+    }
+    lists, _, err := s.getChecklists(ctx, id)
+    if err != nil {
+        return Page{}, err
+    }
+
+    page := Page{
+        ID:        result.ID,
+        Title:     result.Name,
+        Content:   mergeChecklists(content, lists),
+        Timestamp: result.DateLastActivity,
+    }
+    for _, label := range result.Labels {
+        if label.Name != "" {
+            page.Labels = append(page.Labels, label.Name)
+        }
+    }
+    if s.config.Board != "" || s.config.BoardID != "" {
+        listName := s.listNameByID(ctx, result.IDList)
+        page.Status = s.config.statusOf(listName)
+        if s.config.SectionLists && page.Status == "" && listName != "" {
+            page.Section = []string{listName}
+        }
+    }
+
+    return page, nil
+}
+
+// cardPositionStep is the gap Trello leaves between the pos values of cards added to a
+// list, so hand-made cards keep their place among synced ones
+const cardPositionStep = 16384
+
+// cardFields builds the card body for a page, resolving its list and labels, and
+// returns the checklists split out of the content
+func (s *TrelloServiceImpl) cardFields(ctx context.Context, page Page) (map[string]interface{}, []checklist, error) {
+    listID, err := s.resolveList(ctx, s.config.listName(page))
+    if err != nil {
+        return nil, nil, err
+    }
+    labelIDs, err := s.resolveLabels(ctx, page.Labels)
+    if err != nil {
+        return nil, nil, err
+    }
+    desc, lists := splitChecklists(page.Content)
+
+    fields := map[string]interface{}{
+        "name":     page.Title,
+        "desc":     desc,
+        "idList":   listID,
+        "idLabels": strings.Join(labelIDs, ","),
+    }
+    if page.Position != 0 {
+        // Trello orders cards by ascending pos and spaces the cards it places itself
+        // cardPositionStep apart, so positions are scaled to interleave with them
+        fields["pos"] = page.Position * cardPositionStep
+    }
+    return fields, lists, nil
 }
@@ -0,0 +1,337 @@
+package zendesk
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// ZendeskMultiBrandService fans a single Zendesk account out to several brand help
+// centers. Each brand gets its own article, so the page ID it returns combines the
+// per-brand article IDs as "brand=id" pairs separated by commas
+type ZendeskMultiBrandService struct {
+    accountURL string
+    email      string
+    apiToken   string
+    brands     []ZendeskBrandConfig
+
+    mu      sync.Mutex
+    clients map[string]*ZendeskServiceImpl
+    results    map[string]map[string]error // operation -> brand -> error
+    reassigned map[string]string           // Combined IDs extended by UpdatePage, keyed by the ID it was given
+}
+
+// BrandErrors collects the failures of a multi-brand operation keyed by brand name
+type BrandErrors map[string]error
+
+func (e BrandErrors) Error() string {
+    names := make([]string, 0, len(e))
+    for name := range e {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    parts := make([]string, 0, len(names))
+    for _, name := range names {
+        parts = append(parts, fmt.Sprintf("%s: %v", name, e[name]))
+    }
+    return "brand errors: " + strings.Join(parts, "; ")
+}
+
+// NewZendeskMultiBrandService creates a service that writes every page to each brand in config.Brands
+func NewZendeskMultiBrandService(accountURL, email, apiToken string, config ZendeskConfig) *ZendeskMultiBrandService {
+    return &ZendeskMultiBrandService{
+        accountURL: accountURL,
+        email:      email,
+        apiToken:   apiToken,
+        brands:     config.Brands,
+        clients:    map[string]*ZendeskServiceImpl{},
+    }
+}
+
+// CreatePage creates the article in every brand and returns the combined ID of the
+// brands where it was created, including brands where a later step such as writing
+// translations failed
+func (s *ZendeskMultiBrandService) CreatePage(ctx context.Context, page Page) (string, error) {
+    ids := map[string]string{}
+    err := s.forEachBrand(ctx, "create", func(brand ZendeskBrandConfig, client *ZendeskServiceImpl) error {
+        id, err := client.CreatePage(ctx, brandPage(brand, page))
+        if id != "" {
+            ids[brand.Name] = id
+        }
+        return err
+    })
+    return s.encodeID(ids), err
+}
+
+// UpdatePage updates the article of every brand named in the combined page ID and
+// creates it in the brands missing from the ID, such as brands that failed when the
+// page was created. CurrentID then reports the combined ID including them
+func (s *ZendeskMultiBrandService) UpdatePage(ctx context.Context, page Page) error {
+    ids := decodeBrandIDs(page.ID)
+    err := s.forEachBrand(ctx, "update", func(brand ZendeskBrandConfig, client *ZendeskServiceImpl) error {
+        branded := brandPage(brand, page)
+        if id, ok := ids[brand.Name]; ok {
+            branded.ID = id
+            return client.UpdatePage(ctx, branded)
+        }
+
+        created, err := client.CreatePage(ctx, branded)
+        if created != "" {
+            ids[brand.Name] = created
+        }
+        return err
+    })
+
+    if combined := s.encodeID(ids); combined != page.ID {
+        s.mu.Lock()
+        if s.reassigned == nil {
+            s.reassigned = map[string]string{}
+        }
+        s.reassigned[page.ID] = combined
+        s.mu.Unlock()
+    }
+    return err
+}
+
+// CurrentID returns the combined ID a page has after UpdatePage created its article
+// in brands that were missing from id
+func (s *ZendeskMultiBrandService) CurrentID(ctx context.Context, id string) (string, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if current, ok := s.reassigned[id]; ok {
+        return current, nil
+    }
+    return id, nil
+}
+
+// DeletePage deletes the article of every brand named in the combined page ID
+func (s *ZendeskMultiBrandService) DeletePage(ctx context.Context, id string) error {
+    ids := decodeBrandIDs(id)
+    return s.forEachBrand(ctx, "delete", func(brand ZendeskBrandConfig, client *ZendeskServiceImpl) error {
+        if articleID, ok := ids[brand.Name]; ok {
+            return client.DeletePage(ctx, articleID)
+        }
+        return nil
+    })
+}
+
+// ArchivePage archives the article in every brand that has it
+func (s *ZendeskMultiBrandService) ArchivePage(ctx context.Context, id string) error {
+    return s.DeletePage(ctx, id)
+}
+
+// GetPage retrieves the article from the first configured brand that has one. The
+// returned content includes that brand's overrides
+func (s *ZendeskMultiBrandService) GetPage(ctx context.Context, id string) (Page, error) {
+    ids := decodeBrandIDs(id)
+    for _, brand := range s.brands {
+        articleID, ok := ids[brand.Name]
+        if !ok {
+            continue
+        }
+        client, err := s.client(ctx, brand)
+        if err != nil {
+            return Page{}, err
+        }
+        page, err := client.GetPage(ctx, articleID)
+        if err != nil {
+            return Page{}, err
+        }
+        page.ID = id
+        return page, nil
+    }
+    return Page{}, fmt.Errorf("no brand article found for page %s", id)
+}
+
+// ReadsLabels reports that GetPage returns the labels of an article
+func (s *ZendeskMultiBrandService) ReadsLabels() bool {
+    return true
+}
+
+// TargetResults reports the outcome of the latest create, update and delete per
+// brand, keyed by operation and then brand name; a nil error means the brand succeeded
+func (s *ZendeskMultiBrandService) TargetResults() map[string]map[string]error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    results := make(map[string]map[string]error, len(s.results))
+    for op, brands := range s.results {
+        results[op] = make(map[string]error, len(brands))
+        for brand, err := range brands {
+            results[op][brand] = err
+        }
+    }
+    return results
+}
+
+// forEachBrand runs fn against every brand, recording per-brand results under op. A failing
+// brand does not stop the others
+func (s *ZendeskMultiBrandService) forEachBrand(ctx context.Context, op string, fn func(ZendeskBrandConfig, *ZendeskServiceImpl) error) error {
+    results := map[string]error{}
+    errs := BrandErrors{}
+
+    for _, brand := range s.brands {
+        client, err := s.client(ctx, brand)
+        if err == nil {
+            err = fn(brand, client)
+        }
+        results[brand.Name] = err
+        if err != nil {
+            errs[brand.Name] = err
+        }
+    }
+
+    s.mu.Lock()
+    if s.results == nil {
+        s.results = map[string]map[string]error{}
+    }
+    s.results[op] = results
+    s.mu.Unlock()
+
+    if len(errs) > 0 {
+        return errs
+    }
+    return nil
+}
+
+// client returns the help center client for a brand, resolving its URL from the
+// account's brand list when it is not configured
+func (s *ZendeskMultiBrandService) client(ctx context.Context, brand ZendeskBrandConfig) (*ZendeskServiceImpl, error) {
+    s.mu.Lock()
+    client, ok := s.clients[brand.Name]
+    s.mu.Unlock()
+    if ok {
+        return client, nil
+    }
+
+    baseURL := brand.BaseURL
+    if baseURL == "" {
+        var err error
+        if baseURL, err = s.brandURL(ctx, brand.Name); err != nil {
+            return nil, err
+        }
+    }
+
+    client = NewZendeskServiceWithConfig(strings.TrimSuffix(baseURL, "/"), s.email, s.apiToken, brand.Placement)
+
+    s.mu.Lock()
+    s.clients[brand.Name] = client
+    s.mu.Unlock()
+
+    return client, nil
+}
+
+// brandURL looks up a brand's help center URL by name or subdomain
+func (s *ZendeskMultiBrandService) brandURL(ctx context.Context, name string) (string, error) {
+    account := NewZendeskService(s.accountURL, s.email, s.apiToken)
+    url := fmt.Sprintf("%s/api/v2/brands.json", s.accountURL)
+
+    for url != "" {
+        var result map[string]interface{}
+        if err := account.doRequest(ctx, "list brands", "GET", url, nil, &result); err != nil {
+            return "", err
+        }
+
+        items, _ := result["brands"].([]interface{})
+        for _, item := range items {
+            brand, ok := item.(map[string]interface{})
+            if !ok {
+                continue
+            }
+            brandName, _ := brand["name"].(string)
+            subdomain, _ := brand["subdomain"].(string)
+            if strings.EqualFold(brandName, name) || strings.EqualFold(subdomain, name) {
+                brandURL, _ := brand["brand_url"].(string)
+                return brandURL, nil
+            }
+        }
+
+        url, _ = result["next_page"].(string)
+    }
+
+    return "", fmt.Errorf("brand %s not found in Zendesk account", name)
+}
+
+// brandPage applies a brand's content overrides to a page and its translations
+func brandPage(brand ZendeskBrandConfig, page Page) Page {
+    if len(brand.ContentOverrides) == 0 {
+        return page
+    }
+
+    // Replace longer strings first so "Acme Cloud" wins over "Acme"
+    pairs := make([]string, 0, len(brand.ContentOverrides)*2)
+    keys := make([]string, 0, len(brand.ContentOverrides))
+    for from := range brand.ContentOverrides {
+        keys = append(keys, from)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        if len(keys[i]) != len(keys[j]) {
+            return len(keys[i]) > len(keys[j])
+        }
+        return keys[i] < keys[j]
+    })
+    for _, from := range keys {
+        pairs = append(pairs, from, brand.ContentOverrides[from])
+    }
+    replacer := strings.NewReplacer(pairs...)
+
+    branded := page
+    branded.Title = replacer.Replace(page.Title)
+    branded.Content = replacer.Replace(page.Content)
+    if len(page.Translations) > 0 {
+        branded.Translations = make(map[string]Page, len(page.Translations))
+        for locale, variant := range page.Translations {
+            variant.Title = replacer.Replace(variant.Title)
+            variant.Content = replacer.Replace(variant.Content)
+            branded.Translations[locale] = variant
+        }
+    }
+    return branded
+}
+
+// encodeID combines per-brand article IDs in configured brand order
+func (s *ZendeskMultiBrandService) encodeID(ids map[string]string) string {
+    parts := make([]string, 0, len(ids))
+    for _, brand := range s.brands {
+        if id, ok := ids[brand.Name]; ok {
+            parts = append(parts, brand.Name+"="+id)
+        }
+    }
+    return strings.Join(parts, ",")
+}
+
+// SplitID splits a combined page ID into the combined IDs of each brand's article,
+// keyed by brand name, which is how ListPages reports articles
+func (s *ZendeskMultiBrandService) SplitID(id string) map[string]string {
+    ids := map[string]string{}
+    for brand, articleID := range decodeBrandIDs(id) {
+        ids[brand] = s.encodeID(map[string]string{brand: articleID})
+    }
+    return ids
+}
+
+// JoinIDs merges combined page IDs into one, the later IDs winning for a brand
+// named in several
+func (s *ZendeskMultiBrandService) JoinIDs(ids ...string) string {
+    merged := map[string]string{}
+    for _, id := range ids {
+        for brand, articleID := range decodeBrandIDs(id) {
+            merged[brand] = articleID
+        }
+    }
+    return s.encodeID(merged)
+}
+
+// decodeBrandIDs splits a combined page ID into per-brand article IDs
+func decodeBrandIDs(id string) map[string]string {
+    ids := map[string]string{}
+    for _, part := range strings.Split(id, ",") {
+        if kv := strings.SplitN(part, "=", 2); len(kv) == 2 {
+            ids[kv[0]] = kv[1]
+        }
+    }
+    return ids
+}
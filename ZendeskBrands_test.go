@@ -0,0 +1,99 @@
+package zendesk
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+)
+
+func TestBrandPage(t *testing.T) {
+    brand := ZendeskBrandConfig{ContentOverrides: map[string]string{"Acme": "Beta", "Acme Cloud": "Beta Sky"}}
+    page := Page{
+        Title:        "Acme Cloud",
+        Content:      "Acme Cloud by Acme",
+        Translations: map[string]Page{"de": {Title: "Acme", Content: "Acme Cloud von Acme"}},
+    }
+
+    got := brandPage(brand, page)
+    if got.Title != "Beta Sky" || got.Content != "Beta Sky by Beta" {
+        t.Errorf("brandPage() = %q, %q", got.Title, got.Content)
+    }
+    if de := got.Translations["de"]; de.Title != "Beta" || de.Content != "Beta Sky von Beta" {
+        t.Errorf("brandPage() translation = %q, %q", de.Title, de.Content)
+    }
+    if page.Translations["de"].Title != "Acme" {
+        t.Errorf("brandPage() changed the original page")
+    }
+}
+
+func TestBrandIDs(t *testing.T) {
+    s := NewZendeskMultiBrandService("", "", "", ZendeskConfig{Brands: []ZendeskBrandConfig{{Name: "a"}, {Name: "b"}}})
+
+    if got := s.encodeID(map[string]string{"b": "2", "a": "1"}); got != "a=1,b=2" {
+        t.Errorf("encodeID() = %q, want brands in configured order", got)
+    }
+    if got, want := s.SplitID("a=1,b=2"), map[string]string{"a": "a=1", "b": "b=2"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("SplitID() = %v, want %v", got, want)
+    }
+    if got := s.JoinIDs("a=1", "b=2", "a=3"); got != "a=3,b=2" {
+        t.Errorf("JoinIDs() = %q, want %q", got, "a=3,b=2")
+    }
+}
+
+func TestMultiBrandPartialFailure(t *testing.T) {
+    a := &fakeHelpCenter{}
+    serverA := httptest.NewServer(a)
+    defer serverA.Close()
+
+    failing := true
+    b := &fakeHelpCenter{}
+    serverB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if failing {
+            http.Error(w, "unavailable", http.StatusServiceUnavailable)
+            return
+        }
+        b.ServeHTTP(w, r)
+    }))
+    defer serverB.Close()
+
+    // Brand b has no URL configured, so it is looked up in the account's brand list
+    account := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode(map[string]interface{}{"brands": []map[string]string{
+            {"name": "Other", "subdomain": "other", "brand_url": "http://invalid"},
+            {"name": "Brand B", "subdomain": "b", "brand_url": serverB.URL},
+        }})
+    }))
+    defer account.Close()
+
+    s := NewZendeskMultiBrandService(account.URL, "me@example.com", "token", ZendeskConfig{Brands: []ZendeskBrandConfig{
+        {Name: "a", BaseURL: serverA.URL, Placement: ZendeskConfig{SectionID: 1}},
+        {Name: "b", Placement: ZendeskConfig{SectionID: 2}},
+    }})
+    ctx := context.Background()
+
+    id, err := s.CreatePage(ctx, Page{ID: "p", Title: "Hello"})
+    if id != "a=7" {
+        t.Errorf("CreatePage() = %q, want the ID of the brand that succeeded", id)
+    }
+    brandErrs, ok := err.(BrandErrors)
+    if !ok || len(brandErrs) != 1 || brandErrs["b"] == nil {
+        t.Fatalf("CreatePage() error = %v, want an error for brand b only", err)
+    }
+    if results := s.TargetResults()["create"]; results["a"] != nil || results["b"] == nil {
+        t.Errorf("TargetResults() = %v", results)
+    }
+
+    failing = false
+    if err := s.UpdatePage(ctx, Page{ID: id, Title: "Hello"}); err != nil {
+        t.Fatal(err)
+    }
+    if current, _ := s.CurrentID(ctx, id); current != "a=7,b=7" {
+        t.Errorf("CurrentID() = %q, want the article created in brand b added", current)
+    }
+    if want := []string{"POST /sections/2/articles.json"}; !reflect.DeepEqual(b.requests, want) {
+        t.Errorf("brand b requests = %q, want %q", b.requests, want)
+    }
+}
@@ -0,0 +1,73 @@
+package zendesk
+
+import "strings"
+
+// defaultLocale is used when neither the page nor the config names a locale
+const defaultLocale = "en-us"
+
+// ZendeskConfig holds the Help Center placement settings used when syncing pages to Zendesk
+type ZendeskConfig struct {
+    Locale            string                       `yaml:"locale"`              // Source locale of new articles, defaults to en-us
+    SectionID         int64                        `yaml:"section_id"`          // Section for pages without a section path
+    CreateMissing     bool                         `yaml:"create_missing"`      // Create categories and sections named by section paths that do not exist yet
+    PermissionGroupID int64                        `yaml:"permission_group_id"` // Group allowed to edit and publish the articles
+    UserSegmentID     int64                        `yaml:"user_segment_id"`     // Segment allowed to view the articles, 0 for everyone
+    Pages             map[string]ZendeskPageConfig `yaml:"pages"`               // Per-page overrides keyed by page ID
+    Brands            []ZendeskBrandConfig         `yaml:"brands"`              // Brand help centers to fan out to, see NewZendeskMultiBrandService
+}
+
+// ZendeskBrandConfig describes one brand help center of a multi-brand account. Its
+// placement settings replace the account defaults, and ContentOverrides rewrites
+// product names, links and other brand-specific text before content is written
+type ZendeskBrandConfig struct {
+    Name             string            `yaml:"name"`              // Brand name or subdomain as listed in the account
+    BaseURL          string            `yaml:"base_url"`          // Help center URL, looked up from the account when empty
+    Placement        ZendeskConfig     `yaml:"placement"`         // Sections, groups and segments within this brand
+    ContentOverrides map[string]string `yaml:"content_overrides"` // Replacement text keyed by the text it replaces
+}
+
+// ZendeskPageConfig overrides the default placement for a single page. Zero values
+// inherit the defaults; UserSegmentID is a pointer so a page can be opened to
+// everyone by setting it to 0
+type ZendeskPageConfig struct {
+    SectionID         int64  `yaml:"section_id"`
+    PermissionGroupID int64  `yaml:"permission_group_id"`
+    UserSegmentID     *int64 `yaml:"user_segment_id"`
+}
+
+// placement returns the effective settings for a page
+func (c ZendeskConfig) placement(pageID string) ZendeskPageConfig {
+    segment := c.UserSegmentID
+    p := ZendeskPageConfig{SectionID: c.SectionID, PermissionGroupID: c.PermissionGroupID, UserSegmentID: &segment}
+    if override, ok := c.Pages[pageID]; ok {
+        if override.SectionID != 0 {
+            p.SectionID = override.SectionID
+        }
+        if override.PermissionGroupID != 0 {
+            p.PermissionGroupID = override.PermissionGroupID
+        }
+        if override.UserSegmentID != nil {
+            p.UserSegmentID = override.UserSegmentID
+        }
+    }
+    return p
+}
+
+// userSegment returns the user_segment_id value to send, where null means everyone
+func (p ZendeskPageConfig) userSegment() interface{} {
+    if p.UserSegmentID == nil || *p.UserSegmentID == 0 {
+        return nil
+    }
+    return *p.UserSegmentID
+}
+
+// locale returns the source locale of a page
+func (c ZendeskConfig) locale(page Page) string {
+    switch {
+    case page.Locale != "":
+        return strings.ToLower(page.Locale)
+    case c.Locale != "":
+        return strings.ToLower(c.Locale)
+    }
+    return defaultLocale
+}
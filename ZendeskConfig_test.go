@@ -0,0 +1,60 @@
+package zendesk
+
+import (
+    "testing"
+)
+
+func TestPlacement(t *testing.T) {
+    everyone := int64(0)
+    config := ZendeskConfig{
+        SectionID:         1,
+        PermissionGroupID: 2,
+        UserSegmentID:     3,
+        Pages: map[string]ZendeskPageConfig{
+            "moved":  {SectionID: 10},
+            "public": {UserSegmentID: &everyone},
+        },
+    }
+
+    tests := []struct {
+        page        string
+        wantSection int64
+        wantGroup   int64
+        wantSegment interface{}
+    }{
+        {page: "plain", wantSection: 1, wantGroup: 2, wantSegment: int64(3)},
+        {page: "moved", wantSection: 10, wantGroup: 2, wantSegment: int64(3)},
+        {page: "public", wantSection: 1, wantGroup: 2, wantSegment: nil},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.page, func(t *testing.T) {
+            p := config.placement(tt.page)
+            if p.SectionID != tt.wantSection || p.PermissionGroupID != tt.wantGroup || p.userSegment() != tt.wantSegment {
+                t.Errorf("placement(%q) = %d, %d, %v, want %d, %d, %v", tt.page,
+                    p.SectionID, p.PermissionGroupID, p.userSegment(), tt.wantSection, tt.wantGroup, tt.wantSegment)
+            }
+        })
+    }
+}
+
+func TestLocale(t *testing.T) {
+    tests := []struct {
+        name   string
+        config ZendeskConfig
+        page   Page
+        want   string
+    }{
+        {name: "default", want: "en-us"},
+        {name: "configured", config: ZendeskConfig{Locale: "en-GB"}, want: "en-gb"},
+        {name: "page wins", config: ZendeskConfig{Locale: "en-gb"}, page: Page{Locale: "de"}, want: "de"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := tt.config.locale(tt.page); got != tt.want {
+                t.Errorf("locale() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
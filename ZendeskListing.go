@@ -0,0 +1,92 @@
+package zendesk
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// ListPages lists the articles of the help center, following the next_page links of
+// the articles endpoint. Each article's section is reported as its parent
+func (s *ZendeskServiceImpl) ListPages(ctx context.Context) *listing.Iterator {
+    return listing.New(ctx, s.listArticles)
+}
+
+// listArticles fetches one page of the help center's articles
+func (s *ZendeskServiceImpl) listArticles(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+    endpoint := cursor
+    if endpoint == "" {
+        endpoint = fmt.Sprintf("%s/api/v2/help_center/articles.json?per_page=100", s.baseURL)
+    }
+
+    var result struct {
+        Articles []struct {
+            ID        int64     `json:"id"`
+            Title     string    `json:"title"`
+            UpdatedAt time.Time `json:"updated_at"`
+            SectionID int64     `json:"section_id"`
+        } `json:"articles"`
+        NextPage string `json:"next_page"`
+    }
+    if err := s.doRequest(ctx, "list articles", "GET", endpoint, nil, &result); err != nil {
+        return nil, "", err
+    }
+
+    summaries := make([]listing.Summary, 0, len(result.Articles))
+    for _, article := range result.Articles {
+        summary := listing.Summary{
+            ID:      strconv.FormatInt(article.ID, 10),
+            Title:   article.Title,
+            Updated: article.UpdatedAt,
+        }
+        if article.SectionID != 0 {
+            summary.ParentID = strconv.FormatInt(article.SectionID, 10)
+        }
+        summaries = append(summaries, summary)
+    }
+
+    return summaries, result.NextPage, nil
+}
+
+// ListPages lists the articles of every brand in turn. Each article is reported with
+// a combined ID naming only its own brand
+func (s *ZendeskMultiBrandService) ListPages(ctx context.Context) *listing.Iterator {
+    if len(s.brands) == 0 {
+        return listing.Failed(fmt.Errorf("no Zendesk brands configured to list articles from"))
+    }
+
+    // The cursor holds the index of the brand being listed and its next page URL
+    return listing.New(ctx, func(ctx context.Context, cursor string) ([]listing.Summary, string, error) {
+        index, next := 0, ""
+        if cursor != "" {
+            parts := strings.SplitN(cursor, " ", 2)
+            index, _ = strconv.Atoi(parts[0])
+            next = parts[1]
+        }
+
+        brand := s.brands[index]
+        client, err := s.client(ctx, brand)
+        if err != nil {
+            return nil, "", err
+        }
+        summaries, next, err := client.listArticles(ctx, next)
+        if err != nil {
+            return nil, "", err
+        }
+        for i := range summaries {
+            summaries[i].ID = s.encodeID(map[string]string{brand.Name: summaries[i].ID})
+        }
+
+        if next == "" {
+            index++
+            if index == len(s.brands) {
+                return summaries, "", nil
+            }
+        }
+        return summaries, fmt.Sprintf("%d %s", index, next), nil
+    })
+}
@@ -0,0 +1,128 @@
+package zendesk
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+
+    "Support_Site_Sync/listing"
+)
+
+// fakeArticleListing serves the articles of a help center two per page, linking the
+// pages through next_page
+type fakeArticleListing struct {
+    server   *httptest.Server
+    articles []listing.Summary
+    pages    []string // Requested page numbers
+}
+
+func newFakeArticleListing(articles ...listing.Summary) *fakeArticleListing {
+    f := &fakeArticleListing{articles: articles}
+    f.server = httptest.NewServer(f)
+    return f
+}
+
+func (f *fakeArticleListing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/api/v2/help_center/articles.json" {
+        http.NotFound(w, r)
+        return
+    }
+    page := r.URL.Query().Get("page")
+    f.pages = append(f.pages, page)
+
+    start, _ := strconv.Atoi(page)
+    var articles []map[string]interface{}
+    for i := start; i < len(f.articles) && i < start+2; i++ {
+        article := map[string]interface{}{"title": f.articles[i].Title, "updated_at": f.articles[i].Updated}
+        article["id"], _ = strconv.ParseInt(f.articles[i].ID, 10, 64)
+        article["section_id"], _ = strconv.ParseInt(f.articles[i].ParentID, 10, 64)
+        articles = append(articles, article)
+    }
+    result := map[string]interface{}{"articles": articles}
+    if start+2 < len(f.articles) {
+        result["next_page"] = fmt.Sprintf("%s/api/v2/help_center/articles.json?per_page=100&page=%d", f.server.URL, start+2)
+    }
+    json.NewEncoder(w).Encode(result)
+}
+
+func TestListPages(t *testing.T) {
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    fake := newFakeArticleListing(
+        listing.Summary{ID: "1", Title: "Welcome", Updated: updated},
+        listing.Summary{ID: "2", Title: "Billing", Updated: updated, ParentID: "20"},
+        listing.Summary{ID: "3", Title: "Invoices", Updated: updated, ParentID: "20"},
+    )
+    defer fake.server.Close()
+
+    s := NewZendeskService(fake.server.URL, "agent@example.com", "token")
+    got, err := s.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(got, fake.articles) {
+        t.Errorf("ListPages() = %v, want %v", got, fake.articles)
+    }
+    if want := []string{"", "2"}; !reflect.DeepEqual(fake.pages, want) {
+        t.Errorf("requested pages %q, want %q", fake.pages, want)
+    }
+}
+
+func TestListPagesMultiBrand(t *testing.T) {
+    updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    first := newFakeArticleListing(
+        listing.Summary{ID: "1", Title: "Welcome", Updated: updated},
+        listing.Summary{ID: "2", Title: "Billing", Updated: updated},
+        listing.Summary{ID: "3", Title: "Invoices", Updated: updated},
+    )
+    defer first.server.Close()
+    empty := newFakeArticleListing()
+    defer empty.server.Close()
+    last := newFakeArticleListing(listing.Summary{ID: "1", Title: "Welcome", Updated: updated})
+    defer last.server.Close()
+
+    s := NewZendeskMultiBrandService("", "agent@example.com", "token", ZendeskConfig{Brands: []ZendeskBrandConfig{
+        {Name: "acme", BaseURL: first.server.URL},
+        {Name: "empty", BaseURL: empty.server.URL},
+        {Name: "labs", BaseURL: last.server.URL},
+    }})
+    got, err := s.ListPages(context.Background()).All()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want := []listing.Summary{
+        {ID: "acme=1", Title: "Welcome", Updated: updated},
+        {ID: "acme=2", Title: "Billing", Updated: updated},
+        {ID: "acme=3", Title: "Invoices", Updated: updated},
+        {ID: "labs=1", Title: "Welcome", Updated: updated},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListPages() = %v, want %v", got, want)
+    }
+    for _, brand := range []struct {
+        name  string
+        fake  *fakeArticleListing
+        pages []string
+    }{
+        {"acme", first, []string{"", "2"}},
+        {"empty", empty, []string{""}},
+        {"labs", last, []string{""}},
+    } {
+        if !reflect.DeepEqual(brand.fake.pages, brand.pages) {
+            t.Errorf("brand %s requested pages %q, want %q", brand.name, brand.fake.pages, brand.pages)
+        }
+    }
+}
+
+func TestListPagesWithoutBrands(t *testing.T) {
+    s := NewZendeskMultiBrandService("", "agent@example.com", "token", ZendeskConfig{})
+    if _, err := s.ListPages(context.Background()).All(); err == nil {
+        t.Error("ListPages() without brands succeeded, want an error")
+    }
+}
@@ -0,0 +1,129 @@
+package zendesk
+
+import (
+    "context"
+    "fmt"
+    "strings"
+)
+
+// helpCenterItem is a category or section as listed by the Help Center API
+type helpCenterItem struct {
+    ID              int64  `json:"id"`
+    Name            string `json:"name"`
+    ParentSectionID *int64 `json:"parent_section_id"`
+}
+
+// sectionID returns the section an article belongs in. A section configured for the
+// page wins; otherwise the page's section path is resolved, falling back to the
+// default section for pages without one
+func (s *ZendeskServiceImpl) sectionID(ctx context.Context, page Page) (int64, error) {
+    if override, ok := s.config.Pages[page.CanonicalID()]; ok && override.SectionID != 0 {
+        return override.SectionID, nil
+    }
+    if len(page.Section) > 0 {
+        return s.resolveSection(ctx, page.Section, s.config.locale(page))
+    }
+    return s.config.SectionID, nil
+}
+
+// resolveSection returns the ID of the section named by a section path. The first
+// element names a category, the second a section in it and any further elements
+// nested sections. Missing levels are created when the config allows it
+func (s *ZendeskServiceImpl) resolveSection(ctx context.Context, path []string, locale string) (int64, error) {
+    if len(path) < 2 {
+        return 0, fmt.Errorf("section path %q needs at least a category and a section", strings.Join(path, " / "))
+    }
+
+    key := strings.Join(path, "\x00")
+    s.mu.Lock()
+    id, ok := s.sections[key]
+    s.mu.Unlock()
+    if ok {
+        return id, nil
+    }
+
+    categoryID, err := s.findOrCreate(ctx,
+        fmt.Sprintf("%s/api/v2/help_center/categories.json", s.baseURL),
+        path[0], nil,
+        map[string]interface{}{"category": map[string]interface{}{"name": path[0], "locale": locale}})
+    if err != nil {
+        return 0, err
+    }
+
+    sectionsURL := fmt.Sprintf("%s/api/v2/help_center/categories/%d/sections.json", s.baseURL, categoryID)
+    var parent *int64
+    for _, name := range path[1:] {
+        section := map[string]interface{}{"name": name, "locale": locale}
+        if parent != nil {
+            section["parent_section_id"] = *parent
+        }
+        id, err = s.findOrCreate(ctx, sectionsURL, name, parent, map[string]interface{}{"section": section})
+        if err != nil {
+            return 0, err
+        }
+        sectionID := id
+        parent = &sectionID
+    }
+
+    s.mu.Lock()
+    if s.sections == nil {
+        s.sections = map[string]int64{}
+    }
+    s.sections[key] = id
+    s.mu.Unlock()
+
+    return id, nil
+}
+
+// findOrCreate looks up a category or section by name in a listing endpoint, among
+// the items directly under parent, and creates it through the same endpoint when it
+// is missing
+func (s *ZendeskServiceImpl) findOrCreate(ctx context.Context, url, name string, parent *int64, create map[string]interface{}) (int64, error) {
+    for next := url; next != ""; {
+        var result struct {
+            Categories []helpCenterItem `json:"categories"`
+            Sections   []helpCenterItem `json:"sections"`
+            NextPage   string           `json:"next_page"`
+        }
+        if err := s.doRequest(ctx, "list "+name, "GET", next, nil, &result); err != nil {
+            return 0, err
+        }
+        for _, item := range append(result.Categories, result.Sections...) {
+            if !sameParent(item.ParentSectionID, parent) {
+                continue
+            }
+            if strings.EqualFold(strings.TrimSpace(item.Name), strings.TrimSpace(name)) {
+                return item.ID, nil
+            }
+        }
+        next = result.NextPage
+    }
+
+    if !s.config.CreateMissing {
+        return 0, fmt.Errorf("no %q found in Zendesk and creating missing sections is disabled", name)
+    }
+
+    var created struct {
+        Category *helpCenterItem `json:"category"`
+        Section  *helpCenterItem `json:"section"`
+    }
+    if err := s.doRequest(ctx, "create "+name, "POST", url, create, &created); err != nil {
+        return 0, err
+    }
+    switch {
+    case created.Category != nil:
+        return created.Category.ID, nil
+    case created.Section != nil:
+        return created.Section.ID, nil
+    }
+    return 0, fmt.Errorf("failed to parse ID of %q", name)
+}
+
+// sameParent reports whether a listed item sits directly under parent, where nil
+// stands for the top level of its category
+func sameParent(itemParent, parent *int64) bool {
+    if itemParent == nil || parent == nil {
+        return itemParent == nil && parent == nil
+    }
+    return *itemParent == *parent
+}
@@ -0,0 +1,166 @@
+package zendesk
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeSections serves categories, one per listing page to exercise next_page, and
+// the sections of each category. Creations are recorded as "category <name> <locale>"
+// and "section <category> <name> <parent>"
+type fakeSections struct {
+    server     *httptest.Server
+    categories []helpCenterItem
+    sections   map[int64][]helpCenterItem // Sections by category ID
+    created    []string
+    listings   int
+}
+
+func newFakeSections() *fakeSections {
+    f := &fakeSections{
+        categories: []helpCenterItem{{ID: 1, Name: "General"}, {ID: 2, Name: "Billing"}},
+        sections: map[int64][]helpCenterItem{
+            2: {
+                {ID: 20, Name: "Invoices"},
+                {ID: 21, Name: "EU", ParentSectionID: int64Ptr(20)},
+                {ID: 22, Name: "EU"},
+            },
+        },
+    }
+    f.server = httptest.NewServer(f)
+    return f
+}
+
+func int64Ptr(v int64) *int64 {
+    return &v
+}
+
+func (f *fakeSections) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/api/v2/help_center")
+    var body map[string]map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+
+    switch {
+    case path == "/categories.json" && r.Method == "GET":
+        f.listings++
+        var page int
+        fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+        result := map[string]interface{}{"categories": f.categories[page : page+1]}
+        if page+1 < len(f.categories) {
+            result["next_page"] = fmt.Sprintf("%s/api/v2/help_center/categories.json?page=%d", f.server.URL, page+1)
+        }
+        json.NewEncoder(w).Encode(result)
+
+    case path == "/categories.json":
+        category := helpCenterItem{ID: int64(len(f.categories) + 1), Name: body["category"]["name"].(string)}
+        f.categories = append(f.categories, category)
+        f.created = append(f.created, fmt.Sprintf("category %s %s", category.Name, body["category"]["locale"]))
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]interface{}{"category": category})
+
+    case r.Method == "GET":
+        f.listings++
+        var categoryID int64
+        fmt.Sscanf(path, "/categories/%d/sections.json", &categoryID)
+        json.NewEncoder(w).Encode(map[string]interface{}{"sections": f.sections[categoryID]})
+
+    default:
+        var categoryID int64
+        fmt.Sscanf(path, "/categories/%d/sections.json", &categoryID)
+        section := helpCenterItem{ID: categoryID*10 + int64(len(f.sections[categoryID])), Name: body["section"]["name"].(string)}
+        parent := "-"
+        if id, ok := body["section"]["parent_section_id"].(float64); ok {
+            section.ParentSectionID = int64Ptr(int64(id))
+            parent = fmt.Sprint(int64(id))
+        }
+        f.sections[categoryID] = append(f.sections[categoryID], section)
+        f.created = append(f.created, fmt.Sprintf("section %d %s %s", categoryID, section.Name, parent))
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]interface{}{"section": section})
+    }
+}
+
+func TestSectionID(t *testing.T) {
+    tests := []struct {
+        name    string
+        create  bool
+        page    Page
+        want    int64
+        created []string
+        wantErr bool
+    }{
+        {name: "override", page: Page{ID: "pinned", Section: []string{"Billing", "Invoices"}}, want: 99},
+        {name: "no section", page: Page{ID: "p1"}, want: 5},
+        {name: "existing section", page: Page{ID: "p1", Section: []string{"billing", "Invoices "}}, want: 20},
+        {name: "nested section", page: Page{ID: "p1", Section: []string{"Billing", "Invoices", "EU"}}, want: 21},
+        {name: "top-level section of the same name", page: Page{ID: "p1", Section: []string{"Billing", "EU"}}, want: 22},
+        {
+            name:    "missing section",
+            create:  true,
+            page:    Page{ID: "p1", Section: []string{"Billing", "Invoices", "US"}},
+            want:    23,
+            created: []string{"section 2 US 20"},
+        },
+        {
+            name:    "missing category",
+            create:  true,
+            page:    Page{ID: "p1", Section: []string{"Setup", "Install"}, Locale: "de"},
+            want:    30,
+            created: []string{"category Setup de", "section 3 Install -"},
+        },
+        {name: "creating disabled", page: Page{ID: "p1", Section: []string{"Billing", "Refunds"}}, wantErr: true},
+        {name: "category only", create: true, page: Page{ID: "p1", Section: []string{"Billing"}}, wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            fake := newFakeSections()
+            defer fake.server.Close()
+
+            config := ZendeskConfig{
+                SectionID:     5,
+                CreateMissing: tt.create,
+                Pages:         map[string]ZendeskPageConfig{"pinned": {SectionID: 99}},
+            }
+            s := NewZendeskServiceWithConfig(fake.server.URL, "agent@example.com", "token", config)
+            got, err := s.sectionID(context.Background(), tt.page)
+            if tt.wantErr {
+                if err == nil {
+                    t.Errorf("sectionID() = %d, want an error", got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("sectionID() error = %v", err)
+            }
+            if got != tt.want {
+                t.Errorf("sectionID() = %d, want %d", got, tt.want)
+            }
+            if !reflect.DeepEqual(fake.created, tt.created) {
+                t.Errorf("created = %q, want %q", fake.created, tt.created)
+            }
+        })
+    }
+}
+
+func TestResolveSectionIsCached(t *testing.T) {
+    fake := newFakeSections()
+    defer fake.server.Close()
+
+    s := NewZendeskService(fake.server.URL, "agent@example.com", "token")
+    for i := 0; i < 2; i++ {
+        if id, err := s.resolveSection(context.Background(), []string{"Billing", "Invoices"}, "en-us"); err != nil || id != 20 {
+            t.Fatalf("resolveSection() = %d, %v, want 20", id, err)
+        }
+    }
+    // Two category pages and one section listing for the first lookup only
+    if fake.listings != 3 {
+        t.Errorf("listings = %d, want 3", fake.listings)
+    }
+}
@@ -1,6 +1,10 @@
 package zendesk
 
-import "context"
+import (
+    "context"
+
+    "Support_Site_Sync/listing"
+)
 
 // ZendeskService defines the methods for interacting with Zendesk
 type ZendeskService interface {
@@ -8,4 +12,5 @@ type ZendeskService interface {
     UpdatePage(ctx context.Context, page Page) error
     DeletePage(ctx context.Context, id string) error
     GetPage(ctx context.Context, id string) (Page, error)
+    ListPages(ctx context.Context) *listing.Iterator
 }
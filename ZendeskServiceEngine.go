@@ -7,6 +7,8 @@ import (
     "net/http"
     "bytes"
     "io/ioutil"
+    "strings"
+    "sync"
 )
 
 // ZendeskServiceImpl is the implementation of the ZendeskService interface
@@ -14,6 +16,10 @@ type ZendeskServiceImpl struct {
     baseURL    string
     email      string
     apiToken    string
+    config     ZendeskConfig
+
+    mu       sync.Mutex
+    sections map[string]int64 // Resolved section IDs by section path
 }
 
 // NewZendeskService creates a new instance of ZendeskService
@@ -21,15 +27,39 @@ func NewZendeskService(baseURL, email, apiToken string) *ZendeskServiceImpl {
     return &ZendeskServiceImpl{baseURL: baseURL, email: email, apiToken: apiToken}
 }
 
-// CreatePage creates a new page in Zendesk
+// NewZendeskServiceWithConfig creates a new instance of ZendeskService that places
+// articles according to the given section, permission group and user segment settings
+func NewZendeskServiceWithConfig(baseURL, email, apiToken string, config ZendeskConfig) *ZendeskServiceImpl {
+    return &ZendeskServiceImpl{baseURL: baseURL, email: email, apiToken: apiToken, config: config}
+}
+
+// CreatePage creates a new article in the Zendesk section matching the page's section
+// path, or the configured section, then adds the page's locale variants as translations
+// of it
 func (s *ZendeskServiceImpl) CreatePage(ctx context.Context, page Page) (string, error) {
-    url := fmt.Sprintf("%s/api/v2/help_center/articles.json", s.baseURL)
+    placement := s.config.placement(page.CanonicalID())
+    sectionID, err := s.sectionID(ctx, page)
+    if err != nil {
+        return "", err
+    }
+    if sectionID == 0 {
+        return "", fmt.Errorf("no Zendesk section configured for page %s", page.ID)
+    }
+
+    url := fmt.Sprintf("%s/api/v2/help_center/sections/%d/articles.json", s.baseURL, sectionID)
+    article := map[string]interface{}{
+        "title":               page.Title,
+        "body":                page.Content,
+        "locale":              s.config.locale(page),
+        "permission_group_id": placement.PermissionGroupID,
+        "user_segment_id":     placement.userSegment(),
+    }
+    if page.Position != 0 {
+        article["position"] = page.Position
+    }
     reqBody, _ := json.Marshal(map[string]interface{}{
-        "article": map[string]interface{}{
-            "title":   page.Title,
-            "body":    page.Content,
-            "locale":  "en-us",
-        },
+        "article":            article,
+        "notify_subscribers": false,
     })
 
     req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
@@ -56,57 +86,41 @@ func (s *ZendeskServiceImpl) CreatePage(ctx context.Context, page Page) (string,
         return "", fmt.Errorf("failed to parse article ID")
     }
 
-    return fmt.Sprintf("%.0f", articleID), nil
+    id := fmt.Sprintf("%.0f", articleID)
+    if err := s.syncTranslations(ctx, id, page, true); err != nil {
+        return id, err
+    }
+
+    return id, nil
 }
 
-// UpdatePage updates an existing page in Zendesk
+// UpdatePage updates an existing article in Zendesk. Placement, position and visibility
+// are set on the article, moving it when its section changed, while title and body are
+// written through the translations endpoints, one per locale variant of the page
 func (s *ZendeskServiceImpl) UpdatePage(ctx context.Context, page Page) error {
     url := fmt.Sprintf("%s/api/v2/help_center/articles/%s.json", s.baseURL, page.ID)
 
-    // Fetch the current version to increment
-    req, _ := http.NewRequest("GET", url, nil)
-    req.SetBasicAuth(s.email+"/token", s.apiToken)
-    resp, err := http.DefaultClient.Do(req)
+    placement := s.config.placement(page.CanonicalID())
+    sectionID, err := s.sectionID(ctx, page)
     if err != nil {
         return err
     }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        return fmt.Errorf("failed to get page: %s", resp.Status)
+    article := map[string]interface{}{"user_segment_id": placement.userSegment()}
+    if sectionID != 0 {
+        article["section_id"] = sectionID
     }
-
-    var result map[string]interface{}
-    json.NewDecoder(resp.Body).Decode(&result)
-
-    currentVersion := result["article"].(map[string]interface{})["version"].(float64)
-    newVersion := int(currentVersion) + 1
-
-    reqBody, _ := json.Marshal(map[string]interface{}{
-        "article": map[string]interface{}{
-            "title":   page.Title,
-            "body":    page.Content,
-            "locale":  "en-us",
-            "version": newVersion,
-        },
-    })
-
-    req, _ = http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
-    req.SetBasicAuth(s.email+"/token", s.apiToken)
-    req.Header.Set("Content-Type", "application/json")
-
-    resp, err = http.DefaultClient.Do(req)
-    if err != nil {
-        return err
+    if page.Position != 0 {
+        article["position"] = page.Position
+    }
+    if placement.PermissionGroupID != 0 {
+        article["permission_group_id"] = placement.PermissionGroupID
     }
-    defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        body, _ := ioutil.ReadAll(resp.Body)
-        return fmt.Errorf("failed to update page: %s - %s", resp.Status, body)
+    if err := s.doRequest(ctx, "update article", "PUT", url, map[string]interface{}{"article": article}, nil); err != nil {
+        return err
     }
 
-    return nil
+    return s.syncTranslations(ctx, page.ID, page, false)
 }
 
 // DeletePage deletes a page in Zendesk
@@ -130,6 +144,12 @@ func (s *ZendeskServiceImpl) DeletePage(ctx context.Context, id string) error {
     return nil
 }
 
+// ArchivePage archives an article. Deleting a Help Center article archives it, so
+// this is the same as DeletePage
+func (s *ZendeskServiceImpl) ArchivePage(ctx context.Context, id string) error {
+    return s.DeletePage(ctx, id)
+}
+
 // GetPage retrieves a page from Zendesk
 func (s *ZendeskServiceImpl) GetPage(ctx context.Context, id string) (Page, error) {
     url := fmt.Sprintf("%s/api/v2/help_center/articles/%s.json", s.baseURL, id)
@@ -155,10 +175,79 @@ func (s *ZendeskServiceImpl) GetPage(ctx context.Context, id string) (Page, erro
     pageID := article["id"].(float64)
     title := article["title"].(string)
     content := article["body"].(string)
+    locale, _ := article["source_locale"].(string)
+    position, _ := article["position"].(float64)
+    var labels []string
+    names, _ := article["label_names"].([]interface{})
+    for _, name := range names {
+        if label, ok := name.(string); ok {
+            labels = append(labels, label)
+        }
+    }
+
+    translations, err := s.listTranslations(ctx, id)
+    if err != nil {
+        return Page{}, err
+    }
+    variants := map[string]Page{}
+    for _, t := range translations {
+        variantLocale, _ := t["locale"].(string)
+        if strings.EqualFold(variantLocale, locale) {
+            continue
+        }
+        variantTitle, _ := t["title"].(string)
+        variantBody, _ := t["body"].(string)
+        variants[variantLocale] = Page{ID: id, Title: variantTitle, Content: variantBody, Locale: variantLocale}
+    }
 
     return Page{
-        ID:      fmt.Sprintf("%.0f", pageID),
-        Title:   title,
-        Content: content,
+        ID:           fmt.Sprintf("%.0f", pageID),
+        Title:        title,
+        Content:      content,
+        Labels:       labels,
+        Locale:       locale,
+        Position:     int(position),
+        Translations: variants,
     }, nil
 }
+
+// ReadsLabels reports that GetPage returns the labels of an article
+func (s *ZendeskServiceImpl) ReadsLabels() bool {
+    return true
+}
+
+// doRequest sends an authenticated JSON request to the Help Center API and decodes
+// the response into out when it is not nil
+func (s *ZendeskServiceImpl) doRequest(ctx context.Context, action, method, url string, body interface{}, out interface{}) error {
+    reqBody := &bytes.Buffer{}
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reqBody = bytes.NewBuffer(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return err
+    }
+    req.SetBasicAuth(s.email+"/token", s.apiToken)
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        respBody, _ := ioutil.ReadAll(resp.Body)
+        return fmt.Errorf("failed to %s: %s - %s", action, resp.Status, respBody)
+    }
+
+    if out != nil {
+        return json.NewDecoder(resp.Body).Decode(out)
+    }
+    return nil
+}
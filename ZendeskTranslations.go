@@ -0,0 +1,91 @@
+package zendesk
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// listTranslations returns every translation of an article, following pagination
+func (s *ZendeskServiceImpl) listTranslations(ctx context.Context, articleID string) ([]map[string]interface{}, error) {
+    var translations []map[string]interface{}
+    url := fmt.Sprintf("%s/api/v2/help_center/articles/%s/translations.json", s.baseURL, articleID)
+
+    for url != "" {
+        var result map[string]interface{}
+        if err := s.doRequest(ctx, "list translations", "GET", url, nil, &result); err != nil {
+            return nil, err
+        }
+
+        items, _ := result["translations"].([]interface{})
+        for _, item := range items {
+            if translation, ok := item.(map[string]interface{}); ok {
+                translations = append(translations, translation)
+            }
+        }
+
+        url, _ = result["next_page"].(string)
+    }
+
+    return translations, nil
+}
+
+// syncTranslations writes the source locale and every locale variant of the page
+// as translations of the article, updating those that exist and creating the rest.
+// Translations without a matching variant are left in place. When skipSource is
+// set the source locale is assumed to have been written with the article itself
+func (s *ZendeskServiceImpl) syncTranslations(ctx context.Context, articleID string, page Page, skipSource bool) error {
+    existing := map[string]bool{}
+    if !skipSource || len(page.Translations) > 0 {
+        translations, err := s.listTranslations(ctx, articleID)
+        if err != nil {
+            return err
+        }
+        for _, t := range translations {
+            locale, _ := t["locale"].(string)
+            existing[strings.ToLower(locale)] = true
+        }
+    }
+
+    variants := map[string]Page{}
+    for locale, variant := range page.Translations {
+        variants[strings.ToLower(locale)] = variant
+    }
+    source := s.config.locale(page)
+    if skipSource {
+        delete(variants, source)
+    } else {
+        variants[source] = page
+    }
+
+    locales := make([]string, 0, len(variants))
+    for locale := range variants {
+        locales = append(locales, locale)
+    }
+    sort.Strings(locales)
+
+    for _, locale := range locales {
+        variant := variants[locale]
+        translation := map[string]interface{}{
+            "title": variant.Title,
+            "body":  variant.Content,
+        }
+
+        if existing[locale] {
+            url := fmt.Sprintf("%s/api/v2/help_center/articles/%s/translations/%s.json", s.baseURL, articleID, locale)
+            if err := s.doRequest(ctx, "update translation", "PUT", url, map[string]interface{}{"translation": translation}, nil); err != nil {
+                return err
+            }
+            continue
+        }
+
+        translation["locale"] = locale
+        url := fmt.Sprintf("%s/api/v2/help_center/articles/%s/translations.json", s.baseURL, articleID)
+        if err := s.doRequest(ctx, "create translation", "POST", url, map[string]interface{}{"translation": translation}, nil); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
@@ -0,0 +1,97 @@
+package zendesk
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// fakeHelpCenter records write requests and serves the translations of article 7
+type fakeHelpCenter struct {
+    locales  []string // Existing translations
+    requests []string // "METHOD path" of every write
+    articles []map[string]interface{}
+}
+
+func (f *fakeHelpCenter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.TrimPrefix(r.URL.Path, "/api/v2/help_center")
+    if r.Method == "GET" {
+        var translations []map[string]string
+        for _, locale := range f.locales {
+            translations = append(translations, map[string]string{"locale": locale})
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"translations": translations})
+        return
+    }
+
+    f.requests = append(f.requests, r.Method+" "+path)
+    var body map[string]interface{}
+    json.NewDecoder(r.Body).Decode(&body)
+    if article, ok := body["article"].(map[string]interface{}); ok {
+        f.articles = append(f.articles, article)
+    }
+    if r.Method == "POST" && strings.HasSuffix(path, "/articles.json") {
+        w.WriteHeader(http.StatusCreated)
+        json.NewEncoder(w).Encode(map[string]interface{}{"article": map[string]interface{}{"id": 7}})
+    }
+}
+
+func TestCreatePagePlacementAndTranslations(t *testing.T) {
+    fake := &fakeHelpCenter{}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewZendeskServiceWithConfig(server.URL, "me@example.com", "token", ZendeskConfig{SectionID: 5, PermissionGroupID: 9})
+    page := Page{ID: "p", Title: "Hello", Content: "<p>Hi</p>", Translations: map[string]Page{
+        "de":    {Title: "Hallo", Content: "<p>Hallo</p>"},
+        "en-us": {Title: "Hello", Content: "<p>Hi</p>"},
+    }}
+    id, err := s.CreatePage(context.Background(), page)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if id != "7" {
+        t.Errorf("CreatePage() = %q, want 7", id)
+    }
+
+    want := []string{
+        "POST /sections/5/articles.json",
+        "POST /articles/7/translations.json",
+    }
+    if !reflect.DeepEqual(fake.requests, want) {
+        t.Errorf("requests = %q, want %q", fake.requests, want)
+    }
+    article := fake.articles[0]
+    if article["locale"] != "en-us" || article["permission_group_id"] != float64(9) || article["user_segment_id"] != nil {
+        t.Errorf("article = %v, want locale en-us, group 9 and no segment", article)
+    }
+}
+
+func TestUpdatePageTranslations(t *testing.T) {
+    fake := &fakeHelpCenter{locales: []string{"en-US", "de"}}
+    server := httptest.NewServer(fake)
+    defer server.Close()
+
+    s := NewZendeskServiceWithConfig(server.URL, "me@example.com", "token", ZendeskConfig{SectionID: 5})
+    page := Page{ID: "7", Title: "Hello", Content: "<p>Hi</p>", Translations: map[string]Page{
+        "de": {Title: "Hallo", Content: "<p>Hallo</p>"},
+        "fr": {Title: "Bonjour", Content: "<p>Salut</p>"},
+    }}
+    if err := s.UpdatePage(context.Background(), page); err != nil {
+        t.Fatal(err)
+    }
+
+    want := []string{
+        "PUT /articles/7.json",
+        "PUT /articles/7/translations/de.json",
+        "PUT /articles/7/translations/en-us.json",
+        "POST /articles/7/translations.json",
+    }
+    if !reflect.DeepEqual(fake.requests, want) {
+        t.Errorf("requests = %q, want %q", fake.requests, want)
+    }
+}